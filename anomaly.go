@@ -0,0 +1,116 @@
+package main
+
+import (
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+)
+
+// anomalyDetector looks for statistically significant jumps in the weekly
+// averages of the merge and first response times: the last week is an
+// anomaly when its z-score against the previous weeks of the window exceeds
+// the threshold. Each anomaly is reported once.
+type anomalyDetector struct {
+	window    int
+	threshold float64
+	reported  map[string]bool
+}
+
+func newAnomalyDetector(window int, threshold float64) *anomalyDetector {
+	return &anomalyDetector{window: window, threshold: threshold, reported: make(map[string]bool)}
+}
+
+// weeklySeries is a metric averaged per week.
+type weeklySeries struct {
+	name  string
+	week  func(pr PRInfo) time.Time
+	value func(pr PRInfo) (time.Duration, bool)
+}
+
+var anomalySeries = []weeklySeries{
+	{
+		name:  "merge time",
+		week:  func(pr PRInfo) time.Time { return weekStart(pr.MergedAt) },
+		value: func(pr PRInfo) (time.Duration, bool) { return pr.Duration, true },
+	},
+	{
+		name: "first human response time",
+		week: func(pr PRInfo) time.Time { return weekStart(pr.CreatedAt) },
+		value: func(pr PRInfo) (time.Duration, bool) {
+			return pr.TimeToFirstHumanResponse, pr.FirstHumanResponder != ""
+		},
+	},
+}
+
+// check logs a warning for every series whose last week is an anomaly.
+func (d *anomalyDetector) check(prData []PRInfo) {
+	for _, series := range anomalySeries {
+		weeks, averages := weeklyAverages(prData, series)
+		if len(weeks) < 2 {
+			continue
+		}
+		last := len(weeks) - 1
+		z, mean, ok := zScore(averages[last], averages[max(0, last-d.window):last])
+		if !ok || math.Abs(z) < d.threshold {
+			continue
+		}
+
+		key := series.name + weeks[last].Format(time.DateOnly)
+		if d.reported[key] {
+			continue
+		}
+		d.reported[key] = true
+		slog.Warn("Anomaly in the weekly trend", "metric", series.name, "week", weeks[last].Format(time.DateOnly),
+			"average", averages[last].Round(time.Minute), "usual", mean.Round(time.Minute), "zscore", math.Round(z*100)/100)
+	}
+}
+
+// weeklyAverages returns the weeks with data, oldest first, and the average
+// value of the series in each of them.
+func weeklyAverages(prData []PRInfo, series weeklySeries) (weeks []time.Time, averages []time.Duration) {
+	totals := make(map[time.Time]time.Duration)
+	counts := make(map[time.Time]int)
+	for _, pr := range prData {
+		value, ok := series.value(pr)
+		if !ok {
+			continue
+		}
+		week := series.week(pr)
+		totals[week] += value
+		counts[week]++
+	}
+
+	for week := range totals {
+		weeks = append(weeks, week)
+	}
+	sort.Slice(weeks, func(i, j int) bool { return weeks[i].Before(weeks[j]) })
+	for _, week := range weeks {
+		averages = append(averages, totals[week]/time.Duration(counts[week]))
+	}
+	return weeks, averages
+}
+
+// zScore returns how many standard deviations value is away from the mean of
+// the previous values. It needs at least three previous values that are not
+// all the same.
+func zScore(value time.Duration, previous []time.Duration) (z float64, mean time.Duration, ok bool) {
+	if len(previous) < 3 {
+		return 0, 0, false
+	}
+	var sum float64
+	for _, v := range previous {
+		sum += float64(v)
+	}
+	avg := sum / float64(len(previous))
+
+	var variance float64
+	for _, v := range previous {
+		variance += (float64(v) - avg) * (float64(v) - avg)
+	}
+	stddev := math.Sqrt(variance / float64(len(previous)))
+	if stddev == 0 {
+		return 0, 0, false
+	}
+	return (float64(value) - avg) / stddev, time.Duration(avg), true
+}
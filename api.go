@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// apiRepository is a repository of /api/repos, with the quarter of its
+// latest report.
+type apiRepository struct {
+	Repository string `json:"repository"`
+	Year       int    `json:"year"`
+	Quarter    string `json:"quarter"`
+	PRs        int    `json:"prs"`
+}
+
+// apiSummary is the latest report of a repository in /api/repos/{owner}/{repo}.
+// Durations are in seconds.
+type apiSummary struct {
+	apiRepository
+	Incomplete                      bool                          `json:"incomplete"`
+	AverageMergeTime                float64                       `json:"average_merge_time"`
+	AverageTimeToFirstHumanResponse float64                       `json:"average_time_to_first_human_response"`
+	AverageTimeToFirstBotResponse   float64                       `json:"average_time_to_first_bot_response"`
+	AverageCITime                   float64                       `json:"average_ci_time"`
+	AverageNumberOfReviewers        float64                       `json:"average_reviewers"`
+	AverageNumberOfComments         float64                       `json:"average_comments"`
+	SLACompliance                   float64                       `json:"sla_compliance"`
+	Leaderboards                    map[string][]LeaderboardEntry `json:"leaderboards"`
+}
+
+// apiWeek is a week of /api/repos/{owner}/{repo}/weeks. Durations are in
+// seconds.
+type apiWeek struct {
+	Week                            string  `json:"week"`
+	PRs                             int     `json:"prs"`
+	AverageMergeTime                float64 `json:"average_merge_time"`
+	AverageTimeToFirstHumanResponse float64 `json:"average_time_to_first_human_response"`
+	SLABreaches                     int     `json:"sla_breaches"`
+}
+
+func newAPISummary(r Report) apiSummary {
+	return apiSummary{
+		apiRepository:                   apiRepository{Repository: r.Owner + "/" + r.Repo, Year: r.Year, Quarter: r.Quarter, PRs: len(r.PRs)},
+		Incomplete:                      r.Incomplete,
+		AverageMergeTime:                r.AverageMergeTime.Seconds(),
+		AverageTimeToFirstHumanResponse: r.AverageTimeToFirstHumanResponse.Seconds(),
+		AverageTimeToFirstBotResponse:   r.AverageTimeToFirstBotResponse.Seconds(),
+		AverageCITime:                   r.AverageCITime.Seconds(),
+		AverageNumberOfReviewers:        r.AverageNumberOfReviewers,
+		AverageNumberOfComments:         r.AverageNumberOfComments,
+		SLACompliance:                   r.SLACompliance,
+		Leaderboards: map[string][]LeaderboardEntry{
+			"reviewers":              r.ReviewerLeaderboard,
+			"commenters":             r.CommenterLeaderboard,
+			"creators":               r.CreatorLeaderboard,
+			"first-human-responders": r.FirstHumanResponderLeaderboard,
+			"first-responders":       r.FirstResponderLeaderboard,
+			"mergers":                r.MergerLeaderboard,
+			"approvers":              r.ApproverLeaderboard,
+			"changes-requesters":     r.ChangesRequesterLeaderboard,
+		},
+	}
+}
+
+func newAPIWeeks(prs []PRInfo, sla SLA) []apiWeek {
+	weeks := []apiWeek{}
+	for _, summary := range weeklySummaries(prs, sla) {
+		weeks = append(weeks, apiWeek{
+			Week:                            summary.Week.Format(time.DateOnly),
+			PRs:                             summary.PRs,
+			AverageMergeTime:                summary.AverageMergeTime.Seconds(),
+			AverageTimeToFirstHumanResponse: summary.AverageTimeToFirstHumanResponse.Seconds(),
+			SLABreaches:                     len(summary.SLABreaches),
+		})
+	}
+	return weeks
+}
+
+// serveRepos answers /api/repos with the repositories of the latest reports
+// the user can read.
+func (s *reportServer) serveRepos(w http.ResponseWriter, req *http.Request) {
+	s.mu.RLock()
+	var latest []Report
+	for _, r := range s.latest {
+		latest = append(latest, r)
+	}
+	s.mu.RUnlock()
+
+	repos := []apiRepository{}
+	for _, r := range latest {
+		if s.canRead(req, r.Owner, r.Repo) {
+			repos = append(repos, newAPISummary(r).apiRepository)
+		}
+	}
+	sort.Slice(repos, func(i, j int) bool { return repos[i].Repository < repos[j].Repository })
+	writeAPI(w, repos)
+}
+
+// serveRepo answers /api/repos/{owner}/{repo} with the latest report of the
+// repository, and /api/repos/{owner}/{repo}/weeks with the weekly trends of
+// the PRs of its latest run. Both only cover the PRs meeting the --where
+// condition of the where parameter when it is given.
+func (s *reportServer) serveRepo(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/api/repos/"), "/")
+	if len(parts) < 2 || !s.canRead(req, parts[0], parts[1]) {
+		http.NotFound(w, req)
+		return
+	}
+	var where whereFilter
+	if expr := req.URL.Query().Get("where"); expr != "" {
+		var err error
+		if where, err = parseWhere(expr); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	prs, ok := s.prs(parts[0], parts[1])
+	if ok && where.match != nil {
+		prs = where.filter(prs)
+	}
+
+	switch {
+	case len(parts) == 2:
+		r, ok := s.report(parts[0], parts[1])
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		if where.match != nil {
+			incomplete := r.Incomplete
+			r = newReport(r.Owner, r.Repo, r.Year, r.Quarter, filterPRInfosByQuarterAndYear(prs, r.Year, r.Quarter), s.reportOpts)
+			r.Incomplete = incomplete
+		}
+		writeAPI(w, newAPISummary(r))
+	case len(parts) == 3 && parts[2] == "weeks":
+		if !ok {
+			http.NotFound(w, req)
+			return
+		}
+		writeAPI(w, newAPIWeeks(prs, s.sla))
+	default:
+		http.NotFound(w, req)
+	}
+}
+
+func writeAPI(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-cache")
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// date is a flag such as --since, given as YYYY-MM-DD.
+type date struct {
+	time.Time
+}
+
+func (d *date) String() string {
+	if d.IsZero() {
+		return ""
+	}
+	return d.Format(time.DateOnly)
+}
+
+func (d *date) Set(value string) error {
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	d.Time = t
+	return nil
+}
+
+// backfillPRs walks all the closed PRs of the repository created since the
+// date, whatever --max-prs, most recent first, and adds the merged ones to
+// the store a page at a time, so that an interrupted backfill keeps what it
+// collected. The PRs the store has already are not collected again, so
+// running the backfill again goes on where it stopped. Before each page, the
+// backfill waits for the rate limit to reset when it would not cover the
+// page. It returns the number of PRs it stored.
+func backfillPRs(ctx context.Context, client GitHubClient, owner string, repo string, store *Store, since time.Time, opts collectOptions, progress *progress) (int, error) {
+	stored, err := store.LoadPRs(owner, repo)
+	if err != nil {
+		return 0, fmt.Errorf("loading the stored PRs: %w", err)
+	}
+	done := make(map[int]bool)
+	for _, pr := range stored {
+		done[pr.Number] = true
+	}
+
+	opt := &github.PullRequestListOptions{State: "closed", Sort: "created", Direction: "desc", ListOptions: github.ListOptions{PerPage: 100}}
+	issues := newIssueCache(client, owner, repo, opts.Calls)
+	added := 0
+	for ctx.Err() == nil {
+		prs, resp, err := client.ListPRs(ctx, owner, repo, opt)
+		if err != nil && ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			return added, fmt.Errorf("page %d: %w", opt.Page, err)
+		}
+		progress.pageFetched(len(prs))
+
+		// The PRs come most recent first, the ones created before the date
+		// end the backfill
+		var batch []*github.PullRequest
+		var oldest time.Time
+		reachedSince := false
+		for _, pr := range prs {
+			if pr.GetCreatedAt().Before(since) {
+				reachedSince = true
+				continue
+			}
+			oldest = pr.GetCreatedAt()
+			if !done[pr.GetNumber()] {
+				batch = append(batch, pr)
+			}
+		}
+
+		if err := opts.Calls.waitForRate(ctx, len(batch)*apiCallsPerPR); err != nil {
+			break
+		}
+		prInfos := getMergeTimes(ctx, client, owner, repo, batch, issues, opts, progress)
+		if err := store.SavePRs(owner, repo, prInfos); err != nil {
+			return added, fmt.Errorf("storing page %d: %w", opt.Page, err)
+		}
+		added += len(prInfos)
+		if !oldest.IsZero() {
+			slog.Info("Backfilled a page of PRs", "page", max(opt.Page, 1), "reached", oldest.Format(time.DateOnly), "stored", added)
+		}
+
+		if reachedSince || resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	progress.done()
+	return added, nil
+}
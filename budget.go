@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// errBudgetExhausted is returned for the API calls beyond the --max-api-calls
+// budget of a run.
+var errBudgetExhausted = errors.New("API call budget exhausted")
+
+// apiCalls accounts for the API calls of one run.
+type apiCalls struct {
+	// max is the number of calls the run may make, unlimited when zero.
+	max       int
+	requests  int
+	cacheHits int
+	rate      github.Rate
+	started   time.Time
+	// exhausted is called when the budget is used up, to stop the fetching.
+	exhausted context.CancelCauseFunc
+}
+
+// reset starts the accounting of a new run.
+func (a *apiCalls) reset(max int, exhausted context.CancelCauseFunc) {
+	*a = apiCalls{max: max, started: time.Now(), exhausted: exhausted}
+}
+
+// cacheHit records a call that was answered from a cache instead of the API.
+func (a *apiCalls) cacheHit() {
+	if a != nil {
+		a.cacheHits++
+	}
+}
+
+// logSummary logs the calls made by the run, so that scheduled runs can be
+// sized to the rate limit.
+func (a *apiCalls) logSummary() {
+	attrs := []any{"requests", a.requests, "cache_hits", a.cacheHits, "wall_time", time.Since(a.started).Round(time.Millisecond)}
+	if a.rate.Limit > 0 {
+		attrs = append(attrs, "rate_limit_remaining", a.rate.Remaining, "rate_limit", a.rate.Limit, "rate_limit_reset", a.rate.Reset.Time)
+	}
+	if a.max > 0 {
+		attrs = append(attrs, "max_api_calls", a.max)
+	}
+	slog.Info("Run finished", attrs...)
+}
+
+// waitForRate waits until the rate limit resets when fewer than needed calls
+// remain, as the last response reported it, or until ctx is done.
+func (a *apiCalls) waitForRate(ctx context.Context, needed int) error {
+	if a == nil || a.rate.Limit == 0 || a.rate.Remaining >= needed {
+		return ctx.Err()
+	}
+	wait := time.Until(a.rate.Reset.Time)
+	if wait <= 0 {
+		return ctx.Err()
+	}
+	slog.Info("Waiting for the rate limit to reset", "remaining", a.rate.Remaining, "needed", needed, "reset", a.rate.Reset.Time)
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-time.After(wait):
+		return nil
+	}
+}
+
+// countingClient counts the calls made through it into calls and refuses the
+// calls beyond the budget. It sits below the retryClient, so that every retry
+// counts as a call too.
+type countingClient struct {
+	next  GitHubClient
+	calls *apiCalls
+}
+
+func (c *countingClient) ListPRs(ctx context.Context, owner string, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	return count(c, func() ([]*github.PullRequest, *github.Response, error) {
+		return c.next.ListPRs(ctx, owner, repo, opts)
+	})
+}
+
+func (c *countingClient) GetPR(ctx context.Context, owner string, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	return count(c, func() (*github.PullRequest, *github.Response, error) {
+		return c.next.GetPR(ctx, owner, repo, number)
+	})
+}
+
+func (c *countingClient) ListComments(ctx context.Context, owner string, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	return count(c, func() ([]*github.IssueComment, *github.Response, error) {
+		return c.next.ListComments(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *countingClient) ListReviewComments(ctx context.Context, owner string, repo string, number int, opts *github.PullRequestListCommentsOptions) ([]*github.PullRequestComment, *github.Response, error) {
+	return count(c, func() ([]*github.PullRequestComment, *github.Response, error) {
+		return c.next.ListReviewComments(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *countingClient) ListReviews(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+	return count(c, func() ([]*github.PullRequestReview, *github.Response, error) {
+		return c.next.ListReviews(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *countingClient) ListCommits(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	return count(c, func() ([]*github.RepositoryCommit, *github.Response, error) {
+		return c.next.ListCommits(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *countingClient) ListTimeline(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.Timeline, *github.Response, error) {
+	return count(c, func() ([]*github.Timeline, *github.Response, error) {
+		return c.next.ListTimeline(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *countingClient) ListFiles(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+	return count(c, func() ([]*github.CommitFile, *github.Response, error) {
+		return c.next.ListFiles(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *countingClient) GetIssue(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error) {
+	return count(c, func() (*github.Issue, *github.Response, error) {
+		return c.next.GetIssue(ctx, owner, repo, number)
+	})
+}
+
+func (c *countingClient) ListReleases(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	return count(c, func() ([]*github.RepositoryRelease, *github.Response, error) {
+		return c.next.ListReleases(ctx, owner, repo, opts)
+	})
+}
+
+func (c *countingClient) ListCheckRuns(ctx context.Context, owner string, repo string, ref string, opts *github.ListOptions) ([]*github.CheckRun, *github.Response, error) {
+	return count(c, func() ([]*github.CheckRun, *github.Response, error) {
+		return c.next.ListCheckRuns(ctx, owner, repo, ref, opts)
+	})
+}
+
+func (c *countingClient) CreateCheckRun(ctx context.Context, owner string, repo string, opts github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+	return count(c, func() (*github.CheckRun, *github.Response, error) {
+		return c.next.CreateCheckRun(ctx, owner, repo, opts)
+	})
+}
+
+func (c *countingClient) CreateStatus(ctx context.Context, owner string, repo string, ref string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+	return count(c, func() (*github.RepoStatus, *github.Response, error) {
+		return c.next.CreateStatus(ctx, owner, repo, ref, status)
+	})
+}
+
+func (c *countingClient) CreateComment(ctx context.Context, owner string, repo string, number int, body string) (*github.IssueComment, *github.Response, error) {
+	return count(c, func() (*github.IssueComment, *github.Response, error) {
+		return c.next.CreateComment(ctx, owner, repo, number, body)
+	})
+}
+
+func (c *countingClient) GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error) {
+	return count(c, func() (*github.User, *github.Response, error) {
+		return c.next.GetAuthenticatedUser(ctx)
+	})
+}
+
+func (c *countingClient) EditComment(ctx context.Context, owner string, repo string, id int64, body string) (*github.IssueComment, *github.Response, error) {
+	return count(c, func() (*github.IssueComment, *github.Response, error) {
+		return c.next.EditComment(ctx, owner, repo, id, body)
+	})
+}
+
+func (c *countingClient) ListIssues(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	return count(c, func() ([]*github.Issue, *github.Response, error) {
+		return c.next.ListIssues(ctx, owner, repo, opts)
+	})
+}
+
+func (c *countingClient) CreateIssue(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return count(c, func() (*github.Issue, *github.Response, error) {
+		return c.next.CreateIssue(ctx, owner, repo, issue)
+	})
+}
+
+func (c *countingClient) EditIssue(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return count(c, func() (*github.Issue, *github.Response, error) {
+		return c.next.EditIssue(ctx, owner, repo, number, issue)
+	})
+}
+
+func count[T any](c *countingClient, call func() (T, *github.Response, error)) (T, *github.Response, error) {
+	calls := c.calls
+	if calls.max > 0 && calls.requests >= calls.max {
+		if calls.exhausted != nil {
+			calls.exhausted(errBudgetExhausted)
+		}
+		var zero T
+		return zero, nil, errBudgetExhausted
+	}
+
+	calls.requests++
+	result, resp, err := call()
+	if resp != nil && resp.Rate.Limit > 0 {
+		calls.rate = resp.Rate
+	}
+	return result, resp, err
+}
@@ -0,0 +1,105 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+)
+
+// PathBusFactor tells how many distinct people authored or reviewed the
+// changes to a directory.
+type PathBusFactor struct {
+	Path      string
+	PRs       int
+	Authors   []string
+	Reviewers []string
+	BusFactor int
+}
+
+// directory returns the first depth directories of the file path, "." for
+// files at the root of the repository.
+func directory(file string, depth int) string {
+	dir := path.Dir(file)
+	if dir == "." || depth <= 0 {
+		return dir
+	}
+	parts := strings.Split(dir, "/")
+	if len(parts) > depth {
+		parts = parts[:depth]
+	}
+	return strings.Join(parts, "/")
+}
+
+// busFactorPerPath estimates the bus factor of every directory of the given
+// depth as the number of distinct humans who authored or reviewed PRs
+// changing it. The riskiest directories come first.
+func busFactorPerPath(prData []PRInfo, depth int) []PathBusFactor {
+	type people struct {
+		prs       int
+		authors   map[string]bool
+		reviewers map[string]bool
+	}
+	dirs := make(map[string]*people)
+
+	for _, pr := range prData {
+		touched := make(map[string]bool)
+		for _, file := range pr.Files {
+			touched[directory(file, depth)] = true
+		}
+		for dir := range touched {
+			p, ok := dirs[dir]
+			if !ok {
+				p = &people{authors: make(map[string]bool), reviewers: make(map[string]bool)}
+				dirs[dir] = p
+			}
+			p.prs++
+			if !strings.HasSuffix(pr.Creator, "[bot]") {
+				p.authors[pr.Creator] = true
+			}
+			for _, reviewer := range pr.Reviewers {
+				p.reviewers[reviewer] = true
+			}
+		}
+	}
+
+	var result []PathBusFactor
+	for dir, p := range dirs {
+		everybody := make(map[string]bool)
+		bf := PathBusFactor{Path: dir, PRs: p.prs}
+		for author := range p.authors {
+			bf.Authors = append(bf.Authors, author)
+			everybody[author] = true
+		}
+		for reviewer := range p.reviewers {
+			bf.Reviewers = append(bf.Reviewers, reviewer)
+			everybody[reviewer] = true
+		}
+		sort.Strings(bf.Authors)
+		sort.Strings(bf.Reviewers)
+		bf.BusFactor = len(everybody)
+		result = append(result, bf)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].BusFactor != result[j].BusFactor {
+			return result[i].BusFactor < result[j].BusFactor
+		}
+		if result[i].PRs != result[j].PRs {
+			return result[i].PRs > result[j].PRs
+		}
+		return result[i].Path < result[j].Path
+	})
+	return result
+}
+
+func printBusFactors(w io.Writer, busFactors []PathBusFactor, limit int) {
+	fmt.Fprintln(w, "Riskiest paths by bus factor:")
+	for i, bf := range busFactors {
+		if limit > 0 && i >= limit {
+			break
+		}
+		fmt.Fprintf(w, "  %s: bus factor %d, %d PRs, authors %v, reviewers %v\n", bf.Path, bf.BusFactor, bf.PRs, bf.Authors, bf.Reviewers)
+	}
+}
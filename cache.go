@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// responseCache keeps the responses of the server for a while (--cache-ttl),
+// by path and query, so that dashboards refreshing every few seconds do not
+// compute the same reports over and over. Every response gets an ETag, as the
+// hash of its body, and If-None-Match requests for the same body get a 304.
+// The responses are dropped whenever a run updates the reports.
+type responseCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	header  http.Header
+	body    []byte
+	etag    string
+	expires time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, entries: make(map[string]cachedResponse)}
+}
+
+// invalidate drops all the responses.
+func (c *responseCache) invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries = make(map[string]cachedResponse)
+}
+
+func (c *responseCache) get(key string) (cachedResponse, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || time.Now().After(entry.expires) {
+		return cachedResponse{}, false
+	}
+	return entry, true
+}
+
+func (c *responseCache) put(key string, entry cachedResponse) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry
+}
+
+// cacheable reports whether the responses to the path are cached: the ones
+// computed from the reports.
+func cacheable(path string) bool {
+	if path == "/api/me" {
+		return false
+	}
+	for _, prefix := range []string{"/api/", "/badge/", "/feed/", "/team/", "/graphql"} {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// responseRecorder keeps the response of a handler to cache it. The status
+// is 200 unless the handler writes another one, as for a http.ResponseWriter.
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func newResponseRecorder() *responseRecorder {
+	return &responseRecorder{header: make(http.Header), status: http.StatusOK}
+}
+
+func (r *responseRecorder) Header() http.Header {
+	return r.header
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+}
+
+func (r *responseRecorder) Write(data []byte) (int, error) {
+	r.WriteHeader(http.StatusOK)
+	return r.body.Write(data)
+}
+
+// wrap answers the GET requests of the cached paths from the cache. user
+// returns who makes the request, as the responses differ by user when they
+// log in.
+func (c *responseCache) wrap(next http.Handler, user func(req *http.Request) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if (req.Method != http.MethodGet && req.Method != http.MethodHead) || !cacheable(req.URL.Path) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		key := user(req) + " " + req.URL.RequestURI()
+		entry, ok := c.get(key)
+		if !ok {
+			recorder := newResponseRecorder()
+			next.ServeHTTP(recorder, req)
+			if recorder.status != http.StatusOK {
+				for k, v := range recorder.header {
+					w.Header()[k] = v
+				}
+				w.WriteHeader(recorder.status)
+				w.Write(recorder.body.Bytes())
+				return
+			}
+			sum := sha256.Sum256(recorder.body.Bytes())
+			entry = cachedResponse{
+				header:  recorder.header,
+				body:    recorder.body.Bytes(),
+				etag:    `"` + hex.EncodeToString(sum[:16]) + `"`,
+				expires: time.Now().Add(c.ttl),
+			}
+			c.put(key, entry)
+		}
+
+		for k, v := range entry.header {
+			w.Header()[k] = v
+		}
+		w.Header().Set("ETag", entry.etag)
+		if etagMatches(req.Header.Get("If-None-Match"), entry.etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write(entry.body)
+	})
+}
+
+// etagMatches reports whether the If-None-Match header lists the ETag.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == etag || candidate == "*" {
+			return true
+		}
+	}
+	return false
+}
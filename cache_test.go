@@ -0,0 +1,62 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponseCache(t *testing.T) {
+	for _, tc := range []struct {
+		name       string
+		handler    http.HandlerFunc
+		wantStatus int
+		wantBody   string
+		wantCached bool
+	}{
+		{
+			name:       "body",
+			handler:    func(w http.ResponseWriter, req *http.Request) { w.Write([]byte("report")) },
+			wantStatus: http.StatusOK,
+			wantBody:   "report",
+			wantCached: true,
+		},
+		{
+			name:       "nothing written",
+			handler:    func(w http.ResponseWriter, req *http.Request) {},
+			wantStatus: http.StatusOK,
+			wantCached: true,
+		},
+		{
+			name:       "error",
+			handler:    func(w http.ResponseWriter, req *http.Request) { http.NotFound(w, req) },
+			wantStatus: http.StatusNotFound,
+			wantBody:   "404 page not found\n",
+		},
+		{
+			name:       "status without a body",
+			handler:    func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(http.StatusNoContent) },
+			wantStatus: http.StatusNoContent,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			handler := newResponseCache(time.Minute).wrap(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				calls++
+				tc.handler(w, req)
+			}), func(req *http.Request) string { return "" })
+
+			for i := 0; i < 2; i++ {
+				w := httptest.NewRecorder()
+				handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/repos", nil))
+				if w.Code != tc.wantStatus || w.Body.String() != tc.wantBody {
+					t.Errorf("got %d %q, want %d %q", w.Code, w.Body.String(), tc.wantStatus, tc.wantBody)
+				}
+			}
+			if wantCalls := map[bool]int{true: 1, false: 2}[tc.wantCached]; calls != wantCalls {
+				t.Errorf("got %d calls of the handler, want %d", calls, wantCalls)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gonum.org/v1/plot"
+	"gonum.org/v1/plot/plotter"
+	"gonum.org/v1/plot/vg"
+)
+
+const chartLeaderboardSize = 10
+
+// renderCharts writes the merge time histogram, the weekly merge time trend
+// and the leaderboards of the given PRs as images into dir. The format is
+// either "png" or "svg" and prefix is prepended to every file name.
+func renderCharts(dir string, format string, prefix string, prData []PRInfo) error {
+	if format != "png" && format != "svg" {
+		return fmt.Errorf("unsupported chart format %q", format)
+	}
+	if len(prData) == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	charts := map[string]func([]PRInfo) (*plot.Plot, error){
+		"merge-time-histogram": mergeTimeHistogramChart,
+		"merge-time-trend":     mergeTimeTrendChart,
+		"top-reviewers":        leaderboardChart("Top reviewers", prReviewers),
+		"top-commenters":       leaderboardChart("Top commenters", prCommenters),
+		"top-creators":         leaderboardChart("Top creators", prCreator),
+	}
+
+	for name, chart := range charts {
+		p, err := chart(prData)
+		if err != nil {
+			return fmt.Errorf("rendering %s chart: %w", name, err)
+		}
+		path := filepath.Join(dir, fmt.Sprintf("%s-%s.%s", prefix, name, format))
+		if err := p.Save(8*vg.Inch, 4*vg.Inch, path); err != nil {
+			return fmt.Errorf("saving %s chart: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func mergeTimeHistogramChart(prData []PRInfo) (*plot.Plot, error) {
+	values := make(plotter.Values, len(prData))
+	for i, pr := range prData {
+		values[i] = pr.Duration.Hours()
+	}
+
+	h, err := plotter.NewHist(values, 20)
+	if err != nil {
+		return nil, err
+	}
+
+	p := plot.New()
+	p.Title.Text = "Merge time distribution"
+	p.X.Label.Text = "Merge time (hours)"
+	p.Y.Label.Text = "PRs"
+	p.Add(h)
+	return p, nil
+}
+
+func mergeTimeTrendChart(prData []PRInfo) (*plot.Plot, error) {
+	weeks, averages := mergeTimesPerWeek(prData)
+	points := make(plotter.XYs, len(weeks))
+	for i, week := range weeks {
+		points[i].X = float64(week.Unix())
+		points[i].Y = averages[i].Hours()
+	}
+
+	line, err := plotter.NewLine(points)
+	if err != nil {
+		return nil, err
+	}
+
+	p := plot.New()
+	p.Title.Text = "Average merge time per week"
+	p.X.Label.Text = "Week"
+	p.X.Tick.Marker = plot.TimeTicks{Format: "2006-01-02"}
+	p.Y.Label.Text = "Merge time (hours)"
+	p.Add(line, plotter.NewGrid())
+	return p, nil
+}
+
+func leaderboardChart(title string, names func(PRInfo) []string) func([]PRInfo) (*plot.Plot, error) {
+	return func(prData []PRInfo) (*plot.Plot, error) {
+		entries := leaderboard(prData, names, chartLeaderboardSize)
+
+		p := plot.New()
+		p.Title.Text = title
+		p.Y.Label.Text = "PRs"
+		if len(entries) == 0 {
+			return p, nil
+		}
+
+		values := make(plotter.Values, len(entries))
+		people := make([]string, len(entries))
+		for i, entry := range entries {
+			values[i] = float64(entry.Count)
+			people[i] = entry.Name
+		}
+		bars, err := plotter.NewBarChart(values, vg.Points(20))
+		if err != nil {
+			return nil, err
+		}
+		p.Add(bars)
+		p.NominalX(people...)
+		return p, nil
+	}
+}
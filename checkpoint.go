@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+)
+
+// checkpointInterval is the number of collected PRs after which the
+// checkpoint is written again.
+const checkpointInterval = 25
+
+// checkpoint records the PRs collected so far in a file, so that a run that
+// is interrupted can be resumed without collecting these PRs again.
+type checkpoint struct {
+	path  string
+	Owner string   `json:"owner"`
+	Repo  string   `json:"repo"`
+	PRs   []PRInfo `json:"prs"`
+
+	done    map[int]PRInfo
+	pending int
+}
+
+// newCheckpoint starts a checkpoint at path. When resume is set, the PRs of
+// the checkpoint left by an earlier run of the same repository are loaded.
+func newCheckpoint(path string, owner string, repo string, resume bool) (*checkpoint, error) {
+	c := &checkpoint{path: path, Owner: owner, Repo: repo, done: make(map[int]PRInfo)}
+	if !resume {
+		return c, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		slog.Info("No checkpoint to resume from, starting over", "checkpoint", path)
+		return c, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var saved checkpoint
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return nil, fmt.Errorf("parsing checkpoint %s: %w", path, err)
+	}
+	if saved.Owner != owner || saved.Repo != repo {
+		return nil, fmt.Errorf("checkpoint %s is for %s/%s, not %s/%s", path, saved.Owner, saved.Repo, owner, repo)
+	}
+
+	for _, pr := range saved.PRs {
+		c.done[pr.Number] = pr
+	}
+	c.PRs = saved.PRs
+	slog.Info("Resuming from the checkpoint", "checkpoint", path, "prs", len(c.PRs))
+	return c, nil
+}
+
+// collected returns the PR of the given number if an earlier run already
+// collected it. A nil checkpoint has no PRs.
+func (c *checkpoint) collected(number int) (PRInfo, bool) {
+	if c == nil {
+		return PRInfo{}, false
+	}
+	pr, ok := c.done[number]
+	return pr, ok
+}
+
+// add records a collected PR, writing the checkpoint every
+// checkpointInterval PRs.
+func (c *checkpoint) add(pr PRInfo) {
+	if c == nil {
+		return
+	}
+	c.PRs = append(c.PRs, pr)
+	c.done[pr.Number] = pr
+	c.pending++
+	if c.pending >= checkpointInterval {
+		c.save()
+	}
+}
+
+// save writes the checkpoint, replacing the file only once it is complete so
+// that a crash while writing does not lose the previous checkpoint.
+func (c *checkpoint) save() {
+	if c == nil || c.pending == 0 {
+		return
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		slog.Error("Encoding the checkpoint failed", "err", err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.path), filepath.Base(c.path)+".*")
+	if err != nil {
+		slog.Error("Writing the checkpoint failed", "checkpoint", c.path, "err", err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.path)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		slog.Error("Writing the checkpoint failed", "checkpoint", c.path, "err", err)
+		return
+	}
+	c.pending = 0
+}
+
+// remove deletes the checkpoint once all PRs are collected.
+func (c *checkpoint) remove() {
+	if c == nil {
+		return
+	}
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		slog.Error("Removing the checkpoint failed", "checkpoint", c.path, "err", err)
+	}
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// latencyCheckName is the name of the check run published by the check
+// command.
+const latencyCheckName = "Review latency"
+
+// checkCommand is the check command, which publishes a check run on the head
+// commit of a PR comparing its review latency so far with the SLA.
+type checkCommand struct {
+	number int
+}
+
+// latencyCheck is how a PR is doing against the SLA.
+type latencyCheck struct {
+	conclusion string
+	title      string
+	summary    string
+}
+
+// checkLatency compares the time the PR waited for its first human response
+// and the time it has been open, or took to merge, with the targets of the
+// SLA at now. Missed targets make for a neutral conclusion rather than a
+// failure, so that the check never blocks merging.
+func checkLatency(pr *github.PullRequest, firstHuman *prComment, readyForReviewAt time.Time, sla SLA, opts collectOptions, now time.Time) latencyCheck {
+	var labels []string
+	for _, label := range pr.Labels {
+		labels = append(labels, label.GetName())
+	}
+	targets := sla.targetsFor(labels)
+
+	end := now
+	open := "Open for"
+	if pr.MergedAt != nil {
+		end = pr.GetMergedAt()
+		open = "Merged after"
+	} else if pr.ClosedAt != nil {
+		end = pr.GetClosedAt()
+		open = "Closed after"
+	}
+
+	response := "Waiting for a first human response for"
+	if pr.ClosedAt != nil {
+		response = "No human response during"
+	}
+	responseTime := opts.latency(readyForReviewAt, end)
+	if firstHuman != nil {
+		response = "First human response by @" + firstHuman.Author + " after"
+		responseTime = opts.responseTime(firstHuman.Author, readyForReviewAt, firstHuman.CreatedAt)
+	}
+	openTime := opts.elapsed(pr.GetCreatedAt(), end)
+
+	check := latencyCheck{conclusion: "success"}
+	var missed []string
+	var summary strings.Builder
+	summary.WriteString("| | Time | Target |\n|---|---|---|\n")
+	for _, row := range []struct {
+		name   string
+		metric string
+		actual time.Duration
+		target time.Duration
+	}{
+		{response, "first response", responseTime, time.Duration(targets.FirstResponse)},
+		{open, "merge", openTime, time.Duration(targets.Merge)},
+	} {
+		target := "-"
+		if row.target > 0 {
+			target = humanDuration(row.target)
+			if row.actual > row.target {
+				missed = append(missed, fmt.Sprintf("%s target of %s", row.metric, humanDuration(row.target)))
+			}
+		}
+		fmt.Fprintf(&summary, "| %s | %s | %s |\n", row.name, humanDuration(row.actual.Round(time.Minute)), target)
+	}
+	check.summary = summary.String()
+
+	switch {
+	case !sla.isSet():
+		check.conclusion = "neutral"
+		check.title = fmt.Sprintf("%s %s, no SLA configured", open, humanDuration(openTime.Round(time.Minute)))
+	case len(missed) > 0:
+		check.conclusion = "neutral"
+		check.title = "Missed the " + strings.Join(missed, " and the ")
+	default:
+		check.title = "Within the SLA"
+	}
+	return check
+}
+
+// publishLatencyCheck fetches the responses to the PR and publishes how its
+// review latency compares with the SLA as a check run on its head commit.
+// Only GitHub Apps, including the GITHUB_TOKEN of Actions, create check runs,
+// so with other tokens it falls back to a commit status.
+func publishLatencyCheck(ctx context.Context, client GitHubClient, owner string, repo string, number int, sla SLA, opts collectOptions) (latencyCheck, error) {
+	pr, _, err := client.GetPR(ctx, owner, repo, number)
+	if err != nil {
+		return latencyCheck{}, fmt.Errorf("fetching PR #%d: %w", number, err)
+	}
+
+	timeline, err := listAll(func(listOpts github.ListOptions) ([]*github.Timeline, *github.Response, error) {
+		return client.ListTimeline(ctx, owner, repo, number, &listOpts)
+	})
+	if err != nil {
+		return latencyCheck{}, fmt.Errorf("fetching the timeline of PR #%d: %w", number, err)
+	}
+	comments, err := listAll(func(listOpts github.ListOptions) ([]*github.IssueComment, *github.Response, error) {
+		return client.ListComments(ctx, owner, repo, number, &github.IssueListCommentsOptions{ListOptions: listOpts})
+	})
+	if err != nil {
+		return latencyCheck{}, fmt.Errorf("fetching the comments of PR #%d: %w", number, err)
+	}
+	reviewComments, err := listAll(func(listOpts github.ListOptions) ([]*github.PullRequestComment, *github.Response, error) {
+		return client.ListReviewComments(ctx, owner, repo, number, &github.PullRequestListCommentsOptions{ListOptions: listOpts})
+	})
+	if err != nil {
+		return latencyCheck{}, fmt.Errorf("fetching the review comments of PR #%d: %w", number, err)
+	}
+	reviews, err := listAll(func(listOpts github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+		return client.ListReviews(ctx, owner, repo, number, &listOpts)
+	})
+	if err != nil {
+		return latencyCheck{}, fmt.Errorf("fetching the reviews of PR #%d: %w", number, err)
+	}
+
+	readyForReviewAt, _ := draftTime(pr.GetCreatedAt(), timeline, opts)
+	_, firstHuman := firstResponses(pr.GetUser().GetLogin(), opts.Self, withReviewVerdicts(mergeComments(comments, reviewComments), reviews))
+	now := time.Now()
+	check := checkLatency(pr, firstHuman, readyForReviewAt, sla, opts, now)
+
+	_, resp, err := client.CreateCheckRun(ctx, owner, repo, github.CreateCheckRunOptions{
+		Name:        latencyCheckName,
+		HeadSHA:     pr.GetHead().GetSHA(),
+		Status:      github.String("completed"),
+		Conclusion:  github.String(check.conclusion),
+		CompletedAt: &github.Timestamp{Time: now},
+		Output: &github.CheckRunOutput{
+			Title:   github.String(check.title),
+			Summary: github.String(check.summary),
+		},
+	})
+	if err != nil && resp != nil && resp.StatusCode == http.StatusForbidden {
+		slog.Info("The token cannot create check runs, which needs a GitHub App, publishing a commit status instead", "pr", number, "err", err)
+		// The statuses have no neutral state, and a missed SLA must not
+		// block merging either
+		_, _, err = client.CreateStatus(ctx, owner, repo, pr.GetHead().GetSHA(), &github.RepoStatus{
+			State:       github.String("success"),
+			Description: github.String(check.title),
+			Context:     github.String(latencyCheckName),
+		})
+		if err != nil {
+			return latencyCheck{}, fmt.Errorf("creating the commit status of PR #%d: %w", number, err)
+		}
+		return check, nil
+	}
+	if err != nil {
+		return latencyCheck{}, fmt.Errorf("creating the check run of PR #%d: %w", number, err)
+	}
+	return check, nil
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// ciTime returns how long the checks ran on the head commit of the PR, from
+// the start of the first one to the completion of the last one. Checks that
+// did not complete are ignored.
+func ciTime(runs []*github.CheckRun, opts collectOptions) time.Duration {
+	var started, completed time.Time
+	for _, run := range runs {
+		if run.StartedAt == nil || run.CompletedAt == nil {
+			continue
+		}
+		if started.IsZero() || run.StartedAt.Before(started) {
+			started = run.StartedAt.Time
+		}
+		if run.CompletedAt.After(completed) {
+			completed = run.CompletedAt.Time
+		}
+	}
+	if started.IsZero() {
+		return 0
+	}
+	return opts.elapsed(started, completed)
+}
+
+// ciGreenAt returns when the last of the required checks of the PR succeeded,
+// false when one of them did not succeed. Only the latest run of a check
+// counts, so a check that failed and succeeded when rerun is green. All
+// checks are required when no required checks are configured.
+func ciGreenAt(runs []*github.CheckRun, opts collectOptions) (time.Time, bool) {
+	latest := make(map[string]*github.CheckRun)
+	for _, run := range runs {
+		if len(opts.RequiredChecks) > 0 && !slices.Contains(opts.RequiredChecks, run.GetName()) {
+			continue
+		}
+		if previous, ok := latest[run.GetName()]; !ok || runsAfter(run, previous) {
+			latest[run.GetName()] = run
+		}
+	}
+
+	var green time.Time
+	for _, run := range latest {
+		if run.GetConclusion() != "success" || run.CompletedAt == nil {
+			return time.Time{}, false
+		}
+		if run.CompletedAt.After(green) {
+			green = run.CompletedAt.Time
+		}
+	}
+	return green, !green.IsZero()
+}
+
+// runsAfter reports whether the check run started after the other one, the
+// later created one when either did not start.
+func runsAfter(run *github.CheckRun, other *github.CheckRun) bool {
+	if run.StartedAt == nil || other.StartedAt == nil {
+		return run.GetID() > other.GetID()
+	}
+	return run.StartedAt.After(other.StartedAt.Time)
+}
+
+// averageGreenToMerge returns the average delay between CI turning green and
+// the merge among the PRs whose checks all succeeded. A long delay means the
+// PRs waited for somebody to press the merge button rather than on CI.
+func averageGreenToMerge(prData []PRInfo) (average time.Duration, green int) {
+	var total time.Duration
+	for _, pr := range prData {
+		if pr.CIGreenAt.IsZero() {
+			continue
+		}
+		total += pr.GreenToMerge
+		green++
+	}
+	if green == 0 {
+		return 0, 0
+	}
+	return total / time.Duration(green), green
+}
+
+// waitTimes returns the average time spent waiting on checks among the PRs
+// that had any and the share of their merge time it accounts for, and the
+// average time the PRs spent waiting on humans, which is whatever part of
+// the merge time was not spent on checks.
+func waitTimes(prData []PRInfo) (averageCI time.Duration, ciShare float64, averageHumans time.Duration) {
+	if len(prData) == 0 {
+		return 0, 0, 0
+	}
+
+	var ci, checkedMerge, humans time.Duration
+	var checked int
+	for _, pr := range prData {
+		humans += max(pr.Duration-pr.CITime, 0)
+		if pr.CITime == 0 {
+			continue
+		}
+		ci += pr.CITime
+		checkedMerge += pr.Duration
+		checked++
+	}
+	averageHumans = humans / time.Duration(len(prData))
+	if checked == 0 {
+		return 0, 0, averageHumans
+	}
+	if checkedMerge > 0 {
+		ciShare = float64(ci) / float64(checkedMerge)
+	}
+	return ci / time.Duration(checked), ciShare, averageHumans
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+func TestCIGreenAt(t *testing.T) {
+	run := func(id int64, name string, conclusion string, started string, completed string) *github.CheckRun {
+		checkRun := &github.CheckRun{ID: github.Int64(id), Name: github.String(name)}
+		if conclusion != "" {
+			checkRun.Conclusion = github.String(conclusion)
+		}
+		if started != "" {
+			checkRun.StartedAt = &github.Timestamp{Time: parseTime(t, started)}
+		}
+		if completed != "" {
+			checkRun.CompletedAt = &github.Timestamp{Time: parseTime(t, completed)}
+		}
+		return checkRun
+	}
+	for _, tc := range []struct {
+		name string
+		runs []*github.CheckRun
+		opts collectOptions
+		want string
+	}{
+		{name: "no checks"},
+		{
+			name: "all succeeded",
+			runs: []*github.CheckRun{
+				run(1, "unit", "success", "2024-01-08T09:00:00Z", "2024-01-08T09:10:00Z"),
+				run(2, "lint", "success", "2024-01-08T09:00:00Z", "2024-01-08T09:05:00Z"),
+			},
+			want: "2024-01-08T09:10:00Z",
+		},
+		{
+			name: "one failed",
+			runs: []*github.CheckRun{
+				run(1, "unit", "failure", "2024-01-08T09:00:00Z", "2024-01-08T09:10:00Z"),
+				run(2, "lint", "success", "2024-01-08T09:00:00Z", "2024-01-08T09:05:00Z"),
+			},
+		},
+		{
+			name: "succeeded when rerun",
+			runs: []*github.CheckRun{
+				run(3, "unit", "success", "2024-01-08T10:00:00Z", "2024-01-08T10:10:00Z"),
+				run(1, "unit", "failure", "2024-01-08T09:00:00Z", "2024-01-08T09:10:00Z"),
+				run(2, "lint", "success", "2024-01-08T09:00:00Z", "2024-01-08T09:05:00Z"),
+			},
+			want: "2024-01-08T10:10:00Z",
+		},
+		{
+			name: "failed when rerun",
+			runs: []*github.CheckRun{
+				run(1, "unit", "success", "2024-01-08T09:00:00Z", "2024-01-08T09:10:00Z"),
+				run(2, "unit", "failure", "2024-01-08T10:00:00Z", "2024-01-08T10:10:00Z"),
+			},
+		},
+		{
+			name: "rerun in progress",
+			runs: []*github.CheckRun{
+				run(1, "unit", "success", "2024-01-08T09:00:00Z", "2024-01-08T09:10:00Z"),
+				run(2, "unit", "", "", ""),
+			},
+		},
+		{
+			name: "failed check not required",
+			runs: []*github.CheckRun{
+				run(1, "unit", "success", "2024-01-08T09:00:00Z", "2024-01-08T09:10:00Z"),
+				run(2, "flaky", "failure", "2024-01-08T09:00:00Z", "2024-01-08T09:20:00Z"),
+			},
+			opts: collectOptions{RequiredChecks: []string{"unit"}},
+			want: "2024-01-08T09:10:00Z",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			green, ok := ciGreenAt(tc.runs, tc.opts)
+			var want time.Time
+			if tc.want != "" {
+				want = parseTime(t, tc.want)
+			}
+			if ok != (tc.want != "") || !green.Equal(want) {
+				t.Errorf("got %v (green: %t), want %v", green, ok, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// ClosedPRInfo describes a PR that was closed without being merged.
+type ClosedPRInfo struct {
+	Number      int
+	Title       string
+	Creator     string
+	Closer      string
+	CreatedAt   time.Time
+	ClosedAt    time.Time
+	TimeToClose time.Duration
+	Quarter     string
+	Year        int
+}
+
+// getClosedPRs collects the PRs that were closed without being merged. The
+// timeline of each one is fetched to find out who closed it.
+func getClosedPRs(ctx context.Context, client GitHubClient, owner string, repo string, prs []*github.PullRequest, opts collectOptions) []ClosedPRInfo {
+	var closed []ClosedPRInfo
+	for _, pr := range prs {
+		if ctx.Err() != nil {
+			break
+		}
+		if pr.MergedAt != nil || pr.ClosedAt == nil || pr.CreatedAt == nil {
+			continue
+		}
+
+		info := ClosedPRInfo{
+			Number:      pr.GetNumber(),
+			Title:       pr.GetTitle(),
+			Creator:     pr.GetUser().GetLogin(),
+			CreatedAt:   pr.CreatedAt.UTC(),
+			ClosedAt:    pr.ClosedAt.UTC(),
+			TimeToClose: opts.elapsed(*pr.CreatedAt, *pr.ClosedAt),
+		}
+		info.Year, info.Quarter = getYearAndQuarter(*pr.CreatedAt)
+
+		timeline, err := listAll(func(listOpts github.ListOptions) ([]*github.Timeline, *github.Response, error) {
+			return client.ListTimeline(ctx, owner, repo, info.Number, &listOpts)
+		})
+		if err != nil {
+			slog.Error("Fetching the timeline failed, the closer of the PR is unknown", "pr", info.Number, "err", err)
+		}
+		// The last closed event is the one that stuck, earlier ones were
+		// followed by a reopen
+		for _, event := range timeline {
+			if event.GetEvent() == "closed" {
+				info.Closer = event.GetActor().GetLogin()
+			}
+		}
+		closed = append(closed, info)
+	}
+	return closed
+}
+
+func filterClosedPRsByQuarterAndYear(closed []ClosedPRInfo, year int, quarter string) []ClosedPRInfo {
+	var filtered []ClosedPRInfo
+	for _, pr := range closed {
+		if pr.Year == year && pr.Quarter == quarter {
+			filtered = append(filtered, pr)
+		}
+	}
+	return filtered
+}
+
+// abandonmentRate returns the share of the PRs that were closed without
+// being merged.
+func abandonmentRate(merged int, closed int) float64 {
+	if merged+closed == 0 {
+		return 0
+	}
+	return float64(closed) / float64(merged+closed)
+}
+
+func averageTimeToClose(closed []ClosedPRInfo) time.Duration {
+	if len(closed) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, pr := range closed {
+		total += pr.TimeToClose
+	}
+	return total / time.Duration(len(closed))
+}
+
+// Closer is somebody who closed PRs without merging them.
+type Closer struct {
+	Name string
+	PRs  int
+}
+
+// closers counts the closed PRs per person who closed them, most first.
+func closers(closed []ClosedPRInfo) []Closer {
+	counts := make(map[string]int)
+	for _, pr := range closed {
+		closer := pr.Closer
+		if closer == "" {
+			closer = noSegment
+		}
+		counts[closer]++
+	}
+
+	result := make([]Closer, 0, len(counts))
+	for name, count := range counts {
+		result = append(result, Closer{Name: name, PRs: count})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].PRs != result[j].PRs {
+			return result[i].PRs > result[j].PRs
+		}
+		return result[i].Name < result[j].Name
+	})
+	return result
+}
+
+// ClosedSummary sums up the PRs of a quarter that were closed without being
+// merged.
+type ClosedSummary struct {
+	PRs                []ClosedPRInfo
+	AbandonmentRate    float64
+	AverageTimeToClose time.Duration
+	SelfClosed         int
+	Closers            []Closer
+}
+
+// newClosedSummary sums up the closed PRs of a quarter in which merged PRs
+// were merged.
+func newClosedSummary(merged int, closed []ClosedPRInfo) *ClosedSummary {
+	summary := &ClosedSummary{
+		PRs:                closed,
+		AbandonmentRate:    abandonmentRate(merged, len(closed)),
+		AverageTimeToClose: averageTimeToClose(closed),
+		Closers:            closers(closed),
+	}
+	for _, pr := range closed {
+		if pr.Closer == pr.Creator {
+			summary.SelfClosed++
+		}
+	}
+	return summary
+}
+
+func printClosedPRs(w io.Writer, closed *ClosedSummary) {
+	fmt.Fprintln(w, "PRs closed without merging:")
+	if len(closed.PRs) == 0 {
+		fmt.Fprintln(w, "  None")
+		return
+	}
+
+	// print the share of the PRs that were abandoned
+	fmt.Fprintf(w, "  Closed without merging: %d (abandonment rate %.1f%%)\n", len(closed.PRs), closed.AbandonmentRate*100)
+	// print how long it took to close them
+	fmt.Fprintf(w, "  Average time to close: %s\n", formatDuration(closed.AverageTimeToClose))
+	// print how many closed their own PR
+	fmt.Fprintf(w, "  Closed by their own creator: %d\n", closed.SelfClosed)
+
+	fmt.Fprintln(w, "  Closed by:")
+	for _, closer := range closed.Closers {
+		fmt.Fprintf(w, "    %s: %d PRs\n", closer.Name, closer.PRs)
+	}
+}
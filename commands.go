@@ -0,0 +1,273 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// invocation is the command time2review runs, next to the options of its
+// flags. The zero value is the command without a subcommand, which collects
+// the PRs, writes the reports and runs every export and server it is given
+// flags for.
+type invocation struct {
+	snapshot *snapshotCommand
+	site     *siteCommand
+	check    *checkCommand
+	comment  *commentCommand
+	repl     bool
+	// selfUpdate replaces the executable with the latest release.
+	selfUpdate bool
+	// fetch only collects the PRs into the store.
+	fetch bool
+	// export only runs the exports, without writing the reports.
+	export bool
+	// backfill walks the PRs created since --since into the store.
+	backfill bool
+	// update collects the PRs updated since the previous update into the
+	// store.
+	update bool
+	// prune deletes the PRs of the store older than their retention.
+	prune bool
+}
+
+// The flags of the commands, in groups. Every command takes the common flags
+// and the groups of what it does.
+var (
+	commonFlags   = []string{"config", "fixture", "quiet", "log-level", "log-format", "timeout", "retries", "retry-backoff", "max-api-calls", "otlp-endpoint", "cpuprofile", "memprofile", "pprof-listen"}
+	collectFlags  = []string{"repo", "group", "max-prs", "store", "offline", "query", "checkpoint", "resume", "skip-weekends", "hotfix-label", "required-check", "releases", "closed"}
+	reportFlags   = []string{"output", "format", "plugin", "template", "summary", "details", "top", "sort", "no-color", "duration-format", "where", "metric", "script", "fail-if", "histogram-buckets", "outlier-method", "outlier-threshold", "exclude-outliers", "path-prefix", "bus-factor-depth", "idle-gap", "leaderboard-size", "quarterly-leaderboard", "retention", "forecast", "review-graph", "charts-dir", "charts-format"}
+	exportFlags   = []string{"sheets-id", "sheets-credentials", "sheets-range", "sheets-pr-range", "sink", "publish", "notify", "stale-after", "issue-report", "issue-period"}
+	backfillFlags = []string{"since"}
+	pruneFlags    = []string{"older-than"}
+	serveFlags    = []string{"listen", "grpc-listen", "cache-ttl", "oauth-client-id", "interval", "anomaly-window", "anomaly-threshold"}
+)
+
+// flagCompletions are the values the shell completes the flags with.
+func flagCompletions() map[string][]string {
+	return map[string][]string{
+		"log-level":             {"debug", "info", "warn", "error"},
+		"log-format":            {"text", "json"},
+		"format":                exporterNames(),
+		"sort":                  sortedKeys(prSortKeys),
+		"duration-format":       {string(goDurations), string(humanDurations), string(secondsDurations)},
+		"outlier-method":        {"stddev", "iqr"},
+		"quarterly-leaderboard": sortedKeys(leaderboardRoles),
+		"charts-format":         {"png", "svg"},
+		"issue-period":          {"weekly", "monthly"},
+	}
+}
+
+// newRootCommand returns the time2review command and its subcommands, which
+// run with the options registered on fs.
+func newRootCommand(opts *options, fs *flag.FlagSet) *cobra.Command {
+	root := &cobra.Command{
+		Use:   "time2review",
+		Short: "Report how long the PRs of a repository take to review and merge",
+		Long: "Report how long the PRs of a repository take to review and merge.\n\n" +
+			"Without a command, the PRs are collected, the reports written and every\n" +
+			"export and server given a flag run, as the commands below do separately.",
+		Version: version,
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{})
+		},
+	}
+	addFlags(root, fs, commonFlags, collectFlags, reportFlags, exportFlags, serveFlags)
+
+	fetch := &cobra.Command{
+		Use:   "fetch --store <file>",
+		Short: "Collect the PRs into the store, without reporting them",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{fetch: true})
+		},
+	}
+	addFlags(fetch, fs, commonFlags, collectFlags)
+
+	backfill := &cobra.Command{
+		Use:   "backfill --store <file> --since <date>",
+		Short: "Walk the PRs created since the date into the store, in batches waiting for the rate limit",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{backfill: true})
+		},
+	}
+	addFlags(backfill, fs, commonFlags, collectFlags, backfillFlags)
+
+	update := &cobra.Command{
+		Use:   "update --store <file>",
+		Short: "Collect the PRs merged or updated since the previous update into the store",
+		Long:  "Collect the PRs merged or updated since the previous update into the store. To update time2review itself, use self-update.",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{update: true})
+		},
+	}
+	addFlags(update, fs, commonFlags, collectFlags)
+
+	report := &cobra.Command{
+		Use:   "report",
+		Short: "Collect the PRs and write the reports",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{})
+		},
+	}
+	addFlags(report, fs, commonFlags, collectFlags, reportFlags)
+
+	export := &cobra.Command{
+		Use:   "export",
+		Short: "Collect the PRs and export them to the sheets, sinks, buses, chat rooms and issues, without writing the reports",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{export: true})
+		},
+	}
+	addFlags(export, fs, commonFlags, collectFlags, exportFlags)
+
+	serve := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the badges and feeds of the latest reports, collecting the PRs again every --interval",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if opts.Listen == "" {
+				opts.Listen = ":8080"
+			}
+			runInvocation(*opts, invocation{})
+		},
+	}
+	addFlags(serve, fs, commonFlags, collectFlags, reportFlags, exportFlags, serveFlags)
+
+	snapshot := &cobra.Command{
+		Use:   "snapshot <file>",
+		Short: "Write the reports and save their aggregates to the file",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{snapshot: &snapshotCommand{action: "save", path: args[0]}})
+		},
+	}
+	addFlags(snapshot, fs, commonFlags, collectFlags, reportFlags)
+
+	compare := &cobra.Command{
+		Use:   "compare <file>",
+		Short: "Write the reports and compare their aggregates with the ones of a snapshot",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{snapshot: &snapshotCommand{action: "diff", path: args[0]}})
+		},
+	}
+	addFlags(compare, fs, commonFlags, collectFlags, reportFlags)
+
+	site := &cobra.Command{
+		Use:   "site <dir> --store <file>",
+		Short: "Render the reports of the stored PRs as static HTML pages into the directory",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{site: &siteCommand{dir: args[0]}})
+		},
+	}
+	addFlags(site, fs, commonFlags, collectFlags, reportFlags)
+	site.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+
+	prune := &cobra.Command{
+		Use:   "prune --store <file>",
+		Short: "Delete the stored PRs merged longer ago than --older-than or the retention of their repository",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{prune: true})
+		},
+	}
+	addFlags(prune, fs, commonFlags, collectFlags, pruneFlags)
+
+	check := &cobra.Command{
+		Use:   "check <pr>",
+		Short: "Publish a check run comparing the review latency of the PR with the SLA",
+		Long:  "Publish a check run comparing the review latency of the PR with the SLA. Only GitHub Apps, such as the GITHUB_TOKEN of GitHub Actions, can create check runs, with other tokens a commit status is published instead.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parsePRNumber(args[0])
+			if err != nil {
+				return err
+			}
+			runInvocation(*opts, invocation{check: &checkCommand{number: number}})
+			return nil
+		},
+	}
+	addFlags(check, fs, commonFlags, collectFlags)
+
+	comment := &cobra.Command{
+		Use:   "comment <pr>",
+		Short: "Comment on the merged PR how its review compares with the PRs merged before it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			number, err := parsePRNumber(args[0])
+			if err != nil {
+				return err
+			}
+			runInvocation(*opts, invocation{comment: &commentCommand{number: number}})
+			return nil
+		},
+	}
+	addFlags(comment, fs, commonFlags, collectFlags)
+
+	repl := &cobra.Command{
+		Use:   "repl",
+		Short: "Explore the collected or stored PRs interactively",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{repl: true})
+		},
+	}
+	addFlags(repl, fs, commonFlags, collectFlags, reportFlags)
+
+	selfUpdate := &cobra.Command{
+		Use:     "self-update",
+		Aliases: []string{"upgrade"},
+		Short:   "Replace the executable with the latest release, once its checksum and signature are verified",
+		Long:    "Replace the executable with the latest release, once its checksum and signature are verified. The update command updates the store instead.",
+		Args:    cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			runInvocation(*opts, invocation{selfUpdate: true})
+		},
+	}
+	addFlags(selfUpdate, fs, commonFlags)
+
+	for _, cmd := range []*cobra.Command{check, comment, fetch, backfill, update, prune, report, export, serve, repl, selfUpdate} {
+		cmd.ValidArgsFunction = cobra.NoFileCompletions
+	}
+	root.AddCommand(fetch, backfill, update, prune, report, export, serve, snapshot, compare, site, check, comment, repl, selfUpdate)
+	return root
+}
+
+// addFlags adds the flags of the groups, registered on fs, to the command.
+func addFlags(cmd *cobra.Command, fs *flag.FlagSet, groups ...[]string) {
+	completions := flagCompletions()
+	for _, group := range groups {
+		for _, name := range group {
+			f := pflag.PFlagFromGoFlag(fs.Lookup(name))
+			if name == "output" {
+				f.Shorthand = "o"
+			}
+			cmd.Flags().AddFlag(f)
+			if values, ok := completions[name]; ok {
+				cmd.RegisterFlagCompletionFunc(name, cobra.FixedCompletions(values, cobra.ShellCompDirectiveNoFileComp))
+			}
+		}
+	}
+}
+
+// parsePRNumber parses the number of a PR, written as 42 or #42.
+func parsePRNumber(arg string) (int, error) {
+	number, err := strconv.Atoi(strings.TrimPrefix(arg, "#"))
+	if err != nil || number <= 0 {
+		return 0, fmt.Errorf("invalid PR number %q", arg)
+	}
+	return number, nil
+}
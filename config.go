@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+)
+
+// Config is the content of the optional JSON configuration file given with
+// --config. Settings that are missing from the file keep their defaults.
+type Config struct {
+	// TypeRules classify PRs by their title, the first matching rule wins.
+	TypeRules []TypeRule `json:"typeRules"`
+	// SLA are the review targets the PRs are checked against.
+	SLA SLA `json:"sla"`
+	// Teams maps the GitHub logins to the name of their team.
+	Teams map[string]string `json:"teams"`
+	// TeamViews configure the pages of the teams in server mode.
+	TeamViews map[string]TeamView `json:"teamViews"`
+	// Mentees are the junior contributors the mentorship report is made for.
+	Mentees []string `json:"mentees"`
+	// Karma are the weights of the review karma.
+	Karma KarmaWeights `json:"karma"`
+	// Jira is the Jira server the issues referenced by the PRs are looked
+	// up on, no lookups when its URL is empty.
+	Jira JiraConfig `json:"jira"`
+	// Retention is how long the store keeps the PRs of the repositories
+	// after they merged, by owner/repo, such as
+	// {"codeready-toolchain/sandbox-sre": "3y"}.
+	Retention map[string]configDuration `json:"retention"`
+	// RepoGroups are the repositories of a product reported together with
+	// --group, by name, such as
+	// {"operator-stack": ["codeready-toolchain/host-operator", "codeready-toolchain/member-operator"]}.
+	RepoGroups map[string][]string `json:"repoGroups"`
+	// Timezones are the timezones of the contributors, by login, such as
+	// {"alice": "Europe/Berlin"}. The days and times of day the PRs were
+	// created, responded to and merged are in the local time of who did it,
+	// in UTC for the contributors without one. The locations of the GitHub
+	// profiles are free text rather than timezones, so they are not used.
+	Timezones map[string]configLocation `json:"timezones"`
+	// WorkingHours are the schedules of the contributors, by login. The
+	// times they took to respond only count their working hours, in their
+	// timezone, instead of --skip-weekends.
+	WorkingHours map[string]WorkingHours `json:"workingHours"`
+}
+
+// groupOwner is the owner the reports of the repository groups are written
+// under, as group/<name>.
+const groupOwner = "group"
+
+// groupRepos returns the repositories of the group.
+func (c Config) groupRepos(name string) ([]repoName, error) {
+	entries, ok := c.RepoGroups[name]
+	if !ok {
+		return nil, fmt.Errorf("no repository group %q in the configuration", name)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("repository group %q has no repositories", name)
+	}
+	repos := make([]repoName, len(entries))
+	for i, entry := range entries {
+		if err := repos[i].Set(entry); err != nil {
+			return nil, fmt.Errorf("repository group %q: %w", name, err)
+		}
+	}
+	return repos, nil
+}
+
+// TypeRule assigns Type to the PRs whose title matches Pattern.
+type TypeRule struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern"`
+
+	re *regexp.Regexp
+}
+
+func defaultConfig() Config {
+	return Config{
+		TypeRules: []TypeRule{
+			{Type: "feat", Pattern: `(?i)^feat(\(.*\))?!?:`},
+			{Type: "fix", Pattern: `(?i)^fix(\(.*\))?!?:`},
+			{Type: "chore", Pattern: `(?i)^chore(\(.*\))?!?:`},
+			{Type: "docs", Pattern: `(?i)^docs(\(.*\))?!?:`},
+			{Type: "refactor", Pattern: `(?i)^refactor(\(.*\))?!?:`},
+		},
+		Karma: defaultKarmaWeights,
+	}
+}
+
+// loadConfig reads the configuration file at path, or returns the defaults
+// when path is empty.
+func loadConfig(path string) (Config, error) {
+	config := defaultConfig()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return Config{}, err
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return Config{}, fmt.Errorf("parsing config %s: %w", path, err)
+		}
+	}
+
+	rules := make([]TypeRule, len(config.TypeRules))
+	for i, rule := range config.TypeRules {
+		re, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return Config{}, fmt.Errorf("invalid pattern for type %q: %w", rule.Type, err)
+		}
+		rule.re = re
+		rules[i] = rule
+	}
+	config.TypeRules = rules
+	return config, nil
+}
+
+// otherType is the type of the PRs no type rule matches.
+const otherType = "other"
+
+// classify returns the type of the first rule matching the title.
+func classify(title string, rules []TypeRule) string {
+	for _, rule := range rules {
+		if rule.re.MatchString(title) {
+			return rule.Type
+		}
+	}
+	return otherType
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ProjectCycleTime are the cycle and review times of the PRs referencing the
+// issues of one project of an issue tracker, such as a Jira project or a
+// Linear team.
+type ProjectCycleTime struct {
+	Project string
+	PRs     int
+	// AverageCycleTime is the average time from the creation of the issue
+	// to the merge of the PR.
+	AverageCycleTime time.Duration
+	// AverageStartToMerge is the average time from the start of the work on
+	// the issue to the merge, over the issues that were started.
+	AverageStartToMerge             time.Duration
+	AverageMergeTime                time.Duration
+	AverageTimeToFirstHumanResponse time.Duration
+}
+
+// issueCycle is what a PR knows of the issues of one tracker.
+type issueCycle func(pr PRInfo) (projects []string, cycleTime time.Duration, startToMerge time.Duration)
+
+func jiraCycle(pr PRInfo) ([]string, time.Duration, time.Duration) {
+	return issueProjects(pr.JiraIssues), pr.JiraCycleTime, pr.JiraStartToMerge
+}
+
+// issueProjects returns the projects of issue keys such as "PROJ-123", each
+// once.
+func issueProjects(keys []string) []string {
+	var projects []string
+	seen := make(map[string]bool)
+	for _, key := range keys {
+		project, _, _ := strings.Cut(key, "-")
+		if !seen[project] {
+			seen[project] = true
+			projects = append(projects, project)
+		}
+	}
+	return projects
+}
+
+// projectCycleTimes breaks down the PRs that have a cycle time per project of
+// their issues, by project name. A PR referencing several projects counts for
+// each.
+func projectCycleTimes(prData []PRInfo, cycleOf issueCycle) []ProjectCycleTime {
+	groups := make(map[string][]PRInfo)
+	for _, pr := range prData {
+		projects, cycle, _ := cycleOf(pr)
+		if cycle == 0 {
+			continue
+		}
+		for _, project := range projects {
+			groups[project] = append(groups[project], pr)
+		}
+	}
+
+	stats := make([]ProjectCycleTime, 0, len(groups))
+	for project, prs := range groups {
+		var totalCycle, totalStartToMerge time.Duration
+		started := 0
+		for _, pr := range prs {
+			_, cycle, startToMerge := cycleOf(pr)
+			totalCycle += cycle
+			if startToMerge > 0 {
+				totalStartToMerge += startToMerge
+				started++
+			}
+		}
+		s := ProjectCycleTime{
+			Project:                         project,
+			PRs:                             len(prs),
+			AverageCycleTime:                totalCycle / time.Duration(len(prs)),
+			AverageMergeTime:                averageMergeTime(prs),
+			AverageTimeToFirstHumanResponse: averageFirstReponseHumanTime(prs),
+		}
+		if started > 0 {
+			s.AverageStartToMerge = totalStartToMerge / time.Duration(started)
+		}
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].Project < stats[j].Project })
+	return stats
+}
+
+func printProjectCycleTimes(w io.Writer, title string, stats []ProjectCycleTime) {
+	if len(stats) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, s := range stats {
+		fmt.Fprintf(w, "  %s: %d PRs, issue created to merge %s, issue started to merge %s, merge time %s, time to first human response %s\n",
+			s.Project, s.PRs, formatDuration(s.AverageCycleTime), formatDuration(s.AverageStartToMerge), formatDuration(s.AverageMergeTime), formatDuration(s.AverageTimeToFirstHumanResponse))
+	}
+}
@@ -0,0 +1,225 @@
+"use strict";
+
+// The dashboard draws the latest report of the selected repository from the
+// API of the server: /api/repos, /api/repos/{owner}/{repo} and
+// /api/repos/{owner}/{repo}/weeks. When the users log in with GitHub, /api/me
+// has their login and the repositories they pinned, listed first.
+
+const repoSelect = document.getElementById("repo");
+const boardSelect = document.getElementById("board");
+const pinButton = document.getElementById("pin");
+const status = document.getElementById("status");
+let summary = null;
+// pins are the repositories the user pinned, null when nobody logs in.
+let pins = null;
+
+async function getJSON(path) {
+  const resp = await fetch(path);
+  if (!resp.ok) {
+    throw new Error(`${path}: ${resp.status} ${resp.statusText}`);
+  }
+  return resp.json();
+}
+
+// duration writes seconds the way the text report does in its human format.
+function duration(seconds) {
+  if (!seconds) {
+    return "-";
+  }
+  const units = [["day", 86400], ["hour", 3600], ["minute", 60]];
+  const parts = [];
+  for (const [name, size] of units) {
+    const n = Math.floor(seconds / size);
+    if (n > 0 && parts.length < 2) {
+      parts.push(`${n} ${name}${n === 1 ? "" : "s"}`);
+      seconds -= n * size;
+    }
+  }
+  return parts.length ? parts.join(" ") : "less than a minute";
+}
+
+function svg(name, attrs, text) {
+  const element = document.createElementNS("http://www.w3.org/2000/svg", name);
+  for (const [key, value] of Object.entries(attrs)) {
+    element.setAttribute(key, value);
+  }
+  if (text !== undefined) {
+    element.textContent = text;
+  }
+  return element;
+}
+
+// chart draws the values of the weeks as a line, or as bars, scaled to the
+// largest value.
+function chart(id, weeks, value, bars, label) {
+  const element = document.getElementById(id);
+  element.replaceChildren();
+  if (weeks.length === 0) {
+    element.appendChild(svg("text", { x: 10, y: 100 }, "No PRs merged"));
+    return;
+  }
+  const width = 800, height = 200, top = 20, bottom = 20;
+  const max = Math.max(...weeks.map(value)) || 1;
+  const step = width / weeks.length;
+  const y = (v) => top + (height - top - bottom) * (1 - v / max);
+  if (bars) {
+    weeks.forEach((week, i) => {
+      const rect = svg("rect", { class: "bar", x: i * step + 1, y: y(value(week)), width: Math.max(step - 2, 1), height: height - bottom - y(value(week)) });
+      rect.appendChild(svg("title", {}, `${week.week}: ${label(value(week))}`));
+      element.appendChild(rect);
+    });
+  } else {
+    const points = weeks.map((week, i) => `${i * step + step / 2},${y(value(week))}`).join(" ");
+    element.appendChild(svg("polyline", { class: "line", points }));
+    weeks.forEach((week, i) => {
+      const dot = svg("circle", { cx: i * step + step / 2, cy: y(value(week)), r: 3, fill: "#0969da" });
+      dot.appendChild(svg("title", {}, `${week.week}: ${label(value(week))}`));
+      element.appendChild(dot);
+    });
+  }
+  element.appendChild(svg("text", { x: 4, y: 12 }, label(max)));
+  element.appendChild(svg("text", { x: 4, y: height - 4 }, weeks[0].week));
+  element.appendChild(svg("text", { x: width - 4, y: height - 4, "text-anchor": "end" }, weeks[weeks.length - 1].week));
+}
+
+function showSummary() {
+  const tiles = [
+    ["PRs merged", summary.prs],
+    ["Average merge time", duration(summary.average_merge_time)],
+    ["Average time to first human response", duration(summary.average_time_to_first_human_response)],
+    ["Average time to first bot response", duration(summary.average_time_to_first_bot_response)],
+    ["Average CI time", duration(summary.average_ci_time)],
+    ["Average reviewers per PR", summary.average_reviewers.toFixed(2)],
+    ["Average comments per PR", summary.average_comments.toFixed(2)],
+    ["SLA compliance", `${(summary.sla_compliance * 100).toFixed(0)}%`],
+  ];
+  const element = document.getElementById("summary");
+  element.replaceChildren();
+  for (const [name, value] of tiles) {
+    const tile = document.createElement("div");
+    tile.className = "tile";
+    tile.innerHTML = `<div class="value"></div><div class="name"></div>`;
+    tile.querySelector(".value").textContent = value;
+    tile.querySelector(".name").textContent = name;
+    element.appendChild(tile);
+  }
+  status.textContent = `${summary.quarter} ${summary.year}${summary.incomplete ? ", incomplete: fetching was interrupted" : ""}`;
+}
+
+function showLeaderboard() {
+  const body = document.querySelector("#leaderboard tbody");
+  body.replaceChildren();
+  for (const entry of summary.leaderboards[boardSelect.value] || []) {
+    const row = document.createElement("tr");
+    for (const value of [entry.Rank, entry.Name, entry.Count, `${(entry.Share * 100).toFixed(0)}%`]) {
+      const cell = document.createElement("td");
+      cell.textContent = value;
+      row.appendChild(cell);
+    }
+    body.appendChild(row);
+  }
+}
+
+async function showRepository(repository) {
+  try {
+    const [latest, weeks] = await Promise.all([getJSON(`/api/repos/${repository}`), getJSON(`/api/repos/${repository}/weeks`)]);
+    summary = latest;
+    showSummary();
+    chart("merge-time", weeks, (week) => week.average_merge_time, false, duration);
+    chart("first-response", weeks, (week) => week.average_time_to_first_human_response, false, duration);
+    chart("merged", weeks, (week) => week.prs, true, String);
+    if (boardSelect.options.length === 0) {
+      for (const board of Object.keys(summary.leaderboards).sort()) {
+        boardSelect.appendChild(new Option(board, board));
+      }
+      boardSelect.value = "reviewers";
+    }
+    showLeaderboard();
+  } catch (err) {
+    status.textContent = err.message;
+  }
+}
+
+async function start() {
+  try {
+    const repos = await getJSON("/api/repos");
+    if (repos.length === 0) {
+      status.textContent = "No report yet, the first run is still collecting the PRs.";
+      setTimeout(start, 10000);
+      return;
+    }
+    const me = await fetch("/api/me");
+    if (me.ok) {
+      const user = await me.json();
+      pins = user.pins;
+      document.getElementById("login").textContent = user.login;
+      document.getElementById("user").hidden = false;
+      pinButton.hidden = false;
+    }
+    listRepositories(repos);
+    const selected = new URLSearchParams(location.search).get("repo");
+    if (selected && repos.some((repo) => repo.repository === selected)) {
+      repoSelect.value = selected;
+    }
+    updatePinButton();
+    showRepository(repoSelect.value);
+  } catch (err) {
+    status.textContent = err.message;
+  }
+}
+
+// listRepositories fills the selector, the pinned repositories first.
+function listRepositories(repos) {
+  const selected = repoSelect.value;
+  repoSelect.replaceChildren();
+  let list = repoSelect;
+  if (pins && pins.length > 0) {
+    const pinned = document.createElement("optgroup");
+    pinned.label = "Pinned";
+    for (const pin of pins) {
+      if (repos.some((repo) => repo.repository === pin)) {
+        pinned.appendChild(new Option(pin, pin));
+      }
+    }
+    repoSelect.appendChild(pinned);
+    list = document.createElement("optgroup");
+    list.label = "All repositories";
+    repoSelect.appendChild(list);
+  }
+  for (const repo of repos) {
+    if (!pins || !pins.includes(repo.repository)) {
+      list.appendChild(new Option(repo.repository, repo.repository));
+    }
+  }
+  if (selected) {
+    repoSelect.value = selected;
+  }
+  updatePinButton();
+}
+
+function updatePinButton() {
+  pinButton.textContent = pins && pins.includes(repoSelect.value) ? "Unpin" : "Pin";
+}
+
+pinButton.addEventListener("click", async () => {
+  const repository = repoSelect.value;
+  const updated = pins.includes(repository) ? pins.filter((pin) => pin !== repository) : [...pins, repository];
+  try {
+    const resp = await fetch("/api/me", { method: "PUT", headers: { "Content-Type": "application/json" }, body: JSON.stringify(updated) });
+    if (!resp.ok) {
+      throw new Error(`/api/me: ${resp.status} ${resp.statusText}`);
+    }
+    pins = (await resp.json()).pins;
+    listRepositories(await getJSON("/api/repos"));
+  } catch (err) {
+    status.textContent = err.message;
+  }
+});
+
+repoSelect.addEventListener("change", () => {
+  history.replaceState(null, "", `?repo=${encodeURIComponent(repoSelect.value)}`);
+  updatePinButton();
+  showRepository(repoSelect.value);
+});
+boardSelect.addEventListener("change", showLeaderboard);
+start();
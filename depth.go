@@ -0,0 +1,55 @@
+package main
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// reviewDepth counts the comments, words and characters other humans than the
+// author wrote on the PR, in the conversation, on the diff and as review
+// bodies.
+func reviewDepth(author string, comments []prComment, reviews []*github.PullRequestReview) (count int, words int, characters int) {
+	add := func(user string, body string) {
+		if user == author || strings.HasSuffix(user, "[bot]") || strings.TrimSpace(body) == "" {
+			return
+		}
+		count++
+		words += len(strings.Fields(body))
+		characters += utf8.RuneCountInString(body)
+	}
+
+	for _, comment := range comments {
+		add(comment.Author, comment.Body)
+	}
+	for _, review := range reviews {
+		add(review.GetUser().GetLogin(), review.GetBody())
+	}
+	return count, words, characters
+}
+
+func averageReviewDepth(prData []PRInfo) (words float64, characters float64) {
+	if len(prData) == 0 {
+		return 0, 0
+	}
+
+	var totalWords, totalCharacters int
+	for _, pr := range prData {
+		totalWords += pr.ReviewCommentWords
+		totalCharacters += pr.ReviewCommentCharacters
+	}
+	return float64(totalWords) / float64(len(prData)), float64(totalCharacters) / float64(len(prData))
+}
+
+// prsWithoutReviewComments returns the numbers of the PRs that were merged
+// without a single review comment.
+func prsWithoutReviewComments(prData []PRInfo) []int {
+	var numbers []int
+	for _, pr := range prData {
+		if pr.ReviewComments == 0 {
+			numbers = append(numbers, pr.Number)
+		}
+	}
+	return numbers
+}
@@ -0,0 +1,74 @@
+package main
+
+import "time"
+
+// collectOptions configure how the data of the PRs is collected.
+type collectOptions struct {
+	// SkipWeekends leaves Saturdays and Sundays (UTC) out of all durations.
+	SkipWeekends bool
+	// HotfixLabel is the label that marks hotfix PRs.
+	HotfixLabel string
+	// TypeRules classify the PRs by their title.
+	TypeRules []TypeRule
+	// Timezones are the timezones of the contributors the days and times of
+	// day are computed in.
+	Timezones map[string]configLocation
+	// WorkingHours are the schedules the response times of the contributors
+	// are counted in.
+	WorkingHours map[string]WorkingHours
+	// RequiredChecks are the names of the checks that must succeed before
+	// merging, all checks when empty.
+	RequiredChecks []string
+	// Calls counts the cache hits, nil to not count them.
+	Calls *apiCalls
+	// Jira looks up the Jira issues referenced by the PRs, nil to not look
+	// them up.
+	Jira *jiraClient
+	// Linear looks up the Linear issues referenced by the PRs, nil to not
+	// look them up.
+	Linear *linearClient
+	// Checkpoint records the collected PRs and holds the PRs an interrupted
+	// run already collected, nil to not checkpoint.
+	Checkpoint *checkpoint
+	// MaxPRs is the number of most recently closed PRs fetched, all when 0.
+	MaxPRs int
+	// Self is the user of the token, whose comments of the comment command
+	// are not responses, empty when it is not known.
+	Self string
+}
+
+// elapsed returns the time between start and end, leaving out the weekends
+// when configured to.
+func (o collectOptions) elapsed(start time.Time, end time.Time) time.Duration {
+	if !o.SkipWeekends {
+		return end.Sub(start)
+	}
+	return weekdayDuration(start, end)
+}
+
+// latency returns the time it took from start until something happened at
+// end. Something that happened before start, e.g. a comment on a PR while it
+// was still a draft, happened right away.
+func (o collectOptions) latency(start time.Time, end time.Time) time.Duration {
+	if end.Before(start) {
+		return 0
+	}
+	return o.elapsed(start, end)
+}
+
+// weekdayDuration returns the part of the time between start and end that
+// falls on Monday to Friday in UTC.
+func weekdayDuration(start time.Time, end time.Time) time.Duration {
+	var d time.Duration
+	for t := start.UTC(); t.Before(end); {
+		next := t.Truncate(24 * time.Hour).Add(24 * time.Hour)
+		if next.After(end) {
+			next = end.UTC()
+		}
+		if t.Weekday() != time.Saturday && t.Weekday() != time.Sunday {
+			d += next.Sub(t)
+		}
+		t = next
+	}
+	return d
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"github.com/segmentio/kafka-go"
+)
+
+// Event is a message published to the message bus for every collected PR
+// and every SLA breach.
+type Event struct {
+	// Type is "pr" or "sla_breach".
+	Type       string     `json:"type"`
+	Repository string     `json:"repository"`
+	PR         *PRInfo    `json:"pr,omitempty"`
+	Breach     *SLABreach `json:"breach,omitempty"`
+}
+
+// publisher sends events to a message bus.
+type publisher interface {
+	publish(ctx context.Context, key string, event Event) error
+	Close() error
+}
+
+// newPublisher connects to the message bus at the URL, either
+// nats://host:port/subject or kafka://broker[,broker...]/topic.
+func newPublisher(busURL string) (publisher, error) {
+	u, err := url.Parse(busURL)
+	if err != nil {
+		return nil, err
+	}
+	topic := strings.TrimPrefix(u.Path, "/")
+	if topic == "" {
+		return nil, fmt.Errorf("missing the subject or topic in %q", u.Redacted())
+	}
+
+	switch u.Scheme {
+	case "nats":
+		conn, err := nats.Connect((&url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host}).String())
+		if err != nil {
+			return nil, err
+		}
+		return &natsPublisher{conn: conn, subject: topic}, nil
+	case "kafka":
+		return &kafkaPublisher{writer: &kafka.Writer{
+			Addr:     kafka.TCP(strings.Split(u.Host, ",")...),
+			Topic:    topic,
+			Balancer: &kafka.Hash{},
+		}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported message bus %q, only nats:// and kafka:// URLs are supported", u.Redacted())
+	}
+}
+
+type natsPublisher struct {
+	conn    *nats.Conn
+	subject string
+}
+
+func (p *natsPublisher) publish(ctx context.Context, key string, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.conn.Publish(p.subject, data)
+}
+
+// Close sends the pending events before disconnecting.
+func (p *natsPublisher) Close() error {
+	err := p.conn.Flush()
+	p.conn.Close()
+	return err
+}
+
+// kafkaPublisher keys the messages by PR, so all the events of a PR land in
+// the same partition.
+type kafkaPublisher struct {
+	writer *kafka.Writer
+}
+
+func (p *kafkaPublisher) publish(ctx context.Context, key string, event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	return p.writer.WriteMessages(ctx, kafka.Message{Key: []byte(key), Value: data})
+}
+
+func (p *kafkaPublisher) Close() error {
+	return p.writer.Close()
+}
+
+// publishPRs publishes an event for each PR and for each of its SLA breaches.
+func publishPRs(ctx context.Context, p publisher, owner string, repo string, prInfos []PRInfo, sla SLA) error {
+	for i := range prInfos {
+		pr := &prInfos[i]
+		repository := pr.Repository
+		if repository == "" {
+			repository = owner + "/" + repo
+		}
+		key := prReference(repository, pr.Number)
+		if err := p.publish(ctx, key, Event{Type: "pr", Repository: repository, PR: pr}); err != nil {
+			return fmt.Errorf("publishing PR #%d: %w", pr.Number, err)
+		}
+		for _, breach := range slaBreaches(*pr, sla) {
+			breach := breach
+			if err := p.publish(ctx, key, Event{Type: "sla_breach", Repository: repository, Breach: &breach}); err != nil {
+				return fmt.Errorf("publishing the SLA breach of PR #%d: %w", pr.Number, err)
+			}
+		}
+	}
+	return nil
+}
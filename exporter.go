@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"plugin"
+	"sort"
+	"text/template"
+)
+
+// Exporter writes the reports of a run, one per quarter, in a format of its
+// own. Exporters that keep the reports until the end of the run implement
+// Flush, which is called once all the reports were exported.
+type Exporter interface {
+	Name() string
+	Export(r Report) error
+}
+
+// exportOptions are the options of the text report an exporter may honor.
+type exportOptions struct {
+	Template *template.Template
+	Text     textOptions
+}
+
+// exporterFactory creates the exporter writing the reports to out.
+type exporterFactory func(out io.Writer, opts exportOptions) Exporter
+
+// exporters are the exporters --format picks from by name. The built-in ones
+// register themselves from the files of their formats, the ones of the
+// --plugin files when they are loaded.
+var exporters = make(map[string]exporterFactory)
+
+func registerExporter(name string, factory exporterFactory) {
+	if _, ok := exporters[name]; ok {
+		panic(fmt.Sprintf("exporter %q is registered twice", name))
+	}
+	exporters[name] = factory
+}
+
+func exporterNames() []string {
+	names := make([]string, 0, len(exporters))
+	for name := range exporters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// exporterWriter writes the reports of an --output with its exporter, and
+// closes the output once the exporter is flushed.
+type exporterWriter struct {
+	exporter Exporter
+	out      io.Closer
+}
+
+func (w *exporterWriter) write(r Report) error {
+	return w.exporter.Export(r)
+}
+
+func (w *exporterWriter) close() error {
+	if flusher, ok := w.exporter.(interface{ Flush() error }); ok {
+		if err := flusher.Flush(); err != nil {
+			w.out.Close()
+			return err
+		}
+	}
+	return w.out.Close()
+}
+
+// loadExporterPlugin registers the exporter of a Go plugin built with
+// go build -buildmode=plugin. As plugins cannot import this program, the
+// plugin exports a Name string variable and an Export function of type
+// func(out io.Writer, report []byte) error, which gets the report of every
+// quarter as JSON.
+func loadExporterPlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return err
+	}
+	nameSymbol, err := p.Lookup("Name")
+	if err != nil {
+		return err
+	}
+	name, ok := nameSymbol.(*string)
+	if !ok {
+		return fmt.Errorf("Name of plugin %s is a %T, expected a string", path, nameSymbol)
+	}
+	exportSymbol, err := p.Lookup("Export")
+	if err != nil {
+		return err
+	}
+	export, ok := exportSymbol.(func(io.Writer, []byte) error)
+	if !ok {
+		return fmt.Errorf("Export of plugin %s is a %T, expected a func(io.Writer, []byte) error", path, exportSymbol)
+	}
+	if _, ok := exporters[*name]; ok {
+		return fmt.Errorf("plugin %s exports the %q format, which is already registered", path, *name)
+	}
+
+	registerExporter(*name, func(out io.Writer, opts exportOptions) Exporter {
+		return &pluginExporter{name: *name, out: out, export: export}
+	})
+	return nil
+}
+
+// pluginExporter is the exporter of a plugin.
+type pluginExporter struct {
+	name   string
+	out    io.Writer
+	export func(io.Writer, []byte) error
+}
+
+func (e *pluginExporter) Name() string {
+	return e.name
+}
+
+func (e *pluginExporter) Export(r Report) error {
+	data, err := json.Marshal(reportJSON(r))
+	if err != nil {
+		return err
+	}
+	return e.export(e.out, data)
+}
@@ -0,0 +1,172 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// feedWeeks is the number of most recent weeks the Atom feed has entries for.
+const feedWeeks = 20
+
+// feedBreaches is the number of SLA breaches listed in a weekly entry, the
+// worst first.
+const feedBreaches = 5
+
+// WeeklySummary holds the review metrics of the PRs merged in one week.
+type WeeklySummary struct {
+	Week                            time.Time
+	PRs                             int
+	AverageMergeTime                time.Duration
+	AverageTimeToFirstHumanResponse time.Duration
+	SLABreaches                     []SLABreach
+}
+
+// weeklySummaries summarizes the PRs per week they were merged in, oldest
+// week first. The breaches of a week are sorted by how far they missed the
+// target.
+func weeklySummaries(prData []PRInfo, sla SLA) []WeeklySummary {
+	byWeek := make(map[time.Time][]PRInfo)
+	for _, pr := range prData {
+		week := weekStart(pr.MergedAt)
+		byWeek[week] = append(byWeek[week], pr)
+	}
+
+	summaries := make([]WeeklySummary, 0, len(byWeek))
+	for week, prs := range byWeek {
+		summary := WeeklySummary{Week: week, PRs: len(prs), AverageMergeTime: averageMergeTime(prs), AverageTimeToFirstHumanResponse: averageFirstReponseHumanTime(prs)}
+		for _, pr := range prs {
+			summary.SLABreaches = append(summary.SLABreaches, slaBreaches(pr, sla)...)
+		}
+		sortBreaches(summary.SLABreaches)
+		summaries = append(summaries, summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool { return summaries[i].Week.Before(summaries[j].Week) })
+	return summaries
+}
+
+// sortBreaches sorts the breaches by how far they missed the target, the
+// worst first.
+func sortBreaches(breaches []SLABreach) {
+	sort.Slice(breaches, func(i, j int) bool {
+		a, b := breaches[i], breaches[j]
+		return float64(a.Actual)/float64(a.Target) > float64(b.Actual)/float64(b.Target)
+	})
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomEntry struct {
+	ID      string      `xml:"id"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Content atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Text string `xml:",chardata"`
+}
+
+// weeklyFeed builds the Atom feed of the weekly summaries of a repository,
+// newest week first. An entry is updated at the latest merge of its week, so
+// feed readers pick up the current week again while it fills up.
+func weeklyFeed(owner string, repo string, prData []PRInfo, sla SLA) atomFeed {
+	feed := atomFeed{
+		ID:     fmt.Sprintf("tag:time2review,2024:%s/%s", owner, repo),
+		Title:  fmt.Sprintf("Weekly review metrics of %s/%s", owner, repo),
+		Author: atomAuthor{Name: "time2review"},
+	}
+
+	summaries := weeklySummaries(prData, sla)
+	if len(summaries) > feedWeeks {
+		summaries = summaries[len(summaries)-feedWeeks:]
+	}
+	var updated time.Time
+	for i := len(summaries) - 1; i >= 0; i-- {
+		summary := summaries[i]
+		var lastMerge time.Time
+		for _, pr := range prData {
+			if weekStart(pr.MergedAt).Equal(summary.Week) && pr.MergedAt.After(lastMerge) {
+				lastMerge = pr.MergedAt
+			}
+		}
+		if lastMerge.After(updated) {
+			updated = lastMerge
+		}
+
+		week := summary.Week.Format(time.DateOnly)
+		feed.Entries = append(feed.Entries, atomEntry{
+			ID:      fmt.Sprintf("%s/week/%s", feed.ID, week),
+			Title:   fmt.Sprintf("Week of %s: %d PRs merged, average merge time %s", week, summary.PRs, humanDuration(summary.AverageMergeTime)),
+			Updated: lastMerge.UTC().Format(time.RFC3339),
+			Content: atomContent{Type: "text", Text: weeklySummaryText(summary)},
+		})
+	}
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+	feed.Updated = updated.UTC().Format(time.RFC3339)
+	return feed
+}
+
+func weeklySummaryText(summary WeeklySummary) string {
+	var text strings.Builder
+	fmt.Fprintf(&text, "PRs merged: %d\n", summary.PRs)
+	fmt.Fprintf(&text, "Average merge time: %s\n", formatDuration(summary.AverageMergeTime))
+	fmt.Fprintf(&text, "Average time to first human response: %s\n", formatDuration(summary.AverageTimeToFirstHumanResponse))
+	if len(summary.SLABreaches) > 0 {
+		fmt.Fprintf(&text, "SLA breaches: %d\n", len(summary.SLABreaches))
+		for i, breach := range summary.SLABreaches {
+			if i == feedBreaches {
+				fmt.Fprintf(&text, "  and %d more\n", len(summary.SLABreaches)-feedBreaches)
+				break
+			}
+			fmt.Fprintf(&text, "  %s\n", breachLine(breach))
+		}
+	}
+	return text.String()
+}
+
+func breachLine(breach SLABreach) string {
+	return fmt.Sprintf("PR %s: %s missed the %s target of %s, took %s", prReference(breach.Repository, breach.Number), breach.Title, breach.Metric, formatDuration(breach.Target), formatDuration(breach.Actual))
+}
+
+// serveFeed answers /feed/{owner}/{repo}.atom with the weekly feed of the
+// PRs of the latest run.
+func (s *reportServer) serveFeed(w http.ResponseWriter, req *http.Request) {
+	path, ok := strings.CutSuffix(strings.TrimPrefix(req.URL.Path, "/feed/"), ".atom")
+	owner, repo, found := strings.Cut(path, "/")
+	if !ok || !found || strings.Contains(repo, "/") {
+		http.NotFound(w, req)
+		return
+	}
+	prs, ok := s.prs(owner, repo)
+	if !ok || !s.canRead(req, owner, repo) {
+		http.NotFound(w, req)
+		return
+	}
+
+	data, err := xml.MarshalIndent(weeklyFeed(owner, repo, prs, s.sla), "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/atom+xml")
+	w.Write([]byte(xml.Header))
+	w.Write(data)
+}
@@ -0,0 +1,16 @@
+package main
+
+import "strings"
+
+// stringList is a flag that can be given several times, each value being
+// appended to the list.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
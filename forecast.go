@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Forecast is the projection of the next quarter from the trend of the
+// previous ones. It is an estimate, not a measurement. Quarters is 0 when
+// the history is too short for one.
+type Forecast struct {
+	Year             int
+	Quarter          string
+	Quarters         int
+	AverageMergeTime time.Duration
+	MergedPRs        float64
+}
+
+// forecastNextQuarter fits a linear trend through the average merge time and
+// the number of merged PRs of every quarter of the history and extrapolates
+// it to the quarter following the last one. It needs at least two quarters.
+func forecastNextQuarter(prData []PRInfo) (Forecast, bool) {
+	perQuarter := make(map[int][]PRInfo)
+	for _, pr := range prData {
+		q := quarterIndex(pr.Year, pr.Quarter)
+		perQuarter[q] = append(perQuarter[q], pr)
+	}
+	if len(perQuarter) < 2 {
+		return Forecast{}, false
+	}
+
+	var quarters []int
+	for q := range perQuarter {
+		quarters = append(quarters, q)
+	}
+	sort.Ints(quarters)
+
+	// Quarters without merged PRs in between count as quarters with none for
+	// the throughput, they have no merge time
+	first, last := quarters[0], quarters[len(quarters)-1]
+	var xs, throughputs, mergeXs, mergeTimes []float64
+	for q := first; q <= last; q++ {
+		xs = append(xs, float64(q-first))
+		throughputs = append(throughputs, float64(len(perQuarter[q])))
+		if len(perQuarter[q]) > 0 {
+			mergeXs = append(mergeXs, float64(q-first))
+			mergeTimes = append(mergeTimes, float64(averageMergeTime(perQuarter[q])))
+		}
+	}
+
+	next := float64(last - first + 1)
+	return Forecast{
+		Year:             (last + 1) / 4,
+		Quarter:          fmt.Sprintf("Q%d", (last+1)%4+1),
+		Quarters:         len(xs),
+		AverageMergeTime: time.Duration(max(linearTrend(mergeXs, mergeTimes, next), 0)),
+		MergedPRs:        max(linearTrend(xs, throughputs, next), 0),
+	}, true
+}
+
+// linearTrend fits a least squares line through the points and returns its
+// value at x.
+func linearTrend(xs []float64, ys []float64, x float64) float64 {
+	n := float64(len(xs))
+	var sumX, sumY, sumXY, sumXX float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+		sumXY += xs[i] * ys[i]
+		sumXX += xs[i] * xs[i]
+	}
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return sumY / n
+	}
+	slope := (n*sumXY - sumX*sumY) / denominator
+	intercept := (sumY - slope*sumX) / n
+	return intercept + slope*x
+}
+
+func printForecast(w io.Writer, forecast Forecast) {
+	fmt.Fprintln(w, "Forecast (an estimate from the linear trend of the previous quarters, not a measurement):")
+	if forecast.Quarters == 0 {
+		fmt.Fprintln(w, "  Not enough history, at least two quarters are needed")
+		return
+	}
+	fmt.Fprintf(w, "  %d-%s, from %d quarters: average merge time ~%s, ~%.0f merged PRs\n", forecast.Year, forecast.Quarter, forecast.Quarters, formatDuration(forecast.AverageMergeTime.Round(time.Hour)), forecast.MergedPRs)
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// durationFormat is how durations are written in the text, JSON and CSV
+// reports: "go" (187h26m3s), "human" (7 days 19 hours) or "seconds"
+// (674763).
+type durationFormat string
+
+const (
+	goDurations      durationFormat = "go"
+	humanDurations   durationFormat = "human"
+	secondsDurations durationFormat = "seconds"
+)
+
+func (f *durationFormat) String() string {
+	return string(*f)
+}
+
+func (f *durationFormat) Set(value string) error {
+	switch durationFormat(value) {
+	case goDurations, humanDurations, secondsDurations:
+		*f = durationFormat(value)
+		return nil
+	default:
+		return fmt.Errorf("unknown duration format %q, expected go, human or seconds", value)
+	}
+}
+
+// durationStyle is the format of all the durations of the reports, set from
+// --duration-format. It is empty when the flag is not given, which keeps the
+// encodings the reports always had: Go durations in the text, nanoseconds in
+// JSON and hours in the CSV columns.
+var durationStyle durationFormat
+
+// formatDuration writes the duration in the durationStyle.
+func formatDuration(d time.Duration) string {
+	switch durationStyle {
+	case humanDurations:
+		return humanDuration(d)
+	case secondsDurations:
+		return strconv.FormatInt(int64(d.Round(time.Second)/time.Second), 10)
+	default:
+		return d.String()
+	}
+}
+
+var durationUnits = []struct {
+	name string
+	size time.Duration
+}{
+	{"day", 24 * time.Hour},
+	{"hour", time.Hour},
+	{"minute", time.Minute},
+	{"second", time.Second},
+}
+
+// humanDuration writes the two most significant units of the duration, such
+// as "7 days 19 hours" or "3 minutes 12 seconds".
+func humanDuration(d time.Duration) string {
+	if d < 0 {
+		return "-" + humanDuration(-d)
+	}
+	d = d.Round(time.Second)
+
+	var parts []string
+	for _, unit := range durationUnits {
+		if len(parts) == 2 {
+			break
+		}
+		n := d / unit.size
+		d -= n * unit.size
+		if n == 0 {
+			// Skip the leading units, and stop at a gap after the first one
+			// so "1 day 3 minutes" is written as "1 day"
+			if len(parts) > 0 {
+				break
+			}
+			continue
+		}
+		name := unit.name
+		if n != 1 {
+			name += "s"
+		}
+		parts = append(parts, fmt.Sprintf("%d %s", n, name))
+	}
+	if len(parts) == 0 {
+		return "0 seconds"
+	}
+	return strings.Join(parts, " ")
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// reportJSON returns the value to encode as the JSON of a report, with the
+// durations in the durationStyle once --duration-format is given.
+func reportJSON(v interface{}) interface{} {
+	if durationStyle == "" {
+		return v
+	}
+	return withFormattedDurations(reflect.ValueOf(v))
+}
+
+// csvDuration writes a duration column of the CSV, in hours unless
+// --duration-format is given.
+func csvDuration(d time.Duration) string {
+	if durationStyle == "" {
+		return fmt.Sprint(hours(d))
+	}
+	return formatDuration(d)
+}
+
+// csvDurationColumn names a duration column of the CSV after its unit.
+func csvDurationColumn(name string) string {
+	if durationStyle == "" {
+		return name + "_hours"
+	}
+	return name + "_time"
+}
+
+// withFormattedDurations returns the value with all the durations it holds
+// replaced by their durationStyle representation, seconds being numbers, to
+// be encoded as JSON. Structs become maps of their exported fields.
+func withFormattedDurations(v reflect.Value) interface{} {
+	if v.Type() == durationType {
+		d := time.Duration(v.Int())
+		if durationStyle == secondsDurations {
+			return int64(d.Round(time.Second) / time.Second)
+		}
+		return formatDuration(d)
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return withFormattedDurations(v.Elem())
+	case reflect.Struct:
+		if v.Type() == reflect.TypeOf(time.Time{}) {
+			return v.Interface()
+		}
+		fields := make(map[string]interface{})
+		addFields(fields, v)
+		return fields
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && v.IsNil() {
+			return nil
+		}
+		items := make([]interface{}, v.Len())
+		for i := range items {
+			items[i] = withFormattedDurations(v.Index(i))
+		}
+		return items
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		entries := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			entries[fmt.Sprint(iter.Key().Interface())] = withFormattedDurations(iter.Value())
+		}
+		return entries
+	default:
+		return v.Interface()
+	}
+}
+
+// addFields adds the exported fields of the struct to the map under their
+// JSON names, flattening the embedded structs the way encoding/json does.
+func addFields(fields map[string]interface{}, v reflect.Value) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct {
+			addFields(fields, v.Field(i))
+			continue
+		}
+		name, tagOptions, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(","+tagOptions+",", ",omitempty,") && isEmptyValue(v.Field(i)) {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = withFormattedDurations(v.Field(i))
+	}
+}
+
+// isEmptyValue reports whether encoding/json leaves out the value of an
+// omitempty field.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	}
+	return false
+}
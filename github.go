@@ -0,0 +1,597 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// GitHubClient is the part of the GitHub API the metrics are computed from.
+// It is implemented by apiClient, which talks to GitHub, and by fakeClient,
+// which serves the data of a fixture file from memory.
+type GitHubClient interface {
+	ListPRs(ctx context.Context, owner string, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error)
+	GetPR(ctx context.Context, owner string, repo string, number int) (*github.PullRequest, *github.Response, error)
+	ListComments(ctx context.Context, owner string, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error)
+	ListReviewComments(ctx context.Context, owner string, repo string, number int, opts *github.PullRequestListCommentsOptions) ([]*github.PullRequestComment, *github.Response, error)
+	ListReviews(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error)
+	ListCommits(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error)
+	ListTimeline(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.Timeline, *github.Response, error)
+	ListFiles(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.CommitFile, *github.Response, error)
+	GetIssue(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error)
+	ListReleases(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error)
+	ListCheckRuns(ctx context.Context, owner string, repo string, ref string, opts *github.ListOptions) ([]*github.CheckRun, *github.Response, error)
+	CreateCheckRun(ctx context.Context, owner string, repo string, opts github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error)
+	CreateStatus(ctx context.Context, owner string, repo string, ref string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error)
+	CreateComment(ctx context.Context, owner string, repo string, number int, body string) (*github.IssueComment, *github.Response, error)
+	EditComment(ctx context.Context, owner string, repo string, id int64, body string) (*github.IssueComment, *github.Response, error)
+	ListIssues(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error)
+	CreateIssue(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+	EditIssue(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error)
+	GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error)
+}
+
+type apiClient struct {
+	client *github.Client
+}
+
+func (c *apiClient) ListPRs(ctx context.Context, owner string, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	return c.client.PullRequests.List(ctx, owner, repo, opts)
+}
+
+func (c *apiClient) GetPR(ctx context.Context, owner string, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	return c.client.PullRequests.Get(ctx, owner, repo, number)
+}
+
+func (c *apiClient) ListComments(ctx context.Context, owner string, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	return c.client.Issues.ListComments(ctx, owner, repo, number, opts)
+}
+
+func (c *apiClient) ListReviewComments(ctx context.Context, owner string, repo string, number int, opts *github.PullRequestListCommentsOptions) ([]*github.PullRequestComment, *github.Response, error) {
+	return c.client.PullRequests.ListComments(ctx, owner, repo, number, opts)
+}
+
+func (c *apiClient) ListReviews(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+	return c.client.PullRequests.ListReviews(ctx, owner, repo, number, opts)
+}
+
+func (c *apiClient) ListCommits(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	return c.client.PullRequests.ListCommits(ctx, owner, repo, number, opts)
+}
+
+func (c *apiClient) ListTimeline(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.Timeline, *github.Response, error) {
+	return c.client.Issues.ListIssueTimeline(ctx, owner, repo, number, opts)
+}
+
+func (c *apiClient) ListFiles(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+	return c.client.PullRequests.ListFiles(ctx, owner, repo, number, opts)
+}
+
+func (c *apiClient) GetIssue(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error) {
+	return c.client.Issues.Get(ctx, owner, repo, number)
+}
+
+func (c *apiClient) ListReleases(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	return c.client.Repositories.ListReleases(ctx, owner, repo, opts)
+}
+
+func (c *apiClient) ListCheckRuns(ctx context.Context, owner string, repo string, ref string, opts *github.ListOptions) ([]*github.CheckRun, *github.Response, error) {
+	results, resp, err := c.client.Checks.ListCheckRunsForRef(ctx, owner, repo, ref, &github.ListCheckRunsOptions{ListOptions: listOptionsOrDefault(opts)})
+	if err != nil {
+		return nil, resp, err
+	}
+	return results.CheckRuns, resp, nil
+}
+
+func (c *apiClient) CreateCheckRun(ctx context.Context, owner string, repo string, opts github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+	return c.client.Checks.CreateCheckRun(ctx, owner, repo, opts)
+}
+
+func (c *apiClient) CreateStatus(ctx context.Context, owner string, repo string, ref string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+	return c.client.Repositories.CreateStatus(ctx, owner, repo, ref, status)
+}
+
+func (c *apiClient) CreateComment(ctx context.Context, owner string, repo string, number int, body string) (*github.IssueComment, *github.Response, error) {
+	return c.client.Issues.CreateComment(ctx, owner, repo, number, &github.IssueComment{Body: &body})
+}
+
+func (c *apiClient) EditComment(ctx context.Context, owner string, repo string, id int64, body string) (*github.IssueComment, *github.Response, error) {
+	return c.client.Issues.EditComment(ctx, owner, repo, id, &github.IssueComment{Body: &body})
+}
+
+func (c *apiClient) GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error) {
+	return c.client.Users.Get(ctx, "")
+}
+
+func (c *apiClient) ListIssues(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	return c.client.Issues.ListByRepo(ctx, owner, repo, opts)
+}
+
+func (c *apiClient) CreateIssue(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return c.client.Issues.Create(ctx, owner, repo, issue)
+}
+
+func (c *apiClient) EditIssue(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return c.client.Issues.Edit(ctx, owner, repo, number, issue)
+}
+
+// Fixture is the file format served by fakeClient. Every object uses the JSON
+// representation of the GitHub REST API, so fixtures can be assembled from
+// captured API responses. The PR details (as returned for a single PR, with
+// merged_by), comments, reviews, commits, timeline events and changed files
+// are keyed by PR number, issues by their number and check runs by the SHA
+// of the commit they ran on:
+//
+//	{
+//	  "pull_requests": [{"number": 1, "state": "closed", ...}],
+//	  "pull_request_details": {"1": {"number": 1, "merged_by": {...}, ...}},
+//	  "comments": {"1": [{"user": {"login": "alice"}, ...}]},
+//	  "review_comments": {"1": [...]},
+//	  "reviews": {"1": [...]},
+//	  "commits": {"1": [...]},
+//	  "timeline": {"1": [{"event": "ready_for_review", ...}]},
+//	  "files": {"1": [{"filename": "api/v1/types.go", ...}]},
+//	  "issues": {"100": {"number": 100, "created_at": ...}},
+//	  "releases": [{"tag_name": "v1.0.0", "published_at": ...}],
+//	  "check_runs": {"6dcb09b": [{"name": "unit", "conclusion": "success", ...}]}
+//	}
+type Fixture struct {
+	PullRequests       []*github.PullRequest                `json:"pull_requests"`
+	PullRequestDetails map[int]*github.PullRequest          `json:"pull_request_details"`
+	Comments           map[int][]*github.IssueComment       `json:"comments"`
+	ReviewComments     map[int][]*github.PullRequestComment `json:"review_comments"`
+	Reviews            map[int][]*github.PullRequestReview  `json:"reviews"`
+	Commits            map[int][]*github.RepositoryCommit   `json:"commits"`
+	Timeline           map[int][]*github.Timeline           `json:"timeline"`
+	Files              map[int][]*github.CommitFile         `json:"files"`
+	Issues             map[int]*github.Issue                `json:"issues"`
+	Releases           []*github.RepositoryRelease          `json:"releases"`
+	CheckRuns          map[string][]*github.CheckRun        `json:"check_runs"`
+}
+
+func loadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("parsing fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// fakeClient serves a fixture from memory, paginating the same way the API
+// does. The owner and repo arguments are ignored.
+type fakeClient struct {
+	fixture *Fixture
+}
+
+func (c *fakeClient) ListPRs(ctx context.Context, owner string, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	var prs []*github.PullRequest
+	for _, pr := range c.fixture.PullRequests {
+		if opts.State == "" || opts.State == "all" || pr.GetState() == opts.State {
+			prs = append(prs, pr)
+		}
+	}
+	// The fixture keeps its order unless a sort is asked for, descending by
+	// default for the creation date and ascending otherwise like the API
+	sortedBy := map[string]func(pr *github.PullRequest) time.Time{
+		"created": (*github.PullRequest).GetCreatedAt,
+		"updated": (*github.PullRequest).GetUpdatedAt,
+	}
+	if by, ok := sortedBy[opts.Sort]; ok {
+		desc := opts.Direction == "desc" || (opts.Direction == "" && opts.Sort == "created")
+		sort.SliceStable(prs, func(i, j int) bool {
+			if desc {
+				return by(prs[i]).After(by(prs[j]))
+			}
+			return by(prs[i]).Before(by(prs[j]))
+		})
+	}
+	prs, resp := paginate(prs, opts.ListOptions)
+	return prs, resp, nil
+}
+
+// GetPR returns the details of the PR, falling back to its entry in the
+// listing when the fixture has no details for it.
+func (c *fakeClient) GetPR(ctx context.Context, owner string, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	if pr, ok := c.fixture.PullRequestDetails[number]; ok {
+		return pr, &github.Response{}, nil
+	}
+	for _, pr := range c.fixture.PullRequests {
+		if pr.GetNumber() == number {
+			return pr, &github.Response{}, nil
+		}
+	}
+	return nil, notFound(), fmt.Errorf("PR #%d is not in the fixture", number)
+}
+
+func (c *fakeClient) ListComments(ctx context.Context, owner string, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	var listOptions github.ListOptions
+	if opts != nil {
+		listOptions = opts.ListOptions
+	}
+	comments, resp := paginate(c.fixture.Comments[number], listOptions)
+	return comments, resp, nil
+}
+
+func (c *fakeClient) ListReviewComments(ctx context.Context, owner string, repo string, number int, opts *github.PullRequestListCommentsOptions) ([]*github.PullRequestComment, *github.Response, error) {
+	var listOptions github.ListOptions
+	if opts != nil {
+		listOptions = opts.ListOptions
+	}
+	comments, resp := paginate(c.fixture.ReviewComments[number], listOptions)
+	return comments, resp, nil
+}
+
+func (c *fakeClient) ListReviews(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+	reviews, resp := paginate(c.fixture.Reviews[number], listOptionsOrDefault(opts))
+	return reviews, resp, nil
+}
+
+func (c *fakeClient) ListCommits(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	commits, resp := paginate(c.fixture.Commits[number], listOptionsOrDefault(opts))
+	return commits, resp, nil
+}
+
+func (c *fakeClient) ListTimeline(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.Timeline, *github.Response, error) {
+	events, resp := paginate(c.fixture.Timeline[number], listOptionsOrDefault(opts))
+	return events, resp, nil
+}
+
+func (c *fakeClient) ListFiles(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+	files, resp := paginate(c.fixture.Files[number], listOptionsOrDefault(opts))
+	return files, resp, nil
+}
+
+func (c *fakeClient) GetIssue(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error) {
+	if issue, ok := c.fixture.Issues[number]; ok {
+		return issue, &github.Response{}, nil
+	}
+	return nil, notFound(), fmt.Errorf("issue #%d is not in the fixture", number)
+}
+
+func (c *fakeClient) ListReleases(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	releases, resp := paginate(c.fixture.Releases, listOptionsOrDefault(opts))
+	return releases, resp, nil
+}
+
+func (c *fakeClient) ListCheckRuns(ctx context.Context, owner string, repo string, ref string, opts *github.ListOptions) ([]*github.CheckRun, *github.Response, error) {
+	runs, resp := paginate(c.fixture.CheckRuns[ref], listOptionsOrDefault(opts))
+	return runs, resp, nil
+}
+
+// CreateCheckRun adds the check run to the check runs of its commit in the
+// fixture, so that it is listed from then on.
+func (c *fakeClient) CreateCheckRun(ctx context.Context, owner string, repo string, opts github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+	if c.fixture.CheckRuns == nil {
+		c.fixture.CheckRuns = make(map[string][]*github.CheckRun)
+	}
+	id := int64(len(c.fixture.CheckRuns[opts.HeadSHA]) + 1)
+	run := &github.CheckRun{
+		ID:          &id,
+		Name:        &opts.Name,
+		HeadSHA:     &opts.HeadSHA,
+		Status:      opts.Status,
+		Conclusion:  opts.Conclusion,
+		CompletedAt: opts.CompletedAt,
+		Output:      &github.CheckRunOutput{Title: opts.Output.Title, Summary: opts.Output.Summary},
+	}
+	c.fixture.CheckRuns[opts.HeadSHA] = append(c.fixture.CheckRuns[opts.HeadSHA], run)
+	return run, &github.Response{}, nil
+}
+
+// CreateStatus returns the commit status without keeping it, as no fixture
+// lists the statuses.
+func (c *fakeClient) CreateStatus(ctx context.Context, owner string, repo string, ref string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+	return status, &github.Response{}, nil
+}
+
+// CreateComment adds the comment to the comments of the PR or issue in the
+// fixture.
+func (c *fakeClient) CreateComment(ctx context.Context, owner string, repo string, number int, body string) (*github.IssueComment, *github.Response, error) {
+	if c.fixture.Comments == nil {
+		c.fixture.Comments = make(map[int][]*github.IssueComment)
+	}
+	var id int64 = 1
+	for _, comments := range c.fixture.Comments {
+		for _, comment := range comments {
+			if comment.GetID() >= id {
+				id = comment.GetID() + 1
+			}
+		}
+	}
+	now := time.Now()
+	comment := &github.IssueComment{ID: &id, Body: &body, User: &github.User{Login: github.String("time2review")}, CreatedAt: &now, UpdatedAt: &now}
+	c.fixture.Comments[number] = append(c.fixture.Comments[number], comment)
+	return comment, &github.Response{}, nil
+}
+
+func (c *fakeClient) EditComment(ctx context.Context, owner string, repo string, id int64, body string) (*github.IssueComment, *github.Response, error) {
+	for _, comments := range c.fixture.Comments {
+		for _, comment := range comments {
+			if comment.GetID() == id {
+				now := time.Now()
+				comment.Body, comment.UpdatedAt = &body, &now
+				return comment, &github.Response{}, nil
+			}
+		}
+	}
+	return nil, notFound(), fmt.Errorf("comment %d is not in the fixture", id)
+}
+
+// GetAuthenticatedUser returns the user the fake client comments as.
+func (c *fakeClient) GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error) {
+	return &github.User{Login: github.String("time2review")}, &github.Response{}, nil
+}
+
+// ListIssues lists the issues of the fixture with all the labels of opts, in
+// the order of their numbers. The other options are ignored.
+func (c *fakeClient) ListIssues(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	var issues []*github.Issue
+	for _, issue := range c.fixture.Issues {
+		labels := make(map[string]bool)
+		for _, label := range issue.Labels {
+			labels[label.GetName()] = true
+		}
+		matches := true
+		for _, label := range opts.Labels {
+			matches = matches && labels[label]
+		}
+		if matches {
+			issues = append(issues, issue)
+		}
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].GetNumber() < issues[j].GetNumber() })
+	page, resp := paginate(issues, opts.ListOptions)
+	return page, resp, nil
+}
+
+// CreateIssue adds the issue to the fixture, numbered after its PRs and
+// issues.
+func (c *fakeClient) CreateIssue(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	if c.fixture.Issues == nil {
+		c.fixture.Issues = make(map[int]*github.Issue)
+	}
+	number := 1
+	for n := range c.fixture.Issues {
+		number = max(number, n+1)
+	}
+	for _, pr := range c.fixture.PullRequests {
+		number = max(number, pr.GetNumber()+1)
+	}
+	now := time.Now()
+	created := &github.Issue{Number: &number, Title: issue.Title, Body: issue.Body, State: github.String("open"), CreatedAt: &now, UpdatedAt: &now}
+	if issue.Labels != nil {
+		for _, label := range *issue.Labels {
+			created.Labels = append(created.Labels, &github.Label{Name: github.String(label)})
+		}
+	}
+	c.fixture.Issues[number] = created
+	return created, &github.Response{}, nil
+}
+
+// EditIssue updates the title and the body of the issue in the fixture.
+func (c *fakeClient) EditIssue(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	edited, ok := c.fixture.Issues[number]
+	if !ok {
+		return nil, notFound(), fmt.Errorf("issue #%d is not in the fixture", number)
+	}
+	if issue.Title != nil {
+		edited.Title = issue.Title
+	}
+	if issue.Body != nil {
+		edited.Body = issue.Body
+	}
+	now := time.Now()
+	edited.UpdatedAt = &now
+	return edited, &github.Response{}, nil
+}
+
+// notFound is the response of the fake client for objects missing from the
+// fixture, which must not be retried.
+func notFound() *github.Response {
+	return &github.Response{Response: &http.Response{StatusCode: http.StatusNotFound}}
+}
+
+func listOptionsOrDefault(opts *github.ListOptions) github.ListOptions {
+	if opts == nil {
+		return github.ListOptions{}
+	}
+	return *opts
+}
+
+// paginate returns the requested page of items the way the API would, with a
+// default page size of 30.
+func paginate[T any](items []T, opts github.ListOptions) ([]T, *github.Response) {
+	perPage := opts.PerPage
+	if perPage <= 0 {
+		perPage = 30
+	}
+	page := opts.Page
+	if page <= 0 {
+		page = 1
+	}
+
+	resp := &github.Response{}
+	start := (page - 1) * perPage
+	if start >= len(items) {
+		return nil, resp
+	}
+	end := start + perPage
+	if end < len(items) {
+		resp.NextPage = page + 1
+	} else {
+		end = len(items)
+	}
+	return items[start:end], resp
+}
+
+// retryClient retries the calls of the wrapped client on server errors and
+// network errors, waiting exponentially longer between the attempts. The
+// writes are not retried, as a write that failed on the way back may have
+// been made and would be made twice.
+type retryClient struct {
+	next    GitHubClient
+	retries int
+	backoff time.Duration
+}
+
+func (c *retryClient) ListPRs(ctx context.Context, owner string, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	return retry(ctx, c, func() ([]*github.PullRequest, *github.Response, error) {
+		return c.next.ListPRs(ctx, owner, repo, opts)
+	})
+}
+
+func (c *retryClient) GetPR(ctx context.Context, owner string, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	return retry(ctx, c, func() (*github.PullRequest, *github.Response, error) {
+		return c.next.GetPR(ctx, owner, repo, number)
+	})
+}
+
+func (c *retryClient) ListComments(ctx context.Context, owner string, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	return retry(ctx, c, func() ([]*github.IssueComment, *github.Response, error) {
+		return c.next.ListComments(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *retryClient) ListReviewComments(ctx context.Context, owner string, repo string, number int, opts *github.PullRequestListCommentsOptions) ([]*github.PullRequestComment, *github.Response, error) {
+	return retry(ctx, c, func() ([]*github.PullRequestComment, *github.Response, error) {
+		return c.next.ListReviewComments(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *retryClient) ListReviews(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+	return retry(ctx, c, func() ([]*github.PullRequestReview, *github.Response, error) {
+		return c.next.ListReviews(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *retryClient) ListCommits(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	return retry(ctx, c, func() ([]*github.RepositoryCommit, *github.Response, error) {
+		return c.next.ListCommits(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *retryClient) ListTimeline(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.Timeline, *github.Response, error) {
+	return retry(ctx, c, func() ([]*github.Timeline, *github.Response, error) {
+		return c.next.ListTimeline(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *retryClient) ListFiles(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+	return retry(ctx, c, func() ([]*github.CommitFile, *github.Response, error) {
+		return c.next.ListFiles(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *retryClient) GetIssue(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error) {
+	return retry(ctx, c, func() (*github.Issue, *github.Response, error) {
+		return c.next.GetIssue(ctx, owner, repo, number)
+	})
+}
+
+func (c *retryClient) ListReleases(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	return retry(ctx, c, func() ([]*github.RepositoryRelease, *github.Response, error) {
+		return c.next.ListReleases(ctx, owner, repo, opts)
+	})
+}
+
+func (c *retryClient) ListCheckRuns(ctx context.Context, owner string, repo string, ref string, opts *github.ListOptions) ([]*github.CheckRun, *github.Response, error) {
+	return retry(ctx, c, func() ([]*github.CheckRun, *github.Response, error) {
+		return c.next.ListCheckRuns(ctx, owner, repo, ref, opts)
+	})
+}
+
+func (c *retryClient) CreateCheckRun(ctx context.Context, owner string, repo string, opts github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+	return c.next.CreateCheckRun(ctx, owner, repo, opts)
+}
+
+func (c *retryClient) CreateStatus(ctx context.Context, owner string, repo string, ref string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+	return c.next.CreateStatus(ctx, owner, repo, ref, status)
+}
+
+func (c *retryClient) CreateComment(ctx context.Context, owner string, repo string, number int, body string) (*github.IssueComment, *github.Response, error) {
+	return c.next.CreateComment(ctx, owner, repo, number, body)
+}
+
+func (c *retryClient) EditComment(ctx context.Context, owner string, repo string, id int64, body string) (*github.IssueComment, *github.Response, error) {
+	return c.next.EditComment(ctx, owner, repo, id, body)
+}
+
+func (c *retryClient) ListIssues(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	return retry(ctx, c, func() ([]*github.Issue, *github.Response, error) {
+		return c.next.ListIssues(ctx, owner, repo, opts)
+	})
+}
+
+func (c *retryClient) CreateIssue(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return c.next.CreateIssue(ctx, owner, repo, issue)
+}
+
+func (c *retryClient) EditIssue(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return c.next.EditIssue(ctx, owner, repo, number, issue)
+}
+
+func (c *retryClient) GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error) {
+	return retry(ctx, c, func() (*github.User, *github.Response, error) {
+		return c.next.GetAuthenticatedUser(ctx)
+	})
+}
+
+func retry[T any](ctx context.Context, c *retryClient, call func() (T, *github.Response, error)) (T, *github.Response, error) {
+	for attempt := 0; ; attempt++ {
+		result, resp, err := call()
+		if err == nil || attempt >= c.retries || !isTransient(ctx, resp, err) {
+			return result, resp, err
+		}
+
+		delay := c.backoff << attempt
+		slog.Warn("API call failed, retrying", "attempt", attempt+1, "delay", delay, "err", err)
+		select {
+		case <-ctx.Done():
+			return result, resp, err
+		case <-time.After(delay):
+		}
+	}
+}
+
+// isTransient reports whether a failed call is worth retrying: the server
+// failed or the request never got a response.
+func isTransient(ctx context.Context, resp *github.Response, err error) bool {
+	if ctx.Err() != nil || errors.Is(err, errBudgetExhausted) {
+		return false
+	}
+	if resp == nil || resp.Response == nil {
+		return true
+	}
+	return resp.StatusCode >= 500
+}
+
+// listAll calls list for every page of a listing and returns the items of all
+// pages, the same way the PR listing is paginated.
+func listAll[T any](list func(opts github.ListOptions) ([]T, *github.Response, error)) ([]T, error) {
+	opts := github.ListOptions{PerPage: 100}
+	var all []T
+	for {
+		items, resp, err := list(opts)
+		if err != nil {
+			return all, err
+		}
+		all = append(all, items...)
+		if resp.NextPage == 0 {
+			return all, nil
+		}
+		opts.Page = resp.NextPage
+	}
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// parseTime parses a time of the fixtures, e.g. "2024-01-08T09:00:00Z".
+func parseTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+// testPR is a merged PR of a fixture with its activity. The times are
+// RFC 3339, and the creator is alice unless set.
+type testPR struct {
+	number   int
+	creator  string
+	merger   string
+	created  string
+	merged   string
+	comments []testEvent
+	reviews  []testEvent
+	pushes   []string
+	lines    int
+}
+
+// testEvent is a comment or review of a testPR, the state is only set for
+// reviews.
+type testEvent struct {
+	login string
+	state string
+	at    string
+}
+
+// newFixture builds the fixture serving the PRs.
+func newFixture(t *testing.T, prs ...testPR) *Fixture {
+	t.Helper()
+	fixture := &Fixture{
+		PullRequestDetails: make(map[int]*github.PullRequest),
+		Comments:           make(map[int][]*github.IssueComment),
+		Reviews:            make(map[int][]*github.PullRequestReview),
+		Commits:            make(map[int][]*github.RepositoryCommit),
+	}
+	for _, pr := range prs {
+		creator := pr.creator
+		if creator == "" {
+			creator = "alice"
+		}
+		created, merged := parseTime(t, pr.created), parseTime(t, pr.merged)
+		listed := &github.PullRequest{
+			Number:    github.Int(pr.number),
+			State:     github.String("closed"),
+			Title:     github.String("change"),
+			User:      &github.User{Login: github.String(creator)},
+			CreatedAt: &created,
+			MergedAt:  &merged,
+		}
+		fixture.PullRequests = append(fixture.PullRequests, listed)
+		details := *listed
+		details.Additions = github.Int(pr.lines)
+		if pr.merger != "" {
+			details.MergedBy = &github.User{Login: github.String(pr.merger)}
+		}
+		fixture.PullRequestDetails[pr.number] = &details
+
+		for _, comment := range pr.comments {
+			createdAt := parseTime(t, comment.at)
+			fixture.Comments[pr.number] = append(fixture.Comments[pr.number], &github.IssueComment{
+				User:      &github.User{Login: github.String(comment.login)},
+				Body:      github.String("looks good"),
+				CreatedAt: &createdAt,
+			})
+		}
+		for _, review := range pr.reviews {
+			submittedAt := parseTime(t, review.at)
+			fixture.Reviews[pr.number] = append(fixture.Reviews[pr.number], &github.PullRequestReview{
+				User:        &github.User{Login: github.String(review.login)},
+				State:       github.String(review.state),
+				SubmittedAt: &submittedAt,
+			})
+		}
+		for _, push := range pr.pushes {
+			date := parseTime(t, push)
+			fixture.Commits[pr.number] = append(fixture.Commits[pr.number], &github.RepositoryCommit{
+				Commit: &github.Commit{Committer: &github.CommitAuthor{Date: &date}},
+			})
+		}
+	}
+	return fixture
+}
+
+// collect collects the merged PRs of the fixture through the fake client.
+func collect(t *testing.T, fixture *Fixture, opts collectOptions) []PRInfo {
+	t.Helper()
+	prInfos, _, err := fetchPRs(context.Background(), &fakeClient{fixture: fixture}, "owner", "repo", opts, false, &progress{quiet: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return prInfos
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+// ReviewEdge tells how many PRs of Author were reviewed by Reviewer.
+type ReviewEdge struct {
+	Reviewer string
+	Author   string
+	PRs      int
+}
+
+// ReviewGraph is the who-reviews-whom graph of the PRs. People are the
+// creators and reviewers of the PRs, also those without any edge, so that
+// silos show up as unconnected nodes.
+type ReviewGraph struct {
+	People []string
+	Edges  []ReviewEdge
+}
+
+// reviewGraph builds the review graph of the PRs. Reviewing your own PR is
+// not an edge.
+func reviewGraph(prData []PRInfo) ReviewGraph {
+	people := make(map[string]bool)
+	counts := make(map[[2]string]int)
+	for _, pr := range prData {
+		if pr.Creator != "" {
+			people[pr.Creator] = true
+		}
+		for _, reviewer := range unique(pr.Reviewers) {
+			people[reviewer] = true
+			if reviewer != pr.Creator && pr.Creator != "" {
+				counts[[2]string{reviewer, pr.Creator}]++
+			}
+		}
+	}
+
+	var graph ReviewGraph
+	for person := range people {
+		graph.People = append(graph.People, person)
+	}
+	sort.Strings(graph.People)
+	for pair, n := range counts {
+		graph.Edges = append(graph.Edges, ReviewEdge{Reviewer: pair[0], Author: pair[1], PRs: n})
+	}
+	sort.Slice(graph.Edges, func(i, j int) bool {
+		if graph.Edges[i].Reviewer != graph.Edges[j].Reviewer {
+			return graph.Edges[i].Reviewer < graph.Edges[j].Reviewer
+		}
+		return graph.Edges[i].Author < graph.Edges[j].Author
+	})
+	return graph
+}
+
+// unique returns the names without duplicates, in the order they first
+// appear.
+func unique(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	var result []string
+	for _, name := range names {
+		if name != "" && !seen[name] {
+			seen[name] = true
+			result = append(result, name)
+		}
+	}
+	return result
+}
+
+// writeReviewGraph writes the graph to path, as GraphML when the file has the
+// .graphml extension and in the DOT language of Graphviz otherwise.
+func writeReviewGraph(path string, graph ReviewGraph) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	if filepath.Ext(path) == ".graphml" {
+		err = writeGraphML(f, graph)
+	} else {
+		err = writeDOT(f, graph)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeDOT writes the graph with an edge from each reviewer to the authors
+// they reviewed, labelled and weighted by the number of PRs.
+func writeDOT(w io.Writer, graph ReviewGraph) error {
+	fmt.Fprintln(w, "digraph reviews {")
+	for _, person := range graph.People {
+		fmt.Fprintf(w, "  %s;\n", strconv.Quote(person))
+	}
+	for _, edge := range graph.Edges {
+		fmt.Fprintf(w, "  %s -> %s [label=%d, weight=%d];\n", strconv.Quote(edge.Reviewer), strconv.Quote(edge.Author), edge.PRs, edge.PRs)
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
+
+type graphML struct {
+	XMLName xml.Name     `xml:"graphml"`
+	XMLNS   string       `xml:"xmlns,attr"`
+	Keys    []graphMLKey `xml:"key"`
+	Graph   struct {
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	} `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLNode struct {
+	ID string `xml:"id,attr"`
+}
+
+type graphMLEdge struct {
+	Source string      `xml:"source,attr"`
+	Target string      `xml:"target,attr"`
+	Data   graphMLData `xml:"data"`
+}
+
+type graphMLData struct {
+	Key   string `xml:"key,attr"`
+	Value int    `xml:",chardata"`
+}
+
+// writeGraphML writes the graph as GraphML, the number of PRs of an edge is
+// its "prs" attribute.
+func writeGraphML(w io.Writer, graph ReviewGraph) error {
+	doc := graphML{
+		XMLNS: "http://graphml.graphdrawing.org/xmlns",
+		Keys:  []graphMLKey{{ID: "prs", For: "edge", Name: "prs", Type: "int"}},
+	}
+	doc.Graph.EdgeDefault = "directed"
+	for _, person := range graph.People {
+		doc.Graph.Nodes = append(doc.Graph.Nodes, graphMLNode{ID: person})
+	}
+	for _, edge := range graph.Edges {
+		doc.Graph.Edges = append(doc.Graph.Edges, graphMLEdge{Source: edge.Reviewer, Target: edge.Author, Data: graphMLData{Key: "prs", Value: edge.PRs}})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
@@ -0,0 +1,488 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/graphql-go/graphql"
+)
+
+// graphRepository is a repository of the GraphQL API, with its latest report
+// and the PRs of its latest run.
+type graphRepository struct {
+	report Report
+	prs    []PRInfo
+}
+
+// graphDeveloper is what a developer did on the PRs of a repository.
+type graphDeveloper struct {
+	Login               string
+	Team                string
+	Created             int
+	Merged              int
+	Reviewed            int
+	CommentedOn         int
+	FirstHumanResponses int
+	// reviewResponseTime is the total time the developer took to review the
+	// PRs of others, in reviews reviews.
+	reviewResponseTime time.Duration
+	reviews            int
+}
+
+// newGraphDevelopers counts what the developers did on the PRs, by login.
+func newGraphDevelopers(prs []PRInfo, teams map[string]string) []graphDeveloper {
+	developers := make(map[string]*graphDeveloper)
+	of := func(login string) *graphDeveloper {
+		if developers[login] == nil {
+			developers[login] = &graphDeveloper{Login: login, Team: teams[login]}
+		}
+		return developers[login]
+	}
+	for _, pr := range prs {
+		of(pr.Creator).Created++
+		if pr.Merger != "" {
+			of(pr.Merger).Merged++
+		}
+		for _, reviewer := range uniqueReviewers(pr) {
+			of(reviewer).Reviewed++
+		}
+		for _, commenter := range uniqueCommenters(pr) {
+			of(commenter).CommentedOn++
+		}
+		if pr.FirstHumanResponder != "" {
+			of(pr.FirstHumanResponder).FirstHumanResponses++
+		}
+		for reviewer, d := range pr.ReviewResponseTimes {
+			if reviewer != pr.Creator {
+				of(reviewer).reviewResponseTime += d
+				of(reviewer).reviews++
+			}
+		}
+	}
+
+	list := make([]graphDeveloper, 0, len(developers))
+	for _, developer := range developers {
+		list = append(list, *developer)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Login < list[j].Login })
+	return list
+}
+
+// graphFilterArgs are the arguments filtering the PRs of a repository.
+var graphFilterArgs = graphql.FieldConfigArgument{
+	"where": &graphql.ArgumentConfig{
+		Type:        graphql.String,
+		Description: "Only the PRs meeting the condition, in the syntax of --where.",
+	},
+	"creator": &graphql.ArgumentConfig{Type: graphql.String, Description: "Only the PRs of the creator."},
+	"team":    &graphql.ArgumentConfig{Type: graphql.String, Description: "Only the PRs of the members of the team."},
+	"year":    &graphql.ArgumentConfig{Type: graphql.Int, Description: "Only the PRs merged in the year."},
+	"quarter": &graphql.ArgumentConfig{Type: graphql.String, Description: "Only the PRs merged in the quarter, such as Q2."},
+}
+
+// graphPRArgs are the filter arguments of the PRs field, which can also only
+// return the first ones.
+var graphPRArgs = func() graphql.FieldConfigArgument {
+	args := graphql.FieldConfigArgument{
+		"first": &graphql.ArgumentConfig{Type: graphql.Int, Description: "Only the first PRs, by number."},
+	}
+	for name, arg := range graphFilterArgs {
+		args[name] = arg
+	}
+	return args
+}()
+
+// filterGraphPRs returns the PRs matching the filter arguments, by number.
+func filterGraphPRs(prs []PRInfo, args map[string]any, teams map[string]string) ([]PRInfo, error) {
+	var where whereFilter
+	if expr, ok := args["where"].(string); ok && expr != "" {
+		var err error
+		if where, err = parseWhere(expr); err != nil {
+			return nil, err
+		}
+	}
+	creator, _ := args["creator"].(string)
+	team, _ := args["team"].(string)
+	year, _ := args["year"].(int)
+	quarter, _ := args["quarter"].(string)
+
+	var matching []PRInfo
+	for _, pr := range prs {
+		switch {
+		case where.match != nil && !where.match(pr):
+		case creator != "" && pr.Creator != creator:
+		case team != "" && teams[pr.Creator] != team:
+		case year != 0 && pr.Year != year:
+		case quarter != "" && pr.Quarter != quarter:
+		default:
+			matching = append(matching, pr)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if matching[i].Repository != matching[j].Repository {
+			return matching[i].Repository < matching[j].Repository
+		}
+		return matching[i].Number < matching[j].Number
+	})
+	return matching, nil
+}
+
+func seconds(d time.Duration) float64 {
+	return d.Seconds()
+}
+
+// graphDuration resolves a duration of a PR to seconds.
+func graphDuration(value func(pr PRInfo) time.Duration) *graphql.Field {
+	return &graphql.Field{
+		Type:        graphql.Float,
+		Description: "In seconds.",
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			return seconds(value(p.Source.(PRInfo))), nil
+		},
+	}
+}
+
+// graphTime resolves a time of a PR, null when the PR does not have it.
+func graphTime(value func(pr PRInfo) time.Time) *graphql.Field {
+	return &graphql.Field{
+		Type: graphql.DateTime,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			if t := value(p.Source.(PRInfo)); !t.IsZero() {
+				return t, nil
+			}
+			return nil, nil
+		},
+	}
+}
+
+var graphPRType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "PR",
+	Fields: graphql.Fields{
+		"repository":               &graphql.Field{Type: graphql.String},
+		"number":                   &graphql.Field{Type: graphql.Int},
+		"title":                    &graphql.Field{Type: graphql.String},
+		"creator":                  &graphql.Field{Type: graphql.String},
+		"merger":                   &graphql.Field{Type: graphql.String},
+		"type":                     &graphql.Field{Type: graphql.String},
+		"milestone":                &graphql.Field{Type: graphql.String},
+		"year":                     &graphql.Field{Type: graphql.Int},
+		"quarter":                  &graphql.Field{Type: graphql.String},
+		"labels":                   &graphql.Field{Type: graphql.NewList(graphql.String)},
+		"files":                    &graphql.Field{Type: graphql.NewList(graphql.String)},
+		"approvers":                &graphql.Field{Type: graphql.NewList(graphql.String)},
+		"commits":                  &graphql.Field{Type: graphql.Int},
+		"firstHumanResponder":      &graphql.Field{Type: graphql.String},
+		"isRevert":                 &graphql.Field{Type: graphql.Boolean},
+		"isHotfix":                 &graphql.Field{Type: graphql.Boolean},
+		"createdAt":                graphTime(func(pr PRInfo) time.Time { return pr.CreatedAt }),
+		"mergedAt":                 graphTime(func(pr PRInfo) time.Time { return pr.MergedAt }),
+		"mergeTime":                graphDuration(func(pr PRInfo) time.Duration { return pr.Duration }),
+		"timeToFirstResponse":      graphDuration(func(pr PRInfo) time.Duration { return pr.TimeToFirstResponse }),
+		"timeToFirstHumanResponse": graphDuration(func(pr PRInfo) time.Duration { return pr.TimeToFirstHumanResponse }),
+		"ciTime":                   graphDuration(func(pr PRInfo) time.Duration { return pr.CITime }),
+		"reviewers": &graphql.Field{
+			Type:    graphql.NewList(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (any, error) { return uniqueReviewers(p.Source.(PRInfo)), nil },
+		},
+		"commenters": &graphql.Field{
+			Type:    graphql.NewList(graphql.String),
+			Resolve: func(p graphql.ResolveParams) (any, error) { return uniqueCommenters(p.Source.(PRInfo)), nil },
+		},
+	},
+})
+
+var graphDeveloperType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Developer",
+	Fields: graphql.Fields{
+		"login":               &graphql.Field{Type: graphql.String},
+		"team":                &graphql.Field{Type: graphql.String},
+		"created":             &graphql.Field{Type: graphql.Int, Description: "The number of PRs the developer created."},
+		"merged":              &graphql.Field{Type: graphql.Int, Description: "The number of PRs the developer merged."},
+		"reviewed":            &graphql.Field{Type: graphql.Int, Description: "The number of PRs the developer reviewed."},
+		"commentedOn":         &graphql.Field{Type: graphql.Int, Description: "The number of PRs the developer commented on."},
+		"firstHumanResponses": &graphql.Field{Type: graphql.Int, Description: "The number of PRs the developer responded to first."},
+		"averageReviewResponseTime": &graphql.Field{
+			Type:        graphql.Float,
+			Description: "The average time the developer took to review the PRs of others, in seconds.",
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				developer := p.Source.(graphDeveloper)
+				if developer.reviews == 0 {
+					return nil, nil
+				}
+				return seconds(developer.reviewResponseTime / time.Duration(developer.reviews)), nil
+			},
+		},
+	},
+})
+
+var graphLeaderboardEntryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "LeaderboardEntry",
+	Fields: graphql.Fields{
+		"rank":  &graphql.Field{Type: graphql.Int},
+		"name":  &graphql.Field{Type: graphql.String},
+		"count": &graphql.Field{Type: graphql.Int},
+		"share": &graphql.Field{Type: graphql.Float},
+	},
+})
+
+// graphAggregatesType resolves from the fields of the apiSummary of a report,
+// so the aggregates are the ones of /api/repos/{owner}/{repo}.
+var graphAggregatesType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Aggregates",
+	Fields: graphql.Fields{
+		"year":                            &graphql.Field{Type: graphql.Int},
+		"quarter":                         &graphql.Field{Type: graphql.String},
+		"prs":                             &graphql.Field{Type: graphql.Int},
+		"averageMergeTime":                &graphql.Field{Type: graphql.Float, Description: "In seconds."},
+		"averageTimeToFirstHumanResponse": &graphql.Field{Type: graphql.Float, Description: "In seconds."},
+		"averageTimeToFirstBotResponse":   &graphql.Field{Type: graphql.Float, Description: "In seconds."},
+		"averageCITime":                   &graphql.Field{Type: graphql.Float, Description: "In seconds."},
+		"averageNumberOfReviewers":        &graphql.Field{Type: graphql.Float},
+		"averageNumberOfComments":         &graphql.Field{Type: graphql.Float},
+		"slaCompliance":                   &graphql.Field{Type: graphql.Float},
+		"leaderboard": &graphql.Field{
+			Type: graphql.NewList(graphLeaderboardEntryType),
+			Args: graphql.FieldConfigArgument{
+				"name": &graphql.ArgumentConfig{
+					Type:        graphql.NewNonNull(graphql.String),
+					Description: "reviewers, commenters, creators, first-human-responders, first-responders, mergers, approvers or changes-requesters.",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return p.Source.(map[string]any)["leaderboards"].(map[string][]LeaderboardEntry)[p.Args["name"].(string)], nil
+			},
+		},
+	},
+})
+
+var graphWeekType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Week",
+	Fields: graphql.Fields{
+		"week":                            &graphql.Field{Type: graphql.String, Description: "The Monday of the week."},
+		"prs":                             &graphql.Field{Type: graphql.Int},
+		"averageMergeTime":                &graphql.Field{Type: graphql.Float, Description: "In seconds."},
+		"averageTimeToFirstHumanResponse": &graphql.Field{Type: graphql.Float, Description: "In seconds."},
+		"slaBreaches":                     &graphql.Field{Type: graphql.Int},
+	},
+})
+
+type graphRequestKey struct{}
+
+// newGraphSchema returns the schema of the GraphQL API over the PRs of the
+// latest runs of the server. Every field of the PRs of a repository takes the
+// same filter arguments.
+func (s *reportServer) newGraphSchema() (graphql.Schema, error) {
+	repository := func(p graphql.ResolveParams) graphRepository { return p.Source.(graphRepository) }
+	filtered := func(p graphql.ResolveParams) ([]PRInfo, error) {
+		return filterGraphPRs(repository(p).prs, p.Args, s.reportOpts.Teams)
+	}
+
+	repositoryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Repository",
+		Fields: graphql.Fields{
+			"owner": &graphql.Field{
+				Type:    graphql.String,
+				Resolve: func(p graphql.ResolveParams) (any, error) { return repository(p).report.Owner, nil },
+			},
+			"repo": &graphql.Field{
+				Type:    graphql.String,
+				Resolve: func(p graphql.ResolveParams) (any, error) { return repository(p).report.Repo, nil },
+			},
+			"incomplete": &graphql.Field{
+				Type:        graphql.Boolean,
+				Description: "Whether fetching the PRs of the latest run was interrupted.",
+				Resolve:     func(p graphql.ResolveParams) (any, error) { return repository(p).report.Incomplete, nil },
+			},
+			"prs": &graphql.Field{
+				Type:        graphql.NewList(graphPRType),
+				Description: "The PRs of the latest run.",
+				Args:        graphPRArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					prs, err := filtered(p)
+					if first, ok := p.Args["first"].(int); ok && first >= 0 && first < len(prs) {
+						prs = prs[:first]
+					}
+					return prs, err
+				},
+			},
+			"developers": &graphql.Field{
+				Type:        graphql.NewList(graphDeveloperType),
+				Description: "The developers of the PRs of the latest run, by login.",
+				Args:        graphFilterArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					prs, err := filtered(p)
+					if err != nil {
+						return nil, err
+					}
+					return newGraphDevelopers(prs, s.reportOpts.Teams), nil
+				},
+			},
+			"aggregates": &graphql.Field{
+				Type:        graphAggregatesType,
+				Description: "The metrics of the PRs, the ones of the quarter of the latest report unless the year or the quarter is given.",
+				Args:        graphFilterArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					r := repository(p).report
+					if year, ok := p.Args["year"].(int); ok {
+						r.Year = year
+					}
+					if quarter, ok := p.Args["quarter"].(string); ok {
+						r.Quarter = quarter
+					}
+					args := map[string]any{"year": r.Year, "quarter": r.Quarter}
+					for _, name := range []string{"where", "creator", "team"} {
+						args[name] = p.Args[name]
+					}
+					prs, err := filterGraphPRs(repository(p).prs, args, s.reportOpts.Teams)
+					if err != nil {
+						return nil, err
+					}
+					incomplete := r.Incomplete
+					r = newReport(r.Owner, r.Repo, r.Year, r.Quarter, prs, s.reportOpts)
+					r.Incomplete = incomplete
+					summary := newAPISummary(r)
+					return map[string]any{
+						"year":                            summary.Year,
+						"quarter":                         summary.Quarter,
+						"prs":                             summary.PRs,
+						"averageMergeTime":                summary.AverageMergeTime,
+						"averageTimeToFirstHumanResponse": summary.AverageTimeToFirstHumanResponse,
+						"averageTimeToFirstBotResponse":   summary.AverageTimeToFirstBotResponse,
+						"averageCITime":                   summary.AverageCITime,
+						"averageNumberOfReviewers":        summary.AverageNumberOfReviewers,
+						"averageNumberOfComments":         summary.AverageNumberOfComments,
+						"slaCompliance":                   summary.SLACompliance,
+						"leaderboards":                    summary.Leaderboards,
+					}, nil
+				},
+			},
+			"weeks": &graphql.Field{
+				Type:        graphql.NewList(graphWeekType),
+				Description: "The weekly trends of the PRs of the latest run.",
+				Args:        graphFilterArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					prs, err := filtered(p)
+					if err != nil {
+						return nil, err
+					}
+					var weeks []map[string]any
+					for _, week := range newAPIWeeks(prs, s.sla) {
+						weeks = append(weeks, map[string]any{
+							"week":                            week.Week,
+							"prs":                             week.PRs,
+							"averageMergeTime":                week.AverageMergeTime,
+							"averageTimeToFirstHumanResponse": week.AverageTimeToFirstHumanResponse,
+							"slaBreaches":                     week.SLABreaches,
+						})
+					}
+					return weeks, nil
+				},
+			},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"repositories": &graphql.Field{
+				Type:        graphql.NewList(repositoryType),
+				Description: "The repositories the user can read, by name.",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return s.graphRepositories(p.Context, "", ""), nil
+				},
+			},
+			"repository": &graphql.Field{
+				Type: repositoryType,
+				Args: graphql.FieldConfigArgument{
+					"owner": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"repo":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+				},
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					repos := s.graphRepositories(p.Context, p.Args["owner"].(string), p.Args["repo"].(string))
+					if len(repos) == 0 {
+						return nil, nil
+					}
+					return repos[0], nil
+				},
+			},
+		},
+	})
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType})
+}
+
+// graphRepositories returns the repositories with a report the user of the
+// request in ctx can read, by name, only owner/repo when they are given.
+func (s *reportServer) graphRepositories(ctx context.Context, owner string, repo string) []graphRepository {
+	req := ctx.Value(graphRequestKey{}).(*http.Request)
+	s.mu.RLock()
+	var repos []graphRepository
+	for key, r := range s.latest {
+		if owner == "" || (r.Owner == owner && r.Repo == repo) {
+			repos = append(repos, graphRepository{report: r, prs: s.history[key]})
+		}
+	}
+	s.mu.RUnlock()
+
+	readable := []graphRepository{}
+	for _, repo := range repos {
+		if s.canRead(req, repo.report.Owner, repo.report.Repo) {
+			readable = append(readable, repo)
+		}
+	}
+	sort.Slice(readable, func(i, j int) bool {
+		return readable[i].report.Owner+"/"+readable[i].report.Repo < readable[j].report.Owner+"/"+readable[j].report.Repo
+	})
+	return readable
+}
+
+// graphQuery is the body of a POST request to /graphql.
+type graphQuery struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// graphHandler answers the GraphQL queries of /graphql, as the query
+// parameter of GET requests or as the JSON body of POST requests.
+func (s *reportServer) graphHandler() http.HandlerFunc {
+	schema, err := s.newGraphSchema()
+	if err != nil {
+		// The schema does not depend on the input, like the templates.
+		panic(err)
+	}
+	return func(w http.ResponseWriter, req *http.Request) {
+		var query graphQuery
+		switch req.Method {
+		case http.MethodGet:
+			query.Query = req.URL.Query().Get("query")
+			query.OperationName = req.URL.Query().Get("operationName")
+			if variables := req.URL.Query().Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &query.Variables); err != nil {
+					http.Error(w, "invalid variables: "+err.Error(), http.StatusBadRequest)
+					return
+				}
+			}
+		case http.MethodPost:
+			if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 1<<20)).Decode(&query); err != nil {
+				http.Error(w, "invalid query: "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+
+		result := graphql.Do(graphql.Params{
+			Schema:         schema,
+			RequestString:  query.Query,
+			OperationName:  query.OperationName,
+			VariableValues: query.Variables,
+			Context:        context.WithValue(req.Context(), graphRequestKey{}, req),
+		})
+		writeAPI(w, result)
+	}
+}
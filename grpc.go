@@ -0,0 +1,258 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/drpaneas/time2review/metricspb"
+)
+
+// metricsService is the gRPC service of metricspb over the latest reports of
+// the server. Unlike the HTTP server it does not log the users in, it is
+// meant for the services of the same network, and of the same machine when
+// the HTTP server does. Its callers then read the public repositories only,
+// like the users of the HTTP server who did not log in.
+type metricsService struct {
+	metricspb.UnimplementedMetricsServer
+	server *reportServer
+}
+
+func newRepoStats(r Report) *metricspb.RepoStats {
+	summary := newAPISummary(r)
+	stats := &metricspb.RepoStats{
+		Owner:                           r.Owner,
+		Repo:                            r.Repo,
+		Year:                            int32(r.Year),
+		Quarter:                         r.Quarter,
+		Incomplete:                      r.Incomplete,
+		Prs:                             int32(len(r.PRs)),
+		AverageMergeTime:                durationpb.New(r.AverageMergeTime),
+		AverageTimeToFirstHumanResponse: durationpb.New(r.AverageTimeToFirstHumanResponse),
+		AverageTimeToFirstBotResponse:   durationpb.New(r.AverageTimeToFirstBotResponse),
+		AverageCiTime:                   durationpb.New(r.AverageCITime),
+		AverageReviewers:                r.AverageNumberOfReviewers,
+		AverageComments:                 r.AverageNumberOfComments,
+		SlaCompliance:                   r.SLACompliance,
+	}
+	for _, name := range sortedKeys(summary.Leaderboards) {
+		board := &metricspb.Leaderboard{Name: name}
+		for _, entry := range summary.Leaderboards[name] {
+			board.Entries = append(board.Entries, &metricspb.LeaderboardEntry{Rank: int32(entry.Rank), Name: entry.Name, Count: int32(entry.Count), Share: entry.Share})
+		}
+		stats.Leaderboards = append(stats.Leaderboards, board)
+	}
+	return stats
+}
+
+// timestamp converts a time of a PR, nil when the PR does not have it.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func newPRInfoMessage(pr PRInfo) *metricspb.PRInfo {
+	return &metricspb.PRInfo{
+		Number:                   int32(pr.Number),
+		Title:                    pr.Title,
+		Creator:                  pr.Creator,
+		CreatedAt:                timestamp(pr.CreatedAt),
+		MergedAt:                 timestamp(pr.MergedAt),
+		Merger:                   pr.Merger,
+		Type:                     pr.Type,
+		Milestone:                pr.Milestone,
+		Year:                     int32(pr.Year),
+		Quarter:                  pr.Quarter,
+		Labels:                   pr.Labels,
+		Reviewers:                uniqueReviewers(pr),
+		Commenters:               uniqueCommenters(pr),
+		Approvers:                pr.Approvers,
+		Commits:                  int32(pr.Commits),
+		FirstHumanResponder:      pr.FirstHumanResponder,
+		MergeTime:                durationpb.New(pr.Duration),
+		TimeToFirstResponse:      durationpb.New(pr.TimeToFirstResponse),
+		TimeToFirstHumanResponse: durationpb.New(pr.TimeToFirstHumanResponse),
+		CiTime:                   durationpb.New(pr.CITime),
+		IsRevert:                 pr.IsRevert,
+		IsHotfix:                 pr.IsHotfix,
+	}
+}
+
+// parseRequestWhere parses the where condition of a request, if any.
+func parseRequestWhere(expr string) (whereFilter, error) {
+	if expr == "" {
+		return whereFilter{}, nil
+	}
+	where, err := parseWhere(expr)
+	if err != nil {
+		return whereFilter{}, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return where, nil
+}
+
+func (m *metricsService) GetRepoStats(ctx context.Context, req *metricspb.GetRepoStatsRequest) (*metricspb.RepoStats, error) {
+	where, err := parseRequestWhere(req.Where)
+	if err != nil {
+		return nil, err
+	}
+	r, ok := m.server.report(req.Owner, req.Repo)
+	if !ok || !m.server.canReadAs(ctx, nil, req.Owner, req.Repo) {
+		return nil, status.Errorf(codes.NotFound, "no report of %s/%s", req.Owner, req.Repo)
+	}
+	if where.match != nil {
+		prs, _ := m.server.prs(req.Owner, req.Repo)
+		incomplete := r.Incomplete
+		r = newReport(r.Owner, r.Repo, r.Year, r.Quarter, filterPRInfosByQuarterAndYear(where.filter(prs), r.Year, r.Quarter), m.server.reportOpts)
+		r.Incomplete = incomplete
+	}
+	return newRepoStats(r), nil
+}
+
+func (m *metricsService) ListPRInfos(ctx context.Context, req *metricspb.ListPRInfosRequest) (*metricspb.ListPRInfosResponse, error) {
+	where, err := parseRequestWhere(req.Where)
+	if err != nil {
+		return nil, err
+	}
+	// The page tokens are the owner/repo#N of the last PR of the previous
+	// page, the PRs coming by repository and number
+	afterRepository, afterNumber := "", 0
+	if req.PageToken != "" {
+		i := strings.LastIndex(req.PageToken, "#")
+		if afterNumber, err = strconv.Atoi(req.PageToken[i+1:]); err != nil || i < 0 {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page token %q", req.PageToken)
+		}
+		afterRepository = req.PageToken[:i]
+	}
+	following := func(pr PRInfo) bool {
+		if pr.Repository != afterRepository {
+			return pr.Repository > afterRepository
+		}
+		return pr.Number > afterNumber
+	}
+	size := int(req.PageSize)
+	if size <= 0 {
+		size = 100
+	}
+	prs, ok := m.server.prs(req.Owner, req.Repo)
+	if !ok || !m.server.canReadAs(ctx, nil, req.Owner, req.Repo) {
+		return nil, status.Errorf(codes.NotFound, "no PRs of %s/%s", req.Owner, req.Repo)
+	}
+
+	var matching []PRInfo
+	for _, pr := range prs {
+		if following(pr) && (where.match == nil || where.match(pr)) {
+			matching = append(matching, pr)
+		}
+	}
+	sort.Slice(matching, func(i, j int) bool {
+		if matching[i].Repository != matching[j].Repository {
+			return matching[i].Repository < matching[j].Repository
+		}
+		return matching[i].Number < matching[j].Number
+	})
+	resp := &metricspb.ListPRInfosResponse{}
+	if len(matching) > size {
+		matching = matching[:size]
+		resp.NextPageToken = prReference(matching[size-1].Repository, matching[size-1].Number)
+	}
+	for _, pr := range matching {
+		resp.Prs = append(resp.Prs, newPRInfoMessage(pr))
+	}
+	return resp, nil
+}
+
+func (m *metricsService) StreamUpdates(req *metricspb.StreamUpdatesRequest, stream metricspb.Metrics_StreamUpdatesServer) error {
+	wanted := make(map[string]bool)
+	for _, repo := range req.Repositories {
+		wanted[repo] = true
+	}
+	send := func(r Report) error {
+		if (len(wanted) > 0 && !wanted[r.Owner+"/"+r.Repo]) || !m.server.canReadAs(stream.Context(), nil, r.Owner, r.Repo) {
+			return nil
+		}
+		return stream.Send(newRepoStats(r))
+	}
+
+	// Subscribe before sending the current reports so that no update falls
+	// in between
+	updates, unsubscribe := m.server.subscribe()
+	defer unsubscribe()
+	m.server.mu.RLock()
+	var latest []Report
+	for _, key := range sortedKeys(m.server.latest) {
+		latest = append(latest, m.server.latest[key])
+	}
+	m.server.mu.RUnlock()
+	for _, r := range latest {
+		if err := send(r); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case r := <-updates:
+			if err := send(r); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// loopbackAddr reports whether addr only listens on the loopback interface.
+func loopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// serveGRPC serves the gRPC service on addr until ctx is done.
+func (s *reportServer) serveGRPC(ctx context.Context, addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	server := grpc.NewServer()
+	metricspb.RegisterMetricsServer(server, &metricsService{server: s})
+	go func() {
+		<-ctx.Done()
+		// The streams only end when their clients cancel them
+		stopped := make(chan struct{})
+		go func() {
+			server.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-time.After(5 * time.Second):
+			server.Stop()
+		}
+	}()
+
+	slog.Info("Serving the gRPC service", "addr", addr)
+	if err := server.Serve(listener); err != nil && !errors.Is(err, grpc.ErrServerStopped) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/drpaneas/time2review/metricspb"
+)
+
+func TestMetricsServiceAccess(t *testing.T) {
+	// The login already knows which repositories are public, so GitHub is
+	// not asked
+	until := time.Now().Add(time.Hour)
+	login := &githubLogin{public: map[string]repoAccess{
+		"owner/public":  {readable: true, until: until},
+		"owner/private": {readable: false, until: until},
+	}}
+
+	for _, tc := range []struct {
+		name  string
+		login *githubLogin
+		repo  string
+		want  codes.Code
+	}{
+		{name: "without login", repo: "private", want: codes.OK},
+		{name: "public", login: login, repo: "public", want: codes.OK},
+		{name: "private", login: login, repo: "private", want: codes.NotFound},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			server := newReportServer(SLA{})
+			server.login = tc.login
+			for _, repo := range []string{"public", "private"} {
+				server.update(Report{Owner: "owner", Repo: repo, Year: 2024, Quarter: "Q1"})
+				server.updatePRs("owner", repo, []PRInfo{{Repository: "owner/" + repo, Number: 1}})
+			}
+			service := &metricsService{server: server}
+
+			_, err := service.GetRepoStats(context.Background(), &metricspb.GetRepoStatsRequest{Owner: "owner", Repo: tc.repo})
+			if got := status.Code(err); got != tc.want {
+				t.Errorf("got %s for the stats, want %s", got, tc.want)
+			}
+			_, err = service.ListPRInfos(context.Background(), &metricspb.ListPRInfosRequest{Owner: "owner", Repo: tc.repo})
+			if got := status.Code(err); got != tc.want {
+				t.Errorf("got %s for the PRs, want %s", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var defaultHistogramBuckets = durationList{time.Hour, 4 * time.Hour, 24 * time.Hour, 3 * 24 * time.Hour, 7 * 24 * time.Hour}
+
+// durationList is a flag holding a comma separated list of durations.
+type durationList []time.Duration
+
+func (l *durationList) String() string {
+	var parts []string
+	for _, d := range *l {
+		parts = append(parts, shortDuration(d))
+	}
+	return strings.Join(parts, ",")
+}
+
+func (l *durationList) Set(value string) error {
+	var durations durationList
+	for _, part := range strings.Split(value, ",") {
+		d, err := parseShortDuration(strings.TrimSpace(part))
+		if err != nil {
+			return err
+		}
+		durations = append(durations, d)
+	}
+	sort.Slice(durations, func(i, j int) bool { return durations[i] < durations[j] })
+	*l = durations
+	return nil
+}
+
+// parseShortDuration parses a Go duration, additionally accepting a number of
+// days such as "3d" or of years of 365 days such as "3y".
+func parseShortDuration(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "y": 365 * 24 * time.Hour} {
+		if count, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.Atoi(count)
+			if err != nil {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	return time.ParseDuration(s)
+}
+
+// shortDuration formats whole days and hours the way they are usually written
+// in bucket labels, e.g. "3d" and "4h".
+func shortDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour && d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return d.String()
+	}
+}
+
+// HistogramBucket is the number of PRs whose merge time falls in a range.
+type HistogramBucket struct {
+	Label string
+	Count int
+}
+
+// mergeTimeHistogram puts the PRs into the buckets delimited by the given
+// (sorted) upper bounds, plus one bucket for everything above the last bound.
+// Without bounds all PRs are in a single bucket.
+func mergeTimeHistogram(prData []PRInfo, bounds []time.Duration) []HistogramBucket {
+	buckets := make([]HistogramBucket, len(bounds)+1)
+	for i := range buckets {
+		switch {
+		case len(bounds) == 0:
+			buckets[i].Label = "all"
+		case i == 0:
+			buckets[i].Label = "<" + shortDuration(bounds[0])
+		case i == len(bounds):
+			buckets[i].Label = ">" + shortDuration(bounds[i-1])
+		default:
+			buckets[i].Label = shortDuration(bounds[i-1]) + "-" + shortDuration(bounds[i])
+		}
+	}
+	for _, pr := range prData {
+		i := sort.Search(len(bounds), func(i int) bool { return pr.Duration < bounds[i] })
+		buckets[i].Count++
+	}
+	return buckets
+}
+
+func histogramBarChart(buckets []HistogramBucket) string {
+	labels := make([]string, len(buckets))
+	values := make([]float64, len(buckets))
+	for i, bucket := range buckets {
+		labels[i] = bucket.Label
+		values[i] = float64(bucket.Count)
+	}
+	return barChart(labels, values, 40)
+}
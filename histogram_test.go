@@ -0,0 +1,81 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseShortDuration(t *testing.T) {
+	for _, tc := range []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "90m", want: 90 * time.Minute},
+		{value: "4h", want: 4 * time.Hour},
+		{value: "3d", want: 3 * 24 * time.Hour},
+		{value: "1y", want: 365 * 24 * time.Hour},
+		{value: "d", wantErr: true},
+		{value: "3xd", wantErr: true},
+		{value: "4hx", wantErr: true},
+		{value: "1.5d", wantErr: true},
+		{value: "soon", wantErr: true},
+	} {
+		t.Run(tc.value, func(t *testing.T) {
+			got, err := parseShortDuration(tc.value)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got the error %v, want an error: %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestMergeTimeHistogram(t *testing.T) {
+	prData := []PRInfo{
+		{Duration: 30 * time.Minute},
+		{Duration: time.Hour},
+		{Duration: 5 * time.Hour},
+		{Duration: 2 * 24 * time.Hour},
+		{Duration: 10 * 24 * time.Hour},
+	}
+	for _, tc := range []struct {
+		name   string
+		bounds []time.Duration
+		want   []HistogramBucket
+	}{
+		{
+			name:   "default",
+			bounds: defaultHistogramBuckets,
+			want: []HistogramBucket{
+				{Label: "<1h", Count: 1},
+				{Label: "1h-4h", Count: 1},
+				{Label: "4h-1d", Count: 1},
+				{Label: "1d-3d", Count: 1},
+				{Label: "3d-7d", Count: 0},
+				{Label: ">7d", Count: 1},
+			},
+		},
+		{
+			name:   "one bound",
+			bounds: []time.Duration{24 * time.Hour},
+			want: []HistogramBucket{
+				{Label: "<1d", Count: 3},
+				{Label: ">1d", Count: 2},
+			},
+		},
+		{
+			name: "no bounds",
+			want: []HistogramBucket{{Label: "all", Count: 5}},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := mergeTimeHistogram(prData, tc.bounds); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// longestIdleGap returns the longest time without any comment, review or push
+// on the PR between it being ready for review and its merge.
+func longestIdleGap(pr PRInfo, comments []prComment, reviews []*github.PullRequestReview, pushes []time.Time, opts collectOptions) time.Duration {
+	start := pr.ReadyForReviewAt
+	if start.IsZero() {
+		start = pr.CreatedAt
+	}
+	activity := []time.Time{start, pr.MergedAt}
+	for _, comment := range comments {
+		activity = append(activity, comment.CreatedAt)
+	}
+	for _, review := range reviews {
+		if review.SubmittedAt != nil {
+			activity = append(activity, review.GetSubmittedAt())
+		}
+	}
+	activity = append(activity, pushes...)
+	sort.Slice(activity, func(i, j int) bool { return activity[i].Before(activity[j]) })
+
+	var longest time.Duration
+	last := start
+	for _, t := range activity {
+		if t.Before(start) {
+			continue
+		}
+		if t.After(pr.MergedAt) {
+			break
+		}
+		longest = max(longest, opts.elapsed(last, t))
+		last = t
+	}
+	return longest
+}
+
+// IdleGap is a PR that went without any activity for longer than
+// --idle-gap.
+type IdleGap struct {
+	Number int
+	Title  string
+	Gap    time.Duration
+}
+
+// idleGaps returns the PRs whose longest idle gap exceeds the threshold, the
+// longest gap first, none when the threshold is zero.
+func idleGaps(prData []PRInfo, threshold time.Duration) []IdleGap {
+	if threshold <= 0 {
+		return nil
+	}
+	var gaps []IdleGap
+	for _, pr := range prData {
+		if pr.LongestIdleGap > threshold {
+			gaps = append(gaps, IdleGap{Number: pr.Number, Title: pr.Title, Gap: pr.LongestIdleGap})
+		}
+	}
+	sort.SliceStable(gaps, func(i, j int) bool { return gaps[i].Gap > gaps[j].Gap })
+	return gaps
+}
+
+func printIdleGaps(w io.Writer, gaps []IdleGap, threshold time.Duration) {
+	if threshold <= 0 {
+		return
+	}
+	fmt.Fprintf(w, "PRs idle for longer than %s: %d\n", formatDuration(threshold), len(gaps))
+	for _, gap := range gaps {
+		fmt.Fprintf(w, "  #%d %s: idle for %s\n", gap.Number, gap.Title, formatDuration(gap.Gap))
+	}
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLongestIdleGap(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		pr   testPR
+		want time.Duration
+	}{
+		{
+			name: "no activity",
+			pr:   testPR{merged: "2024-01-08T15:00:00Z"},
+			want: 6 * time.Hour,
+		},
+		{
+			name: "comments, reviews and pushes",
+			pr: testPR{
+				merged:   "2024-01-09T09:00:00Z",
+				comments: []testEvent{{login: "bob", at: "2024-01-08T10:00:00Z"}},
+				reviews:  []testEvent{{login: "bob", state: "CHANGES_REQUESTED", at: "2024-01-08T12:00:00Z"}},
+				pushes:   []string{"2024-01-09T02:00:00Z"},
+			},
+			want: 14 * time.Hour,
+		},
+		{
+			name: "pushes before the creation do not count",
+			pr: testPR{
+				merged: "2024-01-08T12:00:00Z",
+				pushes: []string{"2024-01-07T12:00:00Z", "2024-01-08T10:00:00Z"},
+			},
+			want: 2 * time.Hour,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.pr.number, tc.pr.created = 1, "2024-01-08T09:00:00Z"
+			pr := collect(t, newFixture(t, tc.pr), collectOptions{})[0]
+			if pr.LongestIdleGap != tc.want {
+				t.Errorf("got %v, want %v", pr.LongestIdleGap, tc.want)
+			}
+		})
+	}
+}
+
+func TestIdleGaps(t *testing.T) {
+	prData := []PRInfo{
+		{Number: 1, LongestIdleGap: 2 * time.Hour},
+		{Number: 2, LongestIdleGap: 30 * time.Hour},
+		{Number: 3, LongestIdleGap: 50 * time.Hour},
+	}
+	for _, tc := range []struct {
+		name      string
+		threshold time.Duration
+		want      []IdleGap
+	}{
+		{name: "disabled"},
+		{
+			name:      "longest first",
+			threshold: 24 * time.Hour,
+			want:      []IdleGap{{Number: 3, Gap: 50 * time.Hour}, {Number: 2, Gap: 30 * time.Hour}},
+		},
+		{name: "none above", threshold: 100 * time.Hour},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := idleGaps(prData, tc.threshold); !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("got %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
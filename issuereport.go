@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// reportIssueLabel is the label of the issues the reports are archived in,
+// which the existing ones are looked up by.
+const reportIssueLabel = "time2review"
+
+// reportPeriod is the period covered by the issue of --issue-report: "weekly"
+// or "monthly".
+type reportPeriod string
+
+const (
+	weeklyReports  reportPeriod = "weekly"
+	monthlyReports reportPeriod = "monthly"
+)
+
+func (p *reportPeriod) String() string {
+	return string(*p)
+}
+
+func (p *reportPeriod) Set(value string) error {
+	switch reportPeriod(value) {
+	case weeklyReports, monthlyReports:
+		*p = reportPeriod(value)
+		return nil
+	default:
+		return fmt.Errorf("unknown report period %q, expected weekly or monthly", value)
+	}
+}
+
+// start returns the start of the period t is in, in UTC.
+func (p reportPeriod) start(t time.Time) time.Time {
+	if p == monthlyReports {
+		t = t.UTC()
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+	return weekStart(t)
+}
+
+func (p reportPeriod) name(start time.Time) string {
+	if p == monthlyReports {
+		return start.Format("January 2006")
+	}
+	return "the week of " + start.Format(time.DateOnly)
+}
+
+// issueReport is the markdown report of a period archived in an issue.
+type issueReport struct {
+	// repository is the owner/repo the report is about, which the PRs are
+	// referenced with as the issue may be opened in another repository.
+	repository string
+	// marker starts the body, so that the issue of the period is updated by
+	// the later runs instead of opening another one.
+	marker string
+	title  string
+	body   string
+}
+
+// newIssueReport returns the report of the most recent period the PRs were
+// merged in, false when there are no PRs.
+func newIssueReport(owner string, repo string, prData []PRInfo, sla SLA, period reportPeriod) (issueReport, bool) {
+	var start time.Time
+	for _, pr := range prData {
+		if s := period.start(pr.MergedAt); s.After(start) {
+			start = s
+		}
+	}
+	var prs []PRInfo
+	var breaches []SLABreach
+	for _, pr := range prData {
+		if period.start(pr.MergedAt).Equal(start) {
+			prs = append(prs, pr)
+			breaches = append(breaches, slaBreaches(pr, sla)...)
+		}
+	}
+	if len(prs) == 0 {
+		return issueReport{}, false
+	}
+	sort.Slice(prs, func(i, j int) bool { return prs[i].Duration > prs[j].Duration })
+	sortBreaches(breaches)
+
+	r := issueReport{repository: owner + "/" + repo}
+	r.marker = fmt.Sprintf("<!-- time2review %s %s %s -->", period, r.repository, start.Format(time.DateOnly))
+	r.title = fmt.Sprintf("Review metrics of %s for %s", r.repository, period.name(start))
+	var body strings.Builder
+	body.WriteString(r.marker + "\n")
+	fmt.Fprintf(&body, "## %s\n\n", r.title)
+	body.WriteString("| Metric | Value |\n|---|---|\n")
+	fmt.Fprintf(&body, "| PRs merged | %d |\n", len(prs))
+	fmt.Fprintf(&body, "| Average merge time | %s |\n", humanDuration(averageMergeTime(prs).Round(time.Minute)))
+	fmt.Fprintf(&body, "| Average time to first human response | %s |\n", humanDuration(averageFirstReponseHumanTime(prs).Round(time.Minute)))
+	fmt.Fprintf(&body, "| SLA breaches | %d |\n", len(breaches))
+
+	if len(breaches) > 0 {
+		body.WriteString("\n### SLA breaches\n\n")
+		for _, breach := range breaches {
+			fmt.Fprintf(&body, "- %s %s missed the %s target of %s, took %s\n", prReference(breach.Repository, breach.Number), markdownEscape(breach.Title), breach.Metric, humanDuration(breach.Target), humanDuration(breach.Actual.Round(time.Minute)))
+		}
+	}
+
+	body.WriteString("\n### Merged PRs\n\n")
+	body.WriteString("| PR | Creator | Merge time | First human response |\n|---|---|---|---|\n")
+	for _, pr := range prs {
+		response := "-"
+		if pr.FirstHumanResponder != "" {
+			response = fmt.Sprintf("%s by @%s", humanDuration(pr.TimeToFirstHumanResponse.Round(time.Minute)), pr.FirstHumanResponder)
+		}
+		fmt.Fprintf(&body, "| %s %s | @%s | %s | %s |\n", prReference(pr.Repository, pr.Number), markdownEscape(pr.Title), pr.Creator, humanDuration(pr.Duration.Round(time.Minute)), response)
+	}
+	r.body = body.String()
+	return r, true
+}
+
+// markdownEscape keeps the text from breaking the tables and lists it is
+// written in.
+func markdownEscape(text string) string {
+	return strings.NewReplacer("|", `\|`, "\n", " ", "<", "&lt;").Replace(text)
+}
+
+// repoName is a repository flag such as --issue-report, given as owner/repo.
+type repoName struct {
+	owner string
+	repo  string
+}
+
+func (n *repoName) String() string {
+	if n.repo == "" {
+		return ""
+	}
+	return n.owner + "/" + n.repo
+}
+
+func (n *repoName) Set(value string) error {
+	owner, repo, ok := strings.Cut(value, "/")
+	if !ok || owner == "" || repo == "" || strings.Contains(repo, "/") {
+		return fmt.Errorf("invalid repository %q, expected owner/repo", value)
+	}
+	*n = repoName{owner: owner, repo: repo}
+	return nil
+}
+
+// publishIssueReport opens the issue of the report in the repository, or
+// updates it when an earlier run of the same period opened it already.
+func publishIssueReport(ctx context.Context, client GitHubClient, owner string, repo string, r issueReport) (*github.Issue, error) {
+	issues, err := listAll(func(listOpts github.ListOptions) ([]*github.Issue, *github.Response, error) {
+		return client.ListIssues(ctx, owner, repo, &github.IssueListByRepoOptions{State: "all", Labels: []string{reportIssueLabel}, ListOptions: listOpts})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching the issues of %s/%s: %w", owner, repo, err)
+	}
+	for _, issue := range issues {
+		if strings.HasPrefix(issue.GetBody(), r.marker) {
+			edited, _, err := client.EditIssue(ctx, owner, repo, issue.GetNumber(), &github.IssueRequest{Title: &r.title, Body: &r.body})
+			if err != nil {
+				return nil, fmt.Errorf("updating issue #%d of %s/%s: %w", issue.GetNumber(), owner, repo, err)
+			}
+			return edited, nil
+		}
+	}
+	created, _, err := client.CreateIssue(ctx, owner, repo, &github.IssueRequest{Title: &r.title, Body: &r.body, Labels: &[]string{reportIssueLabel}})
+	if err != nil {
+		return nil, fmt.Errorf("opening an issue in %s/%s: %w", owner, repo, err)
+	}
+	return created, nil
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// closingKeywordPattern matches the references GitHub uses to close issues
+// when a PR is merged, e.g. "Fixes #123".
+var closingKeywordPattern = regexp.MustCompile(`(?i)\b(?:close[sd]?|fix(?:e[sd])?|resolve[sd]?)\s*:?\s+#(\d+)\b`)
+
+// linkedIssues returns the numbers of the issues the PR description closes.
+func linkedIssues(body string) []int {
+	var numbers []int
+	seen := make(map[int]bool)
+	for _, match := range closingKeywordPattern.FindAllStringSubmatch(body, -1) {
+		number, err := strconv.Atoi(match[1])
+		if err != nil || seen[number] {
+			continue
+		}
+		seen[number] = true
+		numbers = append(numbers, number)
+	}
+	return numbers
+}
+
+// issueCache remembers when issues were created, as several PRs often link
+// to the same issue.
+type issueCache struct {
+	client  GitHubClient
+	owner   string
+	repo    string
+	created map[int]time.Time
+	calls   *apiCalls
+}
+
+func newIssueCache(client GitHubClient, owner string, repo string, calls *apiCalls) *issueCache {
+	return &issueCache{client: client, owner: owner, repo: repo, created: make(map[int]time.Time), calls: calls}
+}
+
+// earliestCreation returns the creation time of the oldest of the issues,
+// ignoring the issues that cannot be fetched.
+func (c *issueCache) earliestCreation(ctx context.Context, numbers []int) (earliest time.Time, ok bool) {
+	for _, number := range numbers {
+		created, cached := c.created[number]
+		if cached {
+			c.calls.cacheHit()
+		} else {
+			issue, _, err := c.client.GetIssue(ctx, c.owner, c.repo, number)
+			if err != nil {
+				slog.Warn("Fetching linked issue failed", "issue", number, "err", err)
+				continue
+			}
+			created = issue.GetCreatedAt().UTC()
+			c.created[number] = created
+		}
+		if !ok || created.Before(earliest) {
+			earliest, ok = created, true
+		}
+	}
+	return earliest, ok
+}
+
+func averageLeadTime(prData []PRInfo) (average time.Duration, linked int) {
+	var total time.Duration
+	for _, pr := range prData {
+		if len(pr.LinkedIssues) > 0 && pr.LeadTime > 0 {
+			total += pr.LeadTime
+			linked++
+		}
+	}
+
+	if linked == 0 {
+		return 0, 0
+	}
+	return total / time.Duration(linked), linked
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// JiraConfig is the Jira server the issues referenced by the PRs are looked
+// up on. The API token is read from the JIRA_API_TOKEN environment variable.
+//
+//	"jira": {"url": "https://example.atlassian.net", "user": "me@example.com"}
+type JiraConfig struct {
+	URL  string `json:"url"`
+	User string `json:"user"`
+}
+
+// jiraKeyPattern matches Jira issue keys such as "PROJ-123".
+var jiraKeyPattern = regexp.MustCompile(`\b([A-Z][A-Z0-9_]+)-([1-9][0-9]*)\b`)
+
+// jiraKeys returns the Jira issue keys mentioned in the texts, such as the
+// title and the branch of a PR, each once.
+func jiraKeys(texts ...string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, key := range jiraKeyPattern.FindAllString(text, -1) {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// jiraIssue is what is known of a Jira issue.
+type jiraIssue struct {
+	Created time.Time
+	// Started is when the status of the issue first changed, zero when it
+	// never did.
+	Started time.Time
+}
+
+// jiraClient looks up Jira issues, remembering them as several PRs often
+// reference the same issue.
+type jiraClient struct {
+	baseURL string
+	user    string
+	token   string
+	client  *http.Client
+	issues  map[string]jiraIssue
+}
+
+func newJiraClient(config JiraConfig) *jiraClient {
+	return &jiraClient{
+		baseURL: strings.TrimSuffix(config.URL, "/"),
+		user:    config.User,
+		token:   os.Getenv("JIRA_API_TOKEN"),
+		client:  &http.Client{Timeout: 30 * time.Second},
+		issues:  make(map[string]jiraIssue),
+	}
+}
+
+// issue returns the creation and start time of the issue with the key.
+func (c *jiraClient) issue(ctx context.Context, key string) (jiraIssue, error) {
+	if issue, ok := c.issues[key]; ok {
+		return issue, nil
+	}
+
+	u := fmt.Sprintf("%s/rest/api/2/issue/%s?fields=created&expand=changelog", c.baseURL, url.PathEscape(key))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return jiraIssue{}, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if c.token != "" {
+		req.SetBasicAuth(c.user, c.token)
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return jiraIssue{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return jiraIssue{}, fmt.Errorf("jira returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var data struct {
+		Fields struct {
+			Created string `json:"created"`
+		} `json:"fields"`
+		Changelog struct {
+			Histories []struct {
+				Created string `json:"created"`
+				Items   []struct {
+					Field string `json:"field"`
+				} `json:"items"`
+			} `json:"histories"`
+		} `json:"changelog"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return jiraIssue{}, fmt.Errorf("decoding jira issue %s: %w", key, err)
+	}
+
+	var issue jiraIssue
+	if issue.Created, err = parseJiraTime(data.Fields.Created); err != nil {
+		return jiraIssue{}, err
+	}
+	for _, history := range data.Changelog.Histories {
+		for _, item := range history.Items {
+			if item.Field != "status" {
+				continue
+			}
+			changed, err := parseJiraTime(history.Created)
+			if err != nil {
+				return jiraIssue{}, err
+			}
+			if issue.Started.IsZero() || changed.Before(issue.Started) {
+				issue.Started = changed
+			}
+		}
+	}
+	c.issues[key] = issue
+	return issue, nil
+}
+
+// parseJiraTime parses the timestamps of the Jira API, such as
+// "2024-01-02T15:04:05.000+0100".
+func parseJiraTime(s string) (time.Time, error) {
+	t, err := time.Parse("2006-01-02T15:04:05.000-0700", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid jira time %q: %w", s, err)
+	}
+	return t.UTC(), nil
+}
+
+// earliest returns the earliest creation and start time of the issues,
+// ignoring the issues that cannot be fetched.
+func (c *jiraClient) earliest(ctx context.Context, keys []string) (created time.Time, started time.Time, ok bool) {
+	for _, key := range keys {
+		issue, err := c.issue(ctx, key)
+		if err != nil {
+			slog.Warn("Fetching Jira issue failed", "issue", key, "err", err)
+			continue
+		}
+		if !ok || issue.Created.Before(created) {
+			created = issue.Created
+		}
+		if !issue.Started.IsZero() && (started.IsZero() || issue.Started.Before(started)) {
+			started = issue.Started
+		}
+		ok = true
+	}
+	return created, started, ok
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// KarmaWeights are the points each kind of contribution to the reviews is
+// worth in the review karma, e.g.
+//
+//	"karma": {
+//	  "review": 3,
+//	  "approval": 2,
+//	  "comment": 1,
+//	  "fastResponse": 2,
+//	  "fastResponseWithin": "4h"
+//	}
+type KarmaWeights struct {
+	// Review is the worth of every review submitted.
+	Review float64 `json:"review"`
+	// Approval is the worth of every approving review, on top of Review.
+	Approval float64 `json:"approval"`
+	// Comment is the worth of every comment.
+	Comment float64 `json:"comment"`
+	// FastResponse is the bonus for being the first human to respond to a
+	// PR within FastResponseWithin.
+	FastResponse       float64        `json:"fastResponse"`
+	FastResponseWithin configDuration `json:"fastResponseWithin"`
+}
+
+var defaultKarmaWeights = KarmaWeights{
+	Review:             3,
+	Approval:           2,
+	Comment:            1,
+	FastResponse:       2,
+	FastResponseWithin: configDuration(4 * time.Hour),
+}
+
+// Karma is the review karma of one developer and what it is made of.
+type Karma struct {
+	// Rank is the 1-based place of the developer, shared by equal scores.
+	Rank          int
+	Name          string
+	Score         float64
+	Reviews       int
+	Approvals     int
+	Comments      int
+	FastResponses int
+}
+
+// reviewKarma scores the contributions of every developer to the reviews of
+// the PRs with the weights and ranks them by score, ties by name. Nobody gets
+// points for their own PRs. At most size developers are returned, all when
+// size is zero or negative.
+func reviewKarma(prData []PRInfo, weights KarmaWeights, size int) []Karma {
+	karma := make(map[string]*Karma)
+	of := func(name string) *Karma {
+		if karma[name] == nil {
+			karma[name] = &Karma{Name: name}
+		}
+		return karma[name]
+	}
+
+	for _, pr := range prData {
+		for _, reviewer := range withoutCreator(pr, pr.Reviewers) {
+			of(reviewer).Reviews++
+		}
+		for _, approver := range withoutCreator(pr, pr.Approvers) {
+			of(approver).Approvals++
+		}
+		for _, commenter := range withoutCreator(pr, pr.Commenters) {
+			of(commenter).Comments++
+		}
+		if pr.FirstHumanResponder != "" && pr.FirstHumanResponder != pr.Creator && pr.TimeToFirstHumanResponse <= time.Duration(weights.FastResponseWithin) {
+			of(pr.FirstHumanResponder).FastResponses++
+		}
+	}
+
+	ranking := make([]Karma, 0, len(karma))
+	for _, k := range karma {
+		k.Score = weights.Review*float64(k.Reviews) + weights.Approval*float64(k.Approvals) +
+			weights.Comment*float64(k.Comments) + weights.FastResponse*float64(k.FastResponses)
+		ranking = append(ranking, *k)
+	}
+	sort.Slice(ranking, func(i, j int) bool {
+		if ranking[i].Score != ranking[j].Score {
+			return ranking[i].Score > ranking[j].Score
+		}
+		return ranking[i].Name < ranking[j].Name
+	})
+	for i := range ranking {
+		ranking[i].Rank = i + 1
+		if i > 0 && ranking[i].Score == ranking[i-1].Score {
+			ranking[i].Rank = ranking[i-1].Rank
+		}
+	}
+
+	if size > 0 && len(ranking) > size {
+		ranking = ranking[:size]
+	}
+	return ranking
+}
+
+func printReviewKarma(w io.Writer, karma []Karma) {
+	fmt.Fprintln(w, "Review karma:")
+	for _, k := range karma {
+		fmt.Fprintf(w, "  %d. %s: %g points (%d reviews, %d approvals, %d comments, %d fast first responses)\n", k.Rank, k.Name, k.Score, k.Reviews, k.Approvals, k.Comments, k.FastResponses)
+	}
+}
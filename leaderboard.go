@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// LeaderboardEntry is the place of one person on a leaderboard.
+type LeaderboardEntry struct {
+	// Rank is the 1-based place of the entry, people with the same count
+	// share the same rank.
+	Rank  int
+	Name  string
+	Count int
+	// Share is the part of the total count of the leaderboard.
+	Share float64
+}
+
+// leaderboard ranks the people returned by names for each PR by how often
+// they appear, most first. Ties are ordered by name so the result does not
+// depend on map iteration. At most size entries are returned, all when size
+// is zero or negative, even when more people tie with the last one.
+func leaderboard(prData []PRInfo, names func(PRInfo) []string, size int) []LeaderboardEntry {
+	counts := make(map[string]int)
+	total := 0
+	for _, pr := range prData {
+		for _, name := range names(pr) {
+			if name == "" {
+				continue
+			}
+			counts[name]++
+			total++
+		}
+	}
+	if total == 0 {
+		return nil
+	}
+
+	entries := make([]LeaderboardEntry, 0, len(counts))
+	for name, count := range counts {
+		entries = append(entries, LeaderboardEntry{Name: name, Count: count, Share: float64(count) / float64(total)})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	for i := range entries {
+		entries[i].Rank = i + 1
+		if i > 0 && entries[i].Count == entries[i-1].Count {
+			entries[i].Rank = entries[i-1].Rank
+		}
+	}
+
+	if size > 0 && len(entries) > size {
+		entries = entries[:size]
+	}
+	return entries
+}
+
+// leader returns the name of the first entry of the leaderboard, empty when
+// nobody is on it.
+func leader(entries []LeaderboardEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	return entries[0].Name
+}
+
+func printLeaderboard(w io.Writer, title string, entries []LeaderboardEntry) {
+	fmt.Fprintf(w, "%s:", title)
+	if len(entries) == 0 {
+		fmt.Fprintln(w, " none")
+		return
+	}
+	fmt.Fprintln(w)
+	for _, entry := range entries {
+		fmt.Fprintf(w, "  %d. %s: %d (%.1f%%)\n", entry.Rank, entry.Name, entry.Count, entry.Share*100)
+	}
+}
+
+// The people of a PR the leaderboards of a report are built from. Reviewers
+// and commenters count once per PR they took part in.
+func prReviewers(pr PRInfo) []string           { return uniqueReviewers(pr) }
+func prCommenters(pr PRInfo) []string          { return uniqueCommenters(pr) }
+func prCreator(pr PRInfo) []string             { return []string{pr.Creator} }
+func prFirstHumanResponder(pr PRInfo) []string { return []string{pr.FirstHumanResponder} }
+func prFirstResponder(pr PRInfo) []string      { return []string{pr.FirstResponder} }
+func prMerger(pr PRInfo) []string              { return []string{pr.Merger} }
+func prApprovers(pr PRInfo) []string           { return pr.Approvers }
+func prChangesRequesters(pr PRInfo) []string   { return pr.ChangesRequesters }
+
+// leaderboardRoles are the leaderboards that can be broken down by quarter,
+// by the name used on the command line.
+var leaderboardRoles = map[string]struct {
+	title string
+	names func(PRInfo) []string
+}{
+	"reviewers":              {"Top reviewers", prReviewers},
+	"commenters":             {"Top commenters", prCommenters},
+	"creators":               {"Top creators", prCreator},
+	"first-human-responders": {"Top first human responders", prFirstHumanResponder},
+	"first-responders":       {"Top first responders", prFirstResponder},
+	"mergers":                {"Top mergers", prMerger},
+	"approvers":              {"Top approvers", prApprovers},
+	"changes-requesters":     {"Most changes requested", prChangesRequesters},
+}
+
+// roleList is a flag naming leaderboard roles, given several times or comma
+// separated.
+type roleList []string
+
+func (l *roleList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *roleList) Set(value string) error {
+	for _, role := range strings.Split(value, ",") {
+		if _, ok := leaderboardRoles[role]; !ok {
+			names := make([]string, 0, len(leaderboardRoles))
+			for name := range leaderboardRoles {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return fmt.Errorf("unknown leaderboard %q, expected one of %s", role, strings.Join(names, ", "))
+		}
+		*l = append(*l, role)
+	}
+	return nil
+}
+
+// QuarterLeaderboard is the leaderboard of the PRs merged in one quarter.
+type QuarterLeaderboard struct {
+	Year    int
+	Quarter string
+	Entries []LeaderboardEntry
+}
+
+// RoleLeaderboards are the quarterly leaderboards of one --quarterly role.
+type RoleLeaderboards struct {
+	Role     string
+	Title    string
+	Quarters []QuarterLeaderboard
+}
+
+// quarterlyLeaderboards builds one leaderboard per quarter of the PRs, oldest
+// quarter first.
+func quarterlyLeaderboards(prData []PRInfo, names func(PRInfo) []string, size int) []QuarterLeaderboard {
+	byQuarter := make(map[int][]PRInfo)
+	for _, pr := range prData {
+		q := quarterIndex(pr.Year, pr.Quarter)
+		byQuarter[q] = append(byQuarter[q], pr)
+	}
+
+	quarters := make([]int, 0, len(byQuarter))
+	for q := range byQuarter {
+		quarters = append(quarters, q)
+	}
+	sort.Ints(quarters)
+
+	boards := make([]QuarterLeaderboard, 0, len(quarters))
+	for _, q := range quarters {
+		prs := byQuarter[q]
+		boards = append(boards, QuarterLeaderboard{
+			Year:    prs[0].Year,
+			Quarter: prs[0].Quarter,
+			Entries: leaderboard(prs, names, size),
+		})
+	}
+	return boards
+}
+
+func printQuarterlyLeaderboards(w io.Writer, title string, boards []QuarterLeaderboard) {
+	fmt.Fprintf(w, "%s per quarter:\n", title)
+	if len(boards) == 0 {
+		fmt.Fprintln(w, "  No PRs")
+		return
+	}
+	for _, board := range boards {
+		fmt.Fprintf(w, "  %d-%s:", board.Year, board.Quarter)
+		if len(board.Entries) == 0 {
+			fmt.Fprintln(w, " none")
+			continue
+		}
+		fmt.Fprintln(w)
+		for _, entry := range board.Entries {
+			fmt.Fprintf(w, "    %d. %s: %d (%.1f%%)\n", entry.Rank, entry.Name, entry.Count, entry.Share*100)
+		}
+	}
+}
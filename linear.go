@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// linearAPI is the GraphQL endpoint of Linear.
+const linearAPI = "https://api.linear.app/graphql"
+
+// linearKeyPattern matches Linear issue identifiers such as "ENG-123",
+// also in the lower case Linear uses for branch names ("eng-123-fix-login").
+var linearKeyPattern = regexp.MustCompile(`(?i)\b([a-z][a-z0-9]*)-([1-9][0-9]*)\b`)
+
+// linearKeys returns the Linear issue identifiers mentioned in the texts, such
+// as the title and the branch of a PR, in upper case and each once.
+func linearKeys(texts ...string) []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, text := range texts {
+		for _, key := range linearKeyPattern.FindAllString(text, -1) {
+			key = strings.ToUpper(key)
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// linearIssue is what is known of a Linear issue.
+type linearIssue struct {
+	Created time.Time
+	// Started is when the issue moved to a started state, zero when it
+	// never did.
+	Started time.Time
+}
+
+// linearClient looks up Linear issues with the API key from the
+// LINEAR_API_KEY environment variable, remembering them as several PRs often
+// reference the same issue. Identifiers Linear does not know, such as
+// "UTF-8" in a title, are remembered as missing.
+type linearClient struct {
+	url    string
+	key    string
+	client *http.Client
+	issues map[string]*linearIssue
+}
+
+func newLinearClient(apiKey string) *linearClient {
+	return &linearClient{url: linearAPI, key: apiKey, client: &http.Client{Timeout: 30 * time.Second}, issues: make(map[string]*linearIssue)}
+}
+
+// linearAPIKey returns the API key Linear is queried with, empty when the
+// Linear integration is not used.
+func linearAPIKey() string {
+	return os.Getenv("LINEAR_API_KEY")
+}
+
+const linearIssueQuery = `query($id: String!) { issue(id: $id) { createdAt startedAt } }`
+
+// issue returns the issue with the identifier, nil when Linear does not
+// know it.
+func (c *linearClient) issue(ctx context.Context, id string) (*linearIssue, error) {
+	if issue, ok := c.issues[id]; ok {
+		return issue, nil
+	}
+
+	body, err := json.Marshal(map[string]interface{}{"query": linearIssueQuery, "variables": map[string]string{"id": id}})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", c.key)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("linear returned %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+
+	var data struct {
+		Data struct {
+			Issue *struct {
+				CreatedAt time.Time  `json:"createdAt"`
+				StartedAt *time.Time `json:"startedAt"`
+			} `json:"issue"`
+		} `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&data); err != nil {
+		return nil, fmt.Errorf("decoding linear issue %s: %w", id, err)
+	}
+
+	var issue *linearIssue
+	if found := data.Data.Issue; found != nil {
+		issue = &linearIssue{Created: found.CreatedAt.UTC()}
+		if found.StartedAt != nil {
+			issue.Started = found.StartedAt.UTC()
+		}
+	} else if len(data.Errors) > 0 {
+		slog.Debug("Linear issue not found", "issue", id, "err", data.Errors[0].Message)
+	}
+	c.issues[id] = issue
+	return issue, nil
+}
+
+// earliest returns the earliest creation and start time of the issues Linear
+// knows, ignoring the issues that cannot be fetched.
+func (c *linearClient) earliest(ctx context.Context, ids []string) (created time.Time, started time.Time, found []string) {
+	for _, id := range ids {
+		issue, err := c.issue(ctx, id)
+		if err != nil {
+			slog.Warn("Fetching Linear issue failed", "issue", id, "err", err)
+			continue
+		}
+		if issue == nil {
+			continue
+		}
+		if len(found) == 0 || issue.Created.Before(created) {
+			created = issue.Created
+		}
+		if !issue.Started.IsZero() && (started.IsZero() || issue.Started.Before(started)) {
+			started = issue.Started
+		}
+		found = append(found, id)
+	}
+	return created, started, found
+}
+
+func linearCycle(pr PRInfo) ([]string, time.Duration, time.Duration) {
+	return issueProjects(pr.LinearIssues), pr.LinearCycleTime, pr.LinearStartToMerge
+}
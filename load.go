@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// ReviewerShare is the part of all reviews one reviewer handled.
+type ReviewerShare struct {
+	Name    string
+	Reviews int
+	Share   float64
+}
+
+// reviewerLoad returns the share of the reviews of each reviewer, biggest
+// first, and the Gini coefficient of their distribution: 0 when everybody
+// reviews the same amount, close to 1 when one person does all the reviews.
+// Only people who reviewed at least once are taken into account.
+func reviewerLoad(prData []PRInfo) ([]ReviewerShare, float64) {
+	counts := make(map[string]int)
+	total := 0
+	for _, pr := range prData {
+		for _, reviewer := range pr.Reviewers {
+			counts[reviewer]++
+			total++
+		}
+	}
+	if total == 0 {
+		return nil, 0
+	}
+
+	shares := make([]ReviewerShare, 0, len(counts))
+	for name, count := range counts {
+		shares = append(shares, ReviewerShare{Name: name, Reviews: count, Share: float64(count) / float64(total)})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Reviews != shares[j].Reviews {
+			return shares[i].Reviews > shares[j].Reviews
+		}
+		return shares[i].Name < shares[j].Name
+	})
+
+	values := make([]float64, len(shares))
+	for i, share := range shares {
+		values[i] = float64(share.Reviews)
+	}
+	return shares, gini(values)
+}
+
+// gini returns the Gini coefficient of the non-negative values.
+func gini(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+
+	var weighted, sum float64
+	for i, v := range sorted {
+		weighted += float64(i+1) * v
+		sum += v
+	}
+	if sum == 0 {
+		return 0
+	}
+	n := float64(len(sorted))
+	return 2*weighted/(n*sum) - (n+1)/n
+}
+
+func printReviewerLoad(w io.Writer, shares []ReviewerShare, concentration float64) {
+	fmt.Fprintf(w, "Reviewer load (Gini concentration index %.2f):\n", concentration)
+	for _, share := range shares {
+		fmt.Fprintf(w, "  %s: %d reviews (%.1f%%)\n", share.Name, share.Reviews, share.Share*100)
+	}
+}
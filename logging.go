@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+)
+
+// newLogger creates the logger for the given level (debug, info, warn or
+// error) and format (text or json). Logs are written to stderr so they never
+// mix with the report.
+func newLogger(level string, format string) (*slog.Logger, error) {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q", level)
+	}
+
+	handlerOptions := &slog.HandlerOptions{Level: l}
+	switch format {
+	case "text":
+		return slog.New(failingHandler{slog.NewTextHandler(os.Stderr, handlerOptions)}), nil
+	case "json":
+		return slog.New(failingHandler{slog.NewJSONHandler(os.Stderr, handlerOptions)}), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q", format)
+	}
+}
+
+// failingHandler makes the program exit with status 1 once it logged an
+// error. The errors are logged where they happen rather than returned, so
+// this is what tells the scripts and CI jobs that a run failed.
+type failingHandler struct {
+	slog.Handler
+}
+
+func (h failingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if r.Level >= slog.LevelError {
+		exitCode.CompareAndSwap(0, 1)
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h failingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return failingHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h failingHandler) WithGroup(name string) slog.Handler {
+	return failingHandler{h.Handler.WithGroup(name)}
+}
+
+// loggingTransport logs every API request together with the rate limit
+// information GitHub returns at debug level.
+type loggingTransport struct {
+	base   http.RoundTripper
+	logger *slog.Logger
+}
+
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		t.logger.Debug("API request failed", "method", req.Method, "url", req.URL.String(), "duration", time.Since(start), "err", err)
+		return nil, err
+	}
+
+	t.logger.Debug("API request",
+		"method", req.Method,
+		"url", req.URL.String(),
+		"status", resp.StatusCode,
+		"duration", time.Since(start),
+		"rate_limit_remaining", resp.Header.Get("X-RateLimit-Remaining"),
+		"rate_limit_reset", resp.Header.Get("X-RateLimit-Reset"),
+	)
+	return resp, nil
+}
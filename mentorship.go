@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// reviewResponseTimes returns, per human reviewer, the time from start until
+// their first review of the PR was submitted.
+func reviewResponseTimes(start time.Time, reviews []*github.PullRequestReview, opts collectOptions) map[string]time.Duration {
+	first := make(map[string]time.Time)
+	for _, review := range reviews {
+		login := review.GetUser().GetLogin()
+		if strings.HasSuffix(login, "[bot]") || review.SubmittedAt == nil {
+			continue
+		}
+		if t, ok := first[login]; !ok || review.SubmittedAt.Before(t) {
+			first[login] = *review.SubmittedAt
+		}
+	}
+	if len(first) == 0 {
+		return nil
+	}
+
+	times := make(map[string]time.Duration, len(first))
+	for login, t := range first {
+		times[login] = opts.responseTime(login, start, t)
+	}
+	return times
+}
+
+// Mentor is a reviewer of the PRs of a mentee.
+type Mentor struct {
+	Name string
+	// PRs is the number of PRs of the mentee the mentor reviewed.
+	PRs int
+	// AverageResponseTime is the average time until the first review of the
+	// mentor on a PR of the mentee.
+	AverageResponseTime time.Duration
+}
+
+// Mentorship lists who reviews the PRs of one mentee, most PRs first.
+type Mentorship struct {
+	Mentee  string
+	PRs     int
+	Mentors []Mentor
+}
+
+// mentorships returns who reviewed the PRs of each mentee and how quickly, in
+// the order the mentees are given. Reviews of a mentee's own PRs by the mentee
+// are left out.
+func mentorships(prData []PRInfo, mentees []string) []Mentorship {
+	result := make([]Mentorship, 0, len(mentees))
+	for _, mentee := range mentees {
+		mentorship := Mentorship{Mentee: mentee}
+		counts := make(map[string]int)
+		totals := make(map[string]time.Duration)
+		for _, pr := range prData {
+			if pr.Creator != mentee {
+				continue
+			}
+			mentorship.PRs++
+			for reviewer, responseTime := range pr.ReviewResponseTimes {
+				if reviewer == mentee {
+					continue
+				}
+				counts[reviewer]++
+				totals[reviewer] += responseTime
+			}
+		}
+
+		for name, count := range counts {
+			mentorship.Mentors = append(mentorship.Mentors, Mentor{
+				Name:                name,
+				PRs:                 count,
+				AverageResponseTime: totals[name] / time.Duration(count),
+			})
+		}
+		sort.Slice(mentorship.Mentors, func(i, j int) bool {
+			a, b := mentorship.Mentors[i], mentorship.Mentors[j]
+			if a.PRs != b.PRs {
+				return a.PRs > b.PRs
+			}
+			if a.AverageResponseTime != b.AverageResponseTime {
+				return a.AverageResponseTime < b.AverageResponseTime
+			}
+			return a.Name < b.Name
+		})
+		result = append(result, mentorship)
+	}
+	return result
+}
+
+func printMentorships(w io.Writer, mentorships []Mentorship) {
+	if len(mentorships) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Mentorship (who reviews the PRs of each mentee):")
+	for _, mentorship := range mentorships {
+		fmt.Fprintf(w, "  %s (%d PRs):", mentorship.Mentee, mentorship.PRs)
+		if len(mentorship.Mentors) == 0 {
+			fmt.Fprintln(w, " no reviews")
+			continue
+		}
+		fmt.Fprintln(w)
+		for _, mentor := range mentorship.Mentors {
+			fmt.Fprintf(w, "    %s: %d PRs, first review after %s on average\n", mentor.Name, mentor.PRs, formatDuration(mentor.AverageResponseTime))
+		}
+	}
+}
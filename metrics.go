@@ -0,0 +1,273 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// metricField is a value of the PRs the --metric expressions aggregate.
+type metricField struct {
+	duration bool
+	// value returns the value of the PR, false when the PR has none, such as
+	// the time to the first human response of the PRs nobody responded to.
+	value func(pr PRInfo) (float64, bool)
+	// span returns when the duration starts and ends, false when the PR has
+	// none. It is only set for the durations that can be counted on weekdays
+	// or weekends only.
+	span func(pr PRInfo) (start time.Time, end time.Time, ok bool)
+}
+
+func durationField(value func(pr PRInfo) (time.Duration, bool)) metricField {
+	return metricField{duration: true, value: func(pr PRInfo) (float64, bool) {
+		d, ok := value(pr)
+		return float64(d), ok
+	}}
+}
+
+// spanField is a duration between two times of the PRs, end being zero when
+// the PR has none.
+func spanField(value func(pr PRInfo) (time.Duration, bool), start func(pr PRInfo) time.Time, end func(pr PRInfo) time.Time) metricField {
+	field := durationField(value)
+	field.span = func(pr PRInfo) (time.Time, time.Time, bool) {
+		if _, ok := value(pr); !ok || end(pr).IsZero() {
+			return time.Time{}, time.Time{}, false
+		}
+		return start(pr), end(pr), true
+	}
+	return field
+}
+
+// readyForReview returns when the PR was ready for review, its creation for
+// the PRs stored before it was collected.
+func readyForReview(pr PRInfo) time.Time {
+	if pr.ReadyForReviewAt.IsZero() {
+		return pr.CreatedAt
+	}
+	return pr.ReadyForReviewAt
+}
+
+func countField(value func(pr PRInfo) int) metricField {
+	return metricField{value: func(pr PRInfo) (float64, bool) { return float64(value(pr)), true }}
+}
+
+var metricFields = map[string]metricField{
+	"duration": spanField(func(pr PRInfo) (time.Duration, bool) { return pr.Duration, true },
+		func(pr PRInfo) time.Time { return pr.CreatedAt }, func(pr PRInfo) time.Time { return pr.MergedAt }),
+	"time_to_first_response": spanField(func(pr PRInfo) (time.Duration, bool) {
+		return pr.TimeToFirstResponse, pr.FirstResponder != ""
+	}, readyForReview, func(pr PRInfo) time.Time { return pr.FirstResponseAt }),
+	"time_to_first_human_response": spanField(func(pr PRInfo) (time.Duration, bool) {
+		return pr.TimeToFirstHumanResponse, pr.FirstHumanResponder != ""
+	}, readyForReview, func(pr PRInfo) time.Time { return pr.FirstHumanResponseAt }),
+	"time_in_draft": durationField(func(pr PRInfo) (time.Duration, bool) { return pr.TimeInDraft, true }),
+	"ci_time":       durationField(func(pr PRInfo) (time.Duration, bool) { return pr.CITime, pr.known(unknownCheckRuns) }),
+	"green_to_merge": spanField(func(pr PRInfo) (time.Duration, bool) { return pr.GreenToMerge, !pr.CIGreenAt.IsZero() },
+		func(pr PRInfo) time.Time { return pr.CIGreenAt }, func(pr PRInfo) time.Time { return pr.MergedAt }),
+	"longest_idle_gap": durationField(func(pr PRInfo) (time.Duration, bool) {
+		return pr.LongestIdleGap, pr.known(unknownTimeline, unknownReviews, unknownComments, unknownReviewComments)
+	}),
+	"waiting_on_author": durationField(func(pr PRInfo) (time.Duration, bool) {
+		return pr.WaitingOnAuthor, pr.known(unknownTimeline, unknownReviews)
+	}),
+	"waiting_on_reviewers": durationField(func(pr PRInfo) (time.Duration, bool) {
+		return pr.WaitingOnReviewers, pr.known(unknownTimeline, unknownReviews)
+	}),
+	"lead_time":                 durationField(func(pr PRInfo) (time.Duration, bool) { return pr.LeadTime, len(pr.LinkedIssues) > 0 }),
+	"commits":                   countField(func(pr PRInfo) int { return pr.Commits }),
+	"comments":                  countField(commentCount),
+	"commenters":                countField(func(pr PRInfo) int { return len(uniqueCommenters(pr)) }),
+	"reviews":                   countField(reviewCount),
+	"reviewers":                 countField(func(pr PRInfo) int { return len(uniqueReviewers(pr)) }),
+	"review_comments":           countField(func(pr PRInfo) int { return pr.ReviewComments }),
+	"changes_requested":         countField(func(pr PRInfo) int { return pr.ChangesRequestedReviews }),
+	"pushes_after_first_review": countField(func(pr PRInfo) int { return pr.PushesAfterFirstReview }),
+	"review_rounds":             countField(func(pr PRInfo) int { return pr.ReviewRounds }),
+	"reopened":                  countField(func(pr PRInfo) int { return pr.Reopened }),
+	"files":                     countField(func(pr PRInfo) int { return len(pr.Files) }),
+	"additions":                 countField(func(pr PRInfo) int { return pr.Additions }),
+	"deletions":                 countField(func(pr PRInfo) int { return pr.Deletions }),
+	"lines":                     countField(changedLines),
+}
+
+// metricFilters are the flags following the field of an expression, each
+// keeping the PRs it matches. The created_on filters select the PRs by the day
+// they were created on (UTC), metricDays counts the durations on some days.
+var metricFilters = map[string]func(pr PRInfo) bool{
+	"created_on_weekdays": func(pr PRInfo) bool { return !isWeekend(pr.CreatedAt) },
+	"created_on_weekends": func(pr PRInfo) bool { return isWeekend(pr.CreatedAt) },
+	"humans_only":         func(pr PRInfo) bool { return !strings.HasSuffix(pr.Creator, "[bot]") },
+	"bots_only":           func(pr PRInfo) bool { return strings.HasSuffix(pr.Creator, "[bot]") },
+}
+
+// metricDays are the flags following the field of an expression that only
+// count the part of the duration on weekdays or on weekends (UTC), next to
+// metricFilters. They apply to the durations between two times of the PRs,
+// which they count on the wall clock without the working hours.
+var metricDays = map[string]func(start time.Time, end time.Time) time.Duration{
+	"weekdays_only": weekdayDuration,
+	"weekends_only": func(start time.Time, end time.Time) time.Duration {
+		return max(end.Sub(start)-weekdayDuration(start, end), 0)
+	},
+}
+
+func isWeekend(t time.Time) bool {
+	day := t.UTC().Weekday()
+	return day == time.Saturday || day == time.Sunday
+}
+
+// metricExpr is an expression such as "p90(duration)" or
+// "avg(time_to_first_human_response, weekdays_only)": an aggregate of a
+// field over the PRs matching all the filters, counting only the part of
+// the durations on the days of a weekdays_only or weekends_only. The aggregates are count,
+// sum, avg, median, min, max, stddev and the percentiles p1 to p99.
+type metricExpr struct {
+	expr      string
+	aggregate string
+	quantile  float64
+	field     string
+	filters   []func(pr PRInfo) bool
+	// days counts the part of the duration on weekdays or weekends, nil to
+	// count the value of the field.
+	days func(start time.Time, end time.Time) time.Duration
+}
+
+func parseMetricExpr(expr string) (metricExpr, error) {
+	name, rest, ok := strings.Cut(strings.TrimSpace(expr), "(")
+	args, found := strings.CutSuffix(strings.TrimSpace(rest), ")")
+	if !ok || !found {
+		return metricExpr{}, fmt.Errorf("invalid metric %q, expected an aggregate of a field such as p90(duration)", expr)
+	}
+
+	m := metricExpr{expr: strings.TrimSpace(expr), aggregate: strings.TrimSpace(name)}
+	switch m.aggregate {
+	case "count", "sum", "avg", "median", "min", "max", "stddev":
+	default:
+		percentile, err := strconv.ParseFloat(strings.TrimPrefix(m.aggregate, "p"), 64)
+		if !strings.HasPrefix(m.aggregate, "p") || err != nil || percentile <= 0 || percentile >= 100 {
+			return metricExpr{}, fmt.Errorf("unknown aggregate %q in %q, expected count, sum, avg, median, min, max, stddev or a percentile such as p90", m.aggregate, expr)
+		}
+		m.quantile = percentile / 100
+	}
+
+	parts := strings.Split(args, ",")
+	m.field = strings.TrimSpace(parts[0])
+	if _, ok := metricFields[m.field]; !ok {
+		return metricExpr{}, fmt.Errorf("unknown field %q in %q, expected one of %s", m.field, expr, strings.Join(sortedKeys(metricFields), ", "))
+	}
+	for _, part := range parts[1:] {
+		name := strings.TrimSpace(part)
+		if days, ok := metricDays[name]; ok {
+			if metricFields[m.field].span == nil {
+				return metricExpr{}, fmt.Errorf("%s in %q only applies to the durations duration, time_to_first_response, time_to_first_human_response and green_to_merge", name, expr)
+			}
+			if m.days != nil {
+				return metricExpr{}, fmt.Errorf("%q can only have one of weekdays_only and weekends_only", expr)
+			}
+			m.days = days
+			continue
+		}
+		filter, ok := metricFilters[name]
+		if !ok {
+			return metricExpr{}, fmt.Errorf("unknown filter %q in %q, expected one of %s", name, expr, strings.Join(append(sortedKeys(metricDays), sortedKeys(metricFilters)...), ", "))
+		}
+		m.filters = append(m.filters, filter)
+	}
+	return m, nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// evaluate computes the metric over the PRs, written as a duration for the
+// durations other than counts. The metric is "-" when no PR has a value.
+func (m metricExpr) evaluate(prs []PRInfo) CustomMetric {
+	field := metricFields[m.field]
+	var values []float64
+	for _, pr := range prs {
+		matches := true
+		for _, filter := range m.filters {
+			matches = matches && filter(pr)
+		}
+		value, ok := field.value(pr)
+		if m.days != nil {
+			start, end, spanned := field.span(pr)
+			value, ok = float64(m.days(start, end)), spanned
+		}
+		if matches && ok {
+			values = append(values, value)
+		}
+	}
+	metric := CustomMetric{Name: m.expr, Value: "-"}
+	if m.aggregate == "count" {
+		metric.Value = strconv.Itoa(len(values))
+		return metric
+	}
+	if len(values) == 0 {
+		return metric
+	}
+
+	sort.Float64s(values)
+	var sum float64
+	for _, value := range values {
+		sum += value
+	}
+	mean := sum / float64(len(values))
+	var result float64
+	switch m.aggregate {
+	case "sum":
+		result = sum
+	case "avg":
+		result = mean
+	case "median":
+		result = quantile(values, 0.5)
+	case "min":
+		result = values[0]
+	case "max":
+		result = values[len(values)-1]
+	case "stddev":
+		var squares float64
+		for _, value := range values {
+			squares += (value - mean) * (value - mean)
+		}
+		result = math.Sqrt(squares / float64(len(values)))
+	default:
+		result = quantile(values, m.quantile)
+	}
+
+	if field.duration {
+		metric.Value = formatDuration(time.Duration(result).Round(time.Second))
+	} else {
+		metric.Value = strconv.FormatFloat(math.Round(result*100)/100, 'f', -1, 64)
+	}
+	return metric
+}
+
+// metricList is the --metric flag, which can be given several times.
+type metricList []metricExpr
+
+func (l *metricList) String() string {
+	exprs := make([]string, len(*l))
+	for i, m := range *l {
+		exprs[i] = m.expr
+	}
+	return strings.Join(exprs, ", ")
+}
+
+func (l *metricList) Set(value string) error {
+	m, err := parseMetricExpr(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, m)
+	return nil
+}
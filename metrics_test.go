@@ -0,0 +1,67 @@
+package main
+
+import "testing"
+
+func TestParseMetricExpr(t *testing.T) {
+	for _, tc := range []struct {
+		expr    string
+		wantErr bool
+	}{
+		{expr: "p90(duration)"},
+		{expr: "avg(time_to_first_human_response, weekdays_only)"},
+		{expr: "sum(duration, weekends_only, humans_only)"},
+		{expr: "count(reviews, created_on_weekdays)"},
+		{expr: "avg(reviews, weekdays_only)", wantErr: true},
+		{expr: "avg(duration, weekdays_only, weekends_only)", wantErr: true},
+		{expr: "avg(duration, mondays_only)", wantErr: true},
+		{expr: "avg(happiness)", wantErr: true},
+		{expr: "p100(duration)", wantErr: true},
+		{expr: "avg duration", wantErr: true},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			if _, err := parseMetricExpr(tc.expr); (err != nil) != tc.wantErr {
+				t.Errorf("got the error %v, want an error: %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestMetricExprEvaluate(t *testing.T) {
+	// Both PRs are ready on Friday at 18:00 UTC, the first one is responded
+	// to on Monday at 06:00 and the second one on Saturday at 12:00
+	prInfos := collect(t, newFixture(t,
+		testPR{
+			number:   1,
+			created:  "2024-01-12T18:00:00Z",
+			merged:   "2024-01-15T18:00:00Z",
+			comments: []testEvent{{login: "bob", at: "2024-01-15T06:00:00Z"}},
+		},
+		testPR{
+			number:   2,
+			created:  "2024-01-12T18:00:00Z",
+			merged:   "2024-01-15T18:00:00Z",
+			comments: []testEvent{{login: "bob", at: "2024-01-13T12:00:00Z"}},
+		},
+	), collectOptions{})
+
+	for _, tc := range []struct {
+		expr string
+		want string
+	}{
+		{expr: "avg(time_to_first_human_response)", want: "39h0m0s"},
+		{expr: "avg(time_to_first_human_response, weekdays_only)", want: "9h0m0s"},
+		{expr: "max(time_to_first_human_response, weekends_only)", want: "48h0m0s"},
+		{expr: "sum(duration, weekdays_only)", want: "48h0m0s"},
+		{expr: "count(duration, created_on_weekends)", want: "0"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			m, err := parseMetricExpr(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.evaluate(prInfos).Value; got != tc.want {
+				t.Errorf("got %s, want %s", got, tc.want)
+			}
+		})
+	}
+}
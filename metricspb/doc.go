@@ -0,0 +1,5 @@
+// Package metricspb is the gRPC service time2review serves the review
+// metrics with in server mode (--grpc-listen), from metrics.proto.
+package metricspb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative metrics.proto
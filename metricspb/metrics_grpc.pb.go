@@ -0,0 +1,224 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.25.1
+// source: metrics.proto
+
+// The review metrics time2review serves in server mode (--grpc-listen), for
+// the services consuming them with typed clients.
+
+package metricspb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	Metrics_GetRepoStats_FullMethodName  = "/time2review.v1.Metrics/GetRepoStats"
+	Metrics_ListPRInfos_FullMethodName   = "/time2review.v1.Metrics/ListPRInfos"
+	Metrics_StreamUpdates_FullMethodName = "/time2review.v1.Metrics/StreamUpdates"
+)
+
+// MetricsClient is the client API for Metrics service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type MetricsClient interface {
+	// GetRepoStats returns the metrics of the latest report of a repository.
+	GetRepoStats(ctx context.Context, in *GetRepoStatsRequest, opts ...grpc.CallOption) (*RepoStats, error)
+	// ListPRInfos lists the PRs of the latest run of a repository, by number.
+	ListPRInfos(ctx context.Context, in *ListPRInfosRequest, opts ...grpc.CallOption) (*ListPRInfosResponse, error)
+	// StreamUpdates sends the metrics of the latest report of the repositories
+	// now, and again whenever a run updates one of them, until the call is
+	// cancelled.
+	StreamUpdates(ctx context.Context, in *StreamUpdatesRequest, opts ...grpc.CallOption) (Metrics_StreamUpdatesClient, error)
+}
+
+type metricsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewMetricsClient(cc grpc.ClientConnInterface) MetricsClient {
+	return &metricsClient{cc}
+}
+
+func (c *metricsClient) GetRepoStats(ctx context.Context, in *GetRepoStatsRequest, opts ...grpc.CallOption) (*RepoStats, error) {
+	out := new(RepoStats)
+	err := c.cc.Invoke(ctx, Metrics_GetRepoStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsClient) ListPRInfos(ctx context.Context, in *ListPRInfosRequest, opts ...grpc.CallOption) (*ListPRInfosResponse, error) {
+	out := new(ListPRInfosResponse)
+	err := c.cc.Invoke(ctx, Metrics_ListPRInfos_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *metricsClient) StreamUpdates(ctx context.Context, in *StreamUpdatesRequest, opts ...grpc.CallOption) (Metrics_StreamUpdatesClient, error) {
+	stream, err := c.cc.NewStream(ctx, &Metrics_ServiceDesc.Streams[0], Metrics_StreamUpdates_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &metricsStreamUpdatesClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Metrics_StreamUpdatesClient interface {
+	Recv() (*RepoStats, error)
+	grpc.ClientStream
+}
+
+type metricsStreamUpdatesClient struct {
+	grpc.ClientStream
+}
+
+func (x *metricsStreamUpdatesClient) Recv() (*RepoStats, error) {
+	m := new(RepoStats)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// MetricsServer is the server API for Metrics service.
+// All implementations must embed UnimplementedMetricsServer
+// for forward compatibility
+type MetricsServer interface {
+	// GetRepoStats returns the metrics of the latest report of a repository.
+	GetRepoStats(context.Context, *GetRepoStatsRequest) (*RepoStats, error)
+	// ListPRInfos lists the PRs of the latest run of a repository, by number.
+	ListPRInfos(context.Context, *ListPRInfosRequest) (*ListPRInfosResponse, error)
+	// StreamUpdates sends the metrics of the latest report of the repositories
+	// now, and again whenever a run updates one of them, until the call is
+	// cancelled.
+	StreamUpdates(*StreamUpdatesRequest, Metrics_StreamUpdatesServer) error
+	mustEmbedUnimplementedMetricsServer()
+}
+
+// UnimplementedMetricsServer must be embedded to have forward compatible implementations.
+type UnimplementedMetricsServer struct {
+}
+
+func (UnimplementedMetricsServer) GetRepoStats(context.Context, *GetRepoStatsRequest) (*RepoStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetRepoStats not implemented")
+}
+func (UnimplementedMetricsServer) ListPRInfos(context.Context, *ListPRInfosRequest) (*ListPRInfosResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListPRInfos not implemented")
+}
+func (UnimplementedMetricsServer) StreamUpdates(*StreamUpdatesRequest, Metrics_StreamUpdatesServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamUpdates not implemented")
+}
+func (UnimplementedMetricsServer) mustEmbedUnimplementedMetricsServer() {}
+
+// UnsafeMetricsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to MetricsServer will
+// result in compilation errors.
+type UnsafeMetricsServer interface {
+	mustEmbedUnimplementedMetricsServer()
+}
+
+func RegisterMetricsServer(s grpc.ServiceRegistrar, srv MetricsServer) {
+	s.RegisterService(&Metrics_ServiceDesc, srv)
+}
+
+func _Metrics_GetRepoStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRepoStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServer).GetRepoStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Metrics_GetRepoStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServer).GetRepoStats(ctx, req.(*GetRepoStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Metrics_ListPRInfos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListPRInfosRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MetricsServer).ListPRInfos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Metrics_ListPRInfos_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MetricsServer).ListPRInfos(ctx, req.(*ListPRInfosRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Metrics_StreamUpdates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamUpdatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MetricsServer).StreamUpdates(m, &metricsStreamUpdatesServer{stream})
+}
+
+type Metrics_StreamUpdatesServer interface {
+	Send(*RepoStats) error
+	grpc.ServerStream
+}
+
+type metricsStreamUpdatesServer struct {
+	grpc.ServerStream
+}
+
+func (x *metricsStreamUpdatesServer) Send(m *RepoStats) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// Metrics_ServiceDesc is the grpc.ServiceDesc for Metrics service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Metrics_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "time2review.v1.Metrics",
+	HandlerType: (*MetricsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetRepoStats",
+			Handler:    _Metrics_GetRepoStats_Handler,
+		},
+		{
+			MethodName: "ListPRInfos",
+			Handler:    _Metrics_ListPRInfos_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamUpdates",
+			Handler:       _Metrics_StreamUpdates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "metrics.proto",
+}
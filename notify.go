@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// digestPRs is the number of SLA breaches and of stale PRs listed in a
+// digest, the worst first.
+const digestPRs = 10
+
+// digest is what the notifiers post to the chat rooms after every run: the
+// review metrics of the most recent week of a repository and its stale PRs.
+type digest struct {
+	Repository string
+	Week       WeeklySummary
+	Stale      []StalePR
+}
+
+// newDigest returns the digest of the most recent week the PRs were merged
+// in, false when there are no PRs.
+func newDigest(owner string, repo string, prData []PRInfo, sla SLA) (digest, bool) {
+	summaries := weeklySummaries(prData, sla)
+	if len(summaries) == 0 {
+		return digest{}, false
+	}
+	return digest{Repository: owner + "/" + repo, Week: summaries[len(summaries)-1]}, true
+}
+
+func (d digest) title() string {
+	return fmt.Sprintf("Review metrics of %s for the week of %s", d.Repository, d.Week.Week.Format(time.DateOnly))
+}
+
+// digestFact is a named value of a digest.
+type digestFact struct {
+	Name  string
+	Value string
+}
+
+func (d digest) facts() []digestFact {
+	return []digestFact{
+		{"PRs merged", fmt.Sprint(d.Week.PRs)},
+		{"Average merge time", formatDuration(d.Week.AverageMergeTime)},
+		{"Average time to first human response", formatDuration(d.Week.AverageTimeToFirstHumanResponse)},
+		{"SLA breaches", fmt.Sprint(len(d.Week.SLABreaches))},
+	}
+}
+
+// breaches describes the worst SLA breaches of the week, one line each.
+func (d digest) breaches() []string {
+	var lines []string
+	for i, breach := range d.Week.SLABreaches {
+		if i == digestPRs {
+			lines = append(lines, fmt.Sprintf("and %d more", len(d.Week.SLABreaches)-digestPRs))
+			break
+		}
+		lines = append(lines, breachLine(breach))
+	}
+	return lines
+}
+
+// stale describes the open PRs idle the longest, one line each.
+func (d digest) stale() []string {
+	var lines []string
+	for i, pr := range d.Stale {
+		if i == digestPRs {
+			lines = append(lines, fmt.Sprintf("and %d more", len(d.Stale)-digestPRs))
+			break
+		}
+		lines = append(lines, fmt.Sprintf("PR %s: %s by %s, idle for %s", prReference(pr.Repository, pr.Number), pr.Title, pr.Creator, humanDuration(pr.Idle.Round(time.Hour))))
+	}
+	return lines
+}
+
+// notifier posts digests to a chat room.
+type notifier interface {
+	notify(ctx context.Context, d digest) error
+}
+
+// newNotifier returns the notifier of the URL, teams://host/path for the
+// Microsoft Teams incoming webhook at https://host/path,
+// discord://host/path for the Discord webhook at https://host/path and
+// matrix://homeserver/!room:server for a Matrix room, with the access token
+// read from the MATRIX_ACCESS_TOKEN environment variable.
+func newNotifier(notifyURL string) (notifier, error) {
+	u, err := url.Parse(notifyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	switch u.Scheme {
+	case "teams":
+		u.Scheme = "https"
+		return &teamsNotifier{webhook: u.String(), client: &http.Client{Timeout: 30 * time.Second}}, nil
+	case "discord":
+		u.Scheme = "https"
+		return &discordNotifier{webhook: u.String(), client: &http.Client{Timeout: 30 * time.Second}}, nil
+	case "matrix":
+		room := strings.TrimPrefix(u.Path, "/")
+		if !strings.HasPrefix(room, "!") {
+			return nil, fmt.Errorf("missing the room ID, such as !abc:example.org, in %q", u.Redacted())
+		}
+		token := os.Getenv("MATRIX_ACCESS_TOKEN")
+		if token == "" {
+			return nil, errors.New("the Matrix notifications need an access token in MATRIX_ACCESS_TOKEN")
+		}
+		return &matrixNotifier{homeserver: "https://" + u.Host, room: room, token: token, client: &http.Client{Timeout: 30 * time.Second}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported notification URL %q, only teams://, discord:// and matrix:// URLs are supported", u.Redacted())
+	}
+}
+
+// notifyAll sends the digest to every notifier, returning the first error.
+func notifyAll(ctx context.Context, notifiers []notifier, d digest) error {
+	var first error
+	for _, n := range notifiers {
+		if err := n.notify(ctx, d); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// sendJSON sends the payload as JSON and fails unless the answer is a
+// success.
+func sendJSON(ctx context.Context, client *http.Client, method string, u string, header http.Header, payload interface{}) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("%s returned %s: %s", req.URL.Host, resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// teamsNotifier posts the digests as Adaptive Cards to a Microsoft Teams
+// incoming webhook.
+type teamsNotifier struct {
+	webhook string
+	client  *http.Client
+}
+
+func (n *teamsNotifier) notify(ctx context.Context, d digest) error {
+	facts := []map[string]string{}
+	for _, fact := range d.facts() {
+		facts = append(facts, map[string]string{"title": fact.Name, "value": fact.Value})
+	}
+	body := []interface{}{
+		map[string]interface{}{"type": "TextBlock", "text": d.title(), "size": "Medium", "weight": "Bolder", "wrap": true},
+		map[string]interface{}{"type": "FactSet", "facts": facts},
+	}
+	for _, line := range d.breaches() {
+		body = append(body, map[string]interface{}{"type": "TextBlock", "text": "- " + line, "wrap": true, "spacing": "None"})
+	}
+	if lines := d.stale(); len(lines) > 0 {
+		body = append(body, map[string]interface{}{"type": "TextBlock", "text": fmt.Sprintf("Stale PRs: %d", len(d.Stale)), "weight": "Bolder"})
+		for _, line := range lines {
+			body = append(body, map[string]interface{}{"type": "TextBlock", "text": "- " + line, "wrap": true, "spacing": "None"})
+		}
+	}
+
+	card := map[string]interface{}{
+		"type": "message",
+		"attachments": []interface{}{map[string]interface{}{
+			"contentType": "application/vnd.microsoft.card.adaptive",
+			"content": map[string]interface{}{
+				"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+				"type":    "AdaptiveCard",
+				"version": "1.4",
+				"body":    body,
+			},
+		}},
+	}
+	if err := sendJSON(ctx, n.client, http.MethodPost, n.webhook, nil, card); err != nil {
+		return fmt.Errorf("posting to Microsoft Teams: %w", err)
+	}
+	return nil
+}
+
+// discordNotifier posts the digests as embeds to a Discord webhook.
+type discordNotifier struct {
+	webhook string
+	client  *http.Client
+}
+
+func (n *discordNotifier) notify(ctx context.Context, d digest) error {
+	var fields []map[string]interface{}
+	for _, fact := range d.facts() {
+		fields = append(fields, map[string]interface{}{"name": fact.Name, "value": fact.Value, "inline": true})
+	}
+	embeds := []map[string]interface{}{{
+		"title":       d.title(),
+		"description": strings.Join(d.breaches(), "\n"),
+		"fields":      fields,
+	}}
+	if lines := d.stale(); len(lines) > 0 {
+		embeds = append(embeds, map[string]interface{}{
+			"title":       fmt.Sprintf("Stale PRs of %s: %d", d.Repository, len(d.Stale)),
+			"description": strings.Join(lines, "\n"),
+		})
+	}
+
+	message := map[string]interface{}{"username": "time2review", "embeds": embeds}
+	if err := sendJSON(ctx, n.client, http.MethodPost, n.webhook, nil, message); err != nil {
+		return fmt.Errorf("posting to Discord: %w", err)
+	}
+	return nil
+}
+
+// matrixNotifier sends the digests as notices to a Matrix room the user of
+// the access token has joined.
+type matrixNotifier struct {
+	homeserver string
+	room       string
+	token      string
+	client     *http.Client
+}
+
+func (n *matrixNotifier) notify(ctx context.Context, d digest) error {
+	var text, formatted strings.Builder
+	fmt.Fprintf(&text, "%s\n", d.title())
+	fmt.Fprintf(&formatted, "<h4>%s</h4><ul>", html.EscapeString(d.title()))
+	for _, fact := range d.facts() {
+		fmt.Fprintf(&text, "%s: %s\n", fact.Name, fact.Value)
+		fmt.Fprintf(&formatted, "<li>%s: %s</li>", html.EscapeString(fact.Name), html.EscapeString(fact.Value))
+	}
+	formatted.WriteString("</ul>")
+	writeList := func(title string, lines []string) {
+		if len(lines) == 0 {
+			return
+		}
+		fmt.Fprintf(&text, "%s:\n", title)
+		fmt.Fprintf(&formatted, "<p>%s:</p><ul>", html.EscapeString(title))
+		for _, line := range lines {
+			fmt.Fprintf(&text, "  %s\n", line)
+			fmt.Fprintf(&formatted, "<li>%s</li>", html.EscapeString(line))
+		}
+		formatted.WriteString("</ul>")
+	}
+	writeList("Worst SLA breaches", d.breaches())
+	writeList("Stale PRs", d.stale())
+
+	message := map[string]string{
+		"msgtype":        "m.notice",
+		"body":           text.String(),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": formatted.String(),
+	}
+	// The transaction ID only has to be unique per access token
+	u := fmt.Sprintf("%s/_matrix/client/v3/rooms/%s/send/m.room.message/time2review-%s", n.homeserver, url.PathEscape(n.room), strconv.FormatInt(time.Now().UnixNano(), 10))
+	header := http.Header{"Authorization": {"Bearer " + n.token}}
+	if err := sendJSON(ctx, n.client, http.MethodPut, u, header, message); err != nil {
+		return fmt.Errorf("sending to Matrix: %w", err)
+	}
+	return nil
+}
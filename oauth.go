@@ -0,0 +1,402 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"golang.org/x/oauth2"
+	githuboauth "golang.org/x/oauth2/github"
+)
+
+const (
+	sessionCookie = "time2review_session"
+	stateCookie   = "time2review_state"
+	// sessionTTL is how long a login lasts.
+	sessionTTL = 7 * 24 * time.Hour
+	// accessTTL is how long whether a user can read a repository is
+	// remembered before asking GitHub again.
+	accessTTL = 10 * time.Minute
+)
+
+// githubLogin logs the users of the server in with a GitHub OAuth app
+// (--oauth-client-id), so that every user only sees the reports of the
+// repositories their token can read, and pins the repositories of their own
+// dashboard. The pins are kept in the --store when there is one. The badges
+// and feeds of the public repositories are served to everyone, as README
+// images and feed readers do not log in.
+type githubLogin struct {
+	config *oauth2.Config
+	store  string
+	// anonymous asks GitHub whether the repositories are public.
+	anonymous *github.Client
+
+	mu       sync.Mutex
+	sessions map[string]*session
+	// public remembers whether a repository is public, by "owner/repo", and
+	// until when.
+	public map[string]repoAccess
+	// pins are the pinned repositories by login when there is no store.
+	pins map[string][]string
+}
+
+// session is a logged in user.
+type session struct {
+	login   string
+	client  *github.Client
+	expires time.Time
+	// access remembers whether the user can read a repository, by
+	// "owner/repo", and until when.
+	access map[string]repoAccess
+}
+
+type repoAccess struct {
+	readable bool
+	until    time.Time
+}
+
+func newGitHubLogin(clientID string, clientSecret string, store string) *githubLogin {
+	return &githubLogin{
+		config: &oauth2.Config{
+			ClientID:     clientID,
+			ClientSecret: clientSecret,
+			// GitHub has no read-only scope for the private repositories,
+			// asking whether the user can read one needs the full repo
+			// scope. The tokens are only used for that and only kept in
+			// memory.
+			Scopes:   []string{"repo"},
+			Endpoint: githuboauth.Endpoint,
+		},
+		store:     store,
+		anonymous: github.NewClient(nil),
+		sessions:  make(map[string]*session),
+		public:    make(map[string]repoAccess),
+		pins:      make(map[string][]string),
+	}
+}
+
+func randomToken() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+func secureRequest(req *http.Request) bool {
+	return req.TLS != nil || req.Header.Get("X-Forwarded-Proto") == "https"
+}
+
+func setCookie(w http.ResponseWriter, req *http.Request, name string, value string, maxAge time.Duration) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Path:     "/",
+		MaxAge:   int(maxAge.Seconds()),
+		HttpOnly: true,
+		Secure:   secureRequest(req),
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// session returns the session of the request, nil when the user is not
+// logged in.
+func (l *githubLogin) session(req *http.Request) *session {
+	cookie, err := req.Cookie(sessionCookie)
+	if err != nil {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s, ok := l.sessions[cookie.Value]
+	if !ok {
+		return nil
+	}
+	if time.Now().After(s.expires) {
+		delete(l.sessions, cookie.Value)
+		return nil
+	}
+	return s
+}
+
+// canRead reports whether the user of the session can read the repository,
+// asking GitHub with their token.
+func (l *githubLogin) canRead(ctx context.Context, s *session, owner string, repo string) bool {
+	key := owner + "/" + repo
+	l.mu.Lock()
+	access, ok := s.access[key]
+	l.mu.Unlock()
+	if ok && time.Now().Before(access.until) {
+		return access.readable
+	}
+
+	_, _, err := s.client.Repositories.Get(ctx, owner, repo)
+	readable := err == nil
+	if err != nil {
+		slog.Debug("The user cannot read the repository", "login", s.login, "repo", key, "err", err)
+	}
+	l.mu.Lock()
+	s.access[key] = repoAccess{readable: readable, until: time.Now().Add(accessTTL)}
+	l.mu.Unlock()
+	return readable
+}
+
+// isPublic reports whether anyone can read the repository, asking GitHub
+// without a token.
+func (l *githubLogin) isPublic(ctx context.Context, owner string, repo string) bool {
+	key := owner + "/" + repo
+	l.mu.Lock()
+	access, ok := l.public[key]
+	l.mu.Unlock()
+	if ok && time.Now().Before(access.until) {
+		return access.readable
+	}
+
+	_, _, err := l.anonymous.Repositories.Get(ctx, owner, repo)
+	l.mu.Lock()
+	l.public[key] = repoAccess{readable: err == nil, until: time.Now().Add(accessTTL)}
+	l.mu.Unlock()
+	return err == nil
+}
+
+// handleLogin redirects to GitHub to log in.
+func (l *githubLogin) handleLogin(w http.ResponseWriter, req *http.Request) {
+	state := randomToken()
+	setCookie(w, req, stateCookie, state, 10*time.Minute)
+	http.Redirect(w, req, l.config.AuthCodeURL(state), http.StatusFound)
+}
+
+// handleCallback is where GitHub redirects to once the user logged in.
+func (l *githubLogin) handleCallback(w http.ResponseWriter, req *http.Request) {
+	state, err := req.Cookie(stateCookie)
+	if err != nil || subtle.ConstantTimeCompare([]byte(state.Value), []byte(req.URL.Query().Get("state"))) != 1 {
+		http.Error(w, "invalid OAuth state, log in again", http.StatusBadRequest)
+		return
+	}
+	setCookie(w, req, stateCookie, "", -time.Second)
+
+	token, err := l.config.Exchange(req.Context(), req.URL.Query().Get("code"))
+	if err != nil {
+		slog.Error("Logging in with GitHub failed", "err", err)
+		http.Error(w, "logging in with GitHub failed", http.StatusBadGateway)
+		return
+	}
+	client := github.NewClient(l.config.Client(context.Background(), token))
+	user, _, err := client.Users.Get(req.Context(), "")
+	if err != nil {
+		slog.Error("Logging in with GitHub failed", "err", err)
+		http.Error(w, "logging in with GitHub failed", http.StatusBadGateway)
+		return
+	}
+
+	id := randomToken()
+	l.mu.Lock()
+	// Drop the sessions that expired without the users coming back
+	now := time.Now()
+	for key, s := range l.sessions {
+		if now.After(s.expires) {
+			delete(l.sessions, key)
+		}
+	}
+	l.sessions[id] = &session{login: user.GetLogin(), client: client, expires: time.Now().Add(sessionTTL), access: make(map[string]repoAccess)}
+	l.mu.Unlock()
+	setCookie(w, req, sessionCookie, id, sessionTTL)
+	slog.Info("Logged in", "login", user.GetLogin())
+	http.Redirect(w, req, "/", http.StatusFound)
+}
+
+// handleLogout ends the session. It only answers POST, so that other sites
+// cannot log the users out with a link or an image.
+func (l *githubLogin) handleLogout(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.Header().Set("Allow", "POST")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if cookie, err := req.Cookie(sessionCookie); err == nil {
+		l.mu.Lock()
+		delete(l.sessions, cookie.Value)
+		l.mu.Unlock()
+	}
+	setCookie(w, req, sessionCookie, "", -time.Second)
+	http.Redirect(w, req, "/", http.StatusFound)
+}
+
+// loadPins returns the repositories the user pinned, as "owner/repo".
+func (l *githubLogin) loadPins(login string) ([]string, error) {
+	if l.store == "" {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return append([]string{}, l.pins[login]...), nil
+	}
+	store, err := openStore(l.store)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	repos, err := store.Pins(login)
+	if err != nil {
+		return nil, err
+	}
+	pins := []string{}
+	for _, r := range repos {
+		pins = append(pins, r.Owner+"/"+r.Repo)
+	}
+	return pins, nil
+}
+
+func (l *githubLogin) savePins(login string, pins []string) error {
+	if l.store == "" {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.pins[login] = pins
+		return nil
+	}
+	var repos []Repository
+	for _, pin := range pins {
+		owner, repo, _ := strings.Cut(pin, "/")
+		repos = append(repos, Repository{Owner: owner, Repo: repo})
+	}
+	store, err := openStore(l.store)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return store.SavePins(login, repos)
+}
+
+// serveMe answers /api/me with the login of the user and their pinned
+// repositories, and sets the pins on PUT.
+func (s *reportServer) serveMe(w http.ResponseWriter, req *http.Request) {
+	if s.login == nil {
+		http.NotFound(w, req)
+		return
+	}
+	user := s.login.session(req)
+	if user == nil {
+		http.Error(w, "log in first", http.StatusUnauthorized)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var pins []string
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, 64<<10)).Decode(&pins); err != nil {
+			http.Error(w, "expected a JSON array of owner/repo", http.StatusBadRequest)
+			return
+		}
+		readable := []string{}
+		for _, pin := range pins {
+			owner, repo, ok := strings.Cut(pin, "/")
+			if ok && s.login.canRead(req.Context(), user, owner, repo) {
+				readable = append(readable, pin)
+			}
+		}
+		if err := s.login.savePins(user.login, readable); err != nil {
+			slog.Error("Saving the pins failed", "login", user.login, "err", err)
+			http.Error(w, "saving the pins failed", http.StatusInternalServerError)
+			return
+		}
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	pins, err := s.login.loadPins(user.login)
+	if err != nil {
+		slog.Error("Loading the pins failed", "login", user.login, "err", err)
+		http.Error(w, "loading the pins failed", http.StatusInternalServerError)
+		return
+	}
+	writeAPI(w, struct {
+		Login string   `json:"login"`
+		Pins  []string `json:"pins"`
+	}{user.login, pins})
+}
+
+// publicRoute reports whether the path serves the public repositories to
+// the users who did not log in: the badges and the feeds.
+func publicRoute(path string) bool {
+	return strings.HasPrefix(path, "/badge/") || strings.HasPrefix(path, "/feed/")
+}
+
+// requireLogin only lets the requests of logged in users through to next,
+// redirecting the pages to the login and refusing the other requests. The
+// badges and feeds go through, they check the repository is public.
+func (l *githubLogin) requireLogin(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/login", "/oauth/callback", "/logout":
+			next.ServeHTTP(w, req)
+			return
+		}
+		if publicRoute(req.URL.Path) {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if l.session(req) != nil {
+			next.ServeHTTP(w, req)
+			return
+		}
+		if req.URL.Path == "/" || req.URL.Path == "/index.html" {
+			http.Redirect(w, req, "/login", http.StatusFound)
+			return
+		}
+		http.Error(w, "log in first", http.StatusUnauthorized)
+	})
+}
+
+// canRead reports whether the user of the request can read the repository,
+// any repository when nobody logs in. The users who did not log in read the
+// badges and feeds of the public repositories.
+func (s *reportServer) canRead(req *http.Request, owner string, repo string) bool {
+	if s.login == nil {
+		return true
+	}
+	user := s.login.session(req)
+	if user == nil && !publicRoute(req.URL.Path) {
+		return false
+	}
+	return s.canReadAs(req.Context(), user, owner, repo)
+}
+
+// canReadAs reports whether the user of the session can read the repository,
+// only the public repositories without a session, and any repository when
+// nobody logs in.
+func (s *reportServer) canReadAs(ctx context.Context, user *session, owner string, repo string) bool {
+	if s.login == nil {
+		return true
+	}
+	readable := func(owner string, repo string) bool {
+		if user == nil {
+			return s.login.isPublic(ctx, owner, repo)
+		}
+		return s.login.canRead(ctx, user, owner, repo)
+	}
+
+	// The users read the reports of a group when they read all of its
+	// repositories
+	if owner == groupOwner {
+		members, ok := s.groups[repo]
+		if !ok {
+			return false
+		}
+		for _, member := range members {
+			if !readable(member.owner, member.repo) {
+				return false
+			}
+		}
+		return true
+	}
+	return readable(owner, repo)
+}
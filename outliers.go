@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// Outlier is a PR whose merge time or first response time lies far away from
+// the ones of the other PRs.
+type Outlier struct {
+	Repository string
+	Number     int
+	Title      string
+	Metric     string
+	Value      time.Duration
+}
+
+// findOutliers flags the PRs whose merge time or time to first response lies
+// more than k standard deviations from the mean (method "stddev") or more
+// than k interquartile ranges outside the quartiles (method "iqr").
+func findOutliers(prData []PRInfo, method string, k float64) ([]Outlier, error) {
+	metrics := []struct {
+		name  string
+		value func(PRInfo) (time.Duration, bool)
+	}{
+		{"merge time", func(pr PRInfo) (time.Duration, bool) { return pr.Duration, true }},
+		{"time to first response", func(pr PRInfo) (time.Duration, bool) { return pr.TimeToFirstResponse, pr.FirstResponder != "" }},
+	}
+
+	var outliers []Outlier
+	for _, metric := range metrics {
+		var values []float64
+		for _, pr := range prData {
+			if v, ok := metric.value(pr); ok {
+				values = append(values, float64(v))
+			}
+		}
+
+		low, high, err := outlierBounds(values, method, k)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, pr := range prData {
+			v, ok := metric.value(pr)
+			if ok && (float64(v) < low || float64(v) > high) {
+				outliers = append(outliers, Outlier{Repository: pr.Repository, Number: pr.Number, Title: pr.Title, Metric: metric.name, Value: v})
+			}
+		}
+	}
+	return outliers, nil
+}
+
+// outlierBounds returns the range values must lie in to not be outliers.
+func outlierBounds(values []float64, method string, k float64) (low float64, high float64, err error) {
+	if len(values) < 2 {
+		return math.Inf(-1), math.Inf(1), nil
+	}
+
+	switch method {
+	case "stddev":
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		mean := sum / float64(len(values))
+
+		var squares float64
+		for _, v := range values {
+			squares += (v - mean) * (v - mean)
+		}
+		stddev := math.Sqrt(squares / float64(len(values)))
+		return mean - k*stddev, mean + k*stddev, nil
+	case "iqr":
+		sorted := append([]float64(nil), values...)
+		sort.Float64s(sorted)
+		q1, q3 := quantile(sorted, 0.25), quantile(sorted, 0.75)
+		iqr := q3 - q1
+		return q1 - k*iqr, q3 + k*iqr, nil
+	default:
+		return 0, 0, fmt.Errorf("unknown outlier method %q", method)
+	}
+}
+
+// quantile returns the q-quantile of the sorted values, interpolating between
+// the closest ranks.
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+// withoutOutliers returns the PRs that were not flagged as outliers.
+func withoutOutliers(prData []PRInfo, outliers []Outlier) []PRInfo {
+	flagged := make(map[string]bool)
+	for _, outlier := range outliers {
+		flagged[prReference(outlier.Repository, outlier.Number)] = true
+	}
+
+	var kept []PRInfo
+	for _, pr := range prData {
+		if !flagged[prReference(pr.Repository, pr.Number)] {
+			kept = append(kept, pr)
+		}
+	}
+	return kept
+}
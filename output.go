@@ -0,0 +1,257 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// reportWriter writes the reports of a run, one per quarter, to a
+// destination. close is called once all the reports were written.
+type reportWriter interface {
+	write(r Report) error
+	close() error
+}
+
+// newReportWriter creates the writer for an --output destination with the
+// exporter of the format, or when none is given with the exporter of the
+// extension of the file: .json, .csv, .tsv and .parquet files get the reports
+// as JSON, CSV, TSV and Parquet, anything else the text report, or the
+// template when one is given. "-" stands for the standard output.
+func newReportWriter(destination string, format string, tmpl *template.Template, text textOptions) (reportWriter, error) {
+	if format == "" {
+		switch ext := strings.ToLower(filepath.Ext(destination)); ext {
+		case ".json", ".csv", ".tsv", ".parquet":
+			format = strings.TrimPrefix(ext, ".")
+		default:
+			format = "text"
+		}
+	}
+	newExporter, ok := exporters[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown output format %q, expected one of %s", format, strings.Join(exporterNames(), ", "))
+	}
+
+	var out io.WriteCloser = nopCloser{os.Stdout}
+	if destination != "-" {
+		file, err := os.Create(destination)
+		if err != nil {
+			return nil, err
+		}
+		out = file
+		text.Color = false
+	} else if info, err := os.Stdout.Stat(); err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		// Only terminals get colors
+		text.Color = false
+	}
+	return &exporterWriter{exporter: newExporter(out, exportOptions{Template: tmpl, Text: text}), out: out}, nil
+}
+
+func init() {
+	registerExporter("text", func(out io.Writer, opts exportOptions) Exporter {
+		return &textWriter{out: out, tmpl: opts.Template, opts: opts.Text}
+	})
+	registerExporter("json", func(out io.Writer, opts exportOptions) Exporter {
+		return &jsonWriter{out: out}
+	})
+	registerExporter("csv", func(out io.Writer, opts exportOptions) Exporter {
+		return &csvWriter{csv: csv.NewWriter(out)}
+	})
+	registerExporter("tsv", func(out io.Writer, opts exportOptions) Exporter {
+		return &tsvWriter{out: out}
+	})
+	registerExporter("table", func(out io.Writer, opts exportOptions) Exporter {
+		return &tableWriter{out: out}
+	})
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+// textWriter writes the human readable report.
+type textWriter struct {
+	out  io.Writer
+	tmpl *template.Template
+	opts textOptions
+}
+
+func (w *textWriter) Name() string {
+	return "text"
+}
+
+func (w *textWriter) Export(r Report) error {
+	if w.tmpl != nil {
+		return w.tmpl.Execute(w.out, r)
+	}
+	printReport(w.out, r, w.opts)
+	return nil
+}
+
+// jsonWriter writes all the reports as a single JSON array, so it keeps them
+// until it is flushed.
+type jsonWriter struct {
+	out     io.Writer
+	reports []Report
+}
+
+func (w *jsonWriter) Name() string {
+	return "json"
+}
+
+func (w *jsonWriter) Export(r Report) error {
+	w.reports = append(w.reports, r)
+	return nil
+}
+
+func (w *jsonWriter) Flush() error {
+	encoder := json.NewEncoder(w.out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(reportJSON(w.reports))
+}
+
+// csvWriter writes one row per PR of every report.
+type csvWriter struct {
+	csv           *csv.Writer
+	headerWritten bool
+}
+
+// csvHeader names the columns of the CSV, TSV and table outputs.
+func csvHeader() []string {
+	return []string{
+		"repository", "year", "quarter", "number", "title", "type", "creator", "merger",
+		"created_at", "merged_at", csvDurationColumn("merge"), csvDurationColumn("first_response"), csvDurationColumn("first_human_response"),
+		"commits", "commenters", "reviewers", "approved_reviews", "changes_requested_reviews",
+		"comments", "reviews",
+	}
+}
+
+func (w *csvWriter) Name() string {
+	return "csv"
+}
+
+func (w *csvWriter) Export(r Report) error {
+	if !w.headerWritten {
+		if err := w.csv.Write(csvHeader()); err != nil {
+			return err
+		}
+		w.headerWritten = true
+	}
+
+	for _, pr := range r.PRs {
+		if err := w.csv.Write(csvRow(r, pr)); err != nil {
+			return err
+		}
+	}
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// csvRow is the row of the PR of the report in the CSV, TSV and table
+// outputs.
+func csvRow(r Report, pr PRInfo) []string {
+	return []string{
+		r.Owner + "/" + r.Repo,
+		strconv.Itoa(r.Year),
+		r.Quarter,
+		strconv.Itoa(pr.Number),
+		pr.Title,
+		pr.Type,
+		pr.Creator,
+		pr.Merger,
+		pr.CreatedAt.Format(time.RFC3339),
+		pr.MergedAt.Format(time.RFC3339),
+		csvDuration(pr.Duration),
+		csvDuration(pr.TimeToFirstResponse),
+		csvDuration(pr.TimeToFirstHumanResponse),
+		strconv.Itoa(pr.Commits),
+		strconv.Itoa(len(uniqueCommenters(pr))),
+		strconv.Itoa(len(uniqueReviewers(pr))),
+		strconv.Itoa(pr.ApprovedReviews),
+		strconv.Itoa(pr.ChangesRequestedReviews),
+		strconv.Itoa(commentCount(pr)),
+		strconv.Itoa(reviewCount(pr)),
+	}
+}
+
+func (w *csvWriter) Flush() error {
+	w.csv.Flush()
+	return w.csv.Error()
+}
+
+// tsvWriter writes one tab separated line per PR of every report, with the
+// columns of the CSV. Nothing is quoted, so the tabs and line breaks of the
+// titles are replaced by spaces for cut and awk to split the lines right.
+type tsvWriter struct {
+	out           io.Writer
+	headerWritten bool
+}
+
+var tsvReplacer = strings.NewReplacer("\t", " ", "\r", " ", "\n", " ")
+
+func (w *tsvWriter) Name() string {
+	return "tsv"
+}
+
+func (w *tsvWriter) Export(r Report) error {
+	var lines strings.Builder
+	writeLine := func(fields []string) {
+		for i, field := range fields {
+			if i > 0 {
+				lines.WriteByte('\t')
+			}
+			lines.WriteString(tsvReplacer.Replace(field))
+		}
+		lines.WriteByte('\n')
+	}
+	if !w.headerWritten {
+		writeLine(csvHeader())
+		w.headerWritten = true
+	}
+	for _, pr := range r.PRs {
+		writeLine(csvRow(r, pr))
+	}
+	_, err := io.WriteString(w.out, lines.String())
+	return err
+}
+
+// tableWriter writes the columns of the CSV as a single aligned table, so it
+// keeps the rows of all the reports until it is flushed. The titles are
+// truncated to keep the lines readable in a terminal.
+type tableWriter struct {
+	out  io.Writer
+	rows [][]string
+}
+
+func (w *tableWriter) Name() string {
+	return "table"
+}
+
+func (w *tableWriter) Export(r Report) error {
+	for _, pr := range r.PRs {
+		row := csvRow(r, pr)
+		row[4] = truncate(tsvReplacer.Replace(row[4]), maxTitleWidth)
+		w.rows = append(w.rows, row)
+	}
+	return nil
+}
+
+func (w *tableWriter) Flush() error {
+	var table strings.Builder
+	for _, line := range alignColumns(append([][]string{csvHeader()}, w.rows...)) {
+		table.WriteString(line + "\n")
+	}
+	_, err := io.WriteString(w.out, table.String())
+	return err
+}
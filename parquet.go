@@ -0,0 +1,103 @@
+package main
+
+import (
+	"io"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// parquetRow is the schema of the per-PR dataset written to Parquet files.
+type parquetRow struct {
+	Repository              string    `parquet:"repository"`
+	Year                    int32     `parquet:"year"`
+	Quarter                 string    `parquet:"quarter"`
+	Number                  int64     `parquet:"number"`
+	Title                   string    `parquet:"title"`
+	Type                    string    `parquet:"type"`
+	Creator                 string    `parquet:"creator"`
+	Merger                  string    `parquet:"merger"`
+	Labels                  []string  `parquet:"labels,list"`
+	CreatedAt               time.Time `parquet:"created_at,timestamp(millisecond)"`
+	MergedAt                time.Time `parquet:"merged_at,timestamp(millisecond)"`
+	MergeHours              float64   `parquet:"merge_hours"`
+	FirstResponseHours      float64   `parquet:"first_response_hours"`
+	FirstHumanResponseHours float64   `parquet:"first_human_response_hours"`
+	DraftHours              float64   `parquet:"draft_hours"`
+	CIHours                 float64   `parquet:"ci_hours"`
+	Commits                 int32     `parquet:"commits"`
+	Additions               int32     `parquet:"additions"`
+	Deletions               int32     `parquet:"deletions"`
+	Commenters              int32     `parquet:"commenters"`
+	Reviewers               int32     `parquet:"reviewers"`
+	Comments                int32     `parquet:"comments"`
+	Reviews                 int32     `parquet:"reviews"`
+	ApprovedReviews         int32     `parquet:"approved_reviews"`
+	ChangesRequestedReviews int32     `parquet:"changes_requested_reviews"`
+	ReviewComments          int32     `parquet:"review_comments"`
+	PushesAfterFirstReview  int32     `parquet:"pushes_after_first_review"`
+	Reopened                int32     `parquet:"reopened"`
+	IsRevert                bool      `parquet:"is_revert"`
+	IsHotfix                bool      `parquet:"is_hotfix"`
+}
+
+// parquetWriter writes one row per PR of every report to a Parquet file, to
+// be loaded by Spark, DuckDB, BigQuery and the like.
+type parquetWriter struct {
+	writer *parquet.GenericWriter[parquetRow]
+}
+
+func init() {
+	registerExporter("parquet", func(out io.Writer, opts exportOptions) Exporter {
+		return &parquetWriter{writer: parquet.NewGenericWriter[parquetRow](out)}
+	})
+}
+
+func (w *parquetWriter) Name() string {
+	return "parquet"
+}
+
+func (w *parquetWriter) Export(r Report) error {
+	rows := make([]parquetRow, 0, len(r.PRs))
+	for _, pr := range r.PRs {
+		rows = append(rows, parquetRow{
+			Repository:              r.Owner + "/" + r.Repo,
+			Year:                    int32(r.Year),
+			Quarter:                 r.Quarter,
+			Number:                  int64(pr.Number),
+			Title:                   pr.Title,
+			Type:                    pr.Type,
+			Creator:                 pr.Creator,
+			Merger:                  pr.Merger,
+			Labels:                  pr.Labels,
+			CreatedAt:               pr.CreatedAt,
+			MergedAt:                pr.MergedAt,
+			MergeHours:              hours(pr.Duration),
+			FirstResponseHours:      hours(pr.TimeToFirstResponse),
+			FirstHumanResponseHours: hours(pr.TimeToFirstHumanResponse),
+			DraftHours:              hours(pr.TimeInDraft),
+			CIHours:                 hours(pr.CITime),
+			Commits:                 int32(pr.Commits),
+			Additions:               int32(pr.Additions),
+			Deletions:               int32(pr.Deletions),
+			Commenters:              int32(len(uniqueCommenters(pr))),
+			Reviewers:               int32(len(uniqueReviewers(pr))),
+			Comments:                int32(commentCount(pr)),
+			Reviews:                 int32(reviewCount(pr)),
+			ApprovedReviews:         int32(pr.ApprovedReviews),
+			ChangesRequestedReviews: int32(pr.ChangesRequestedReviews),
+			ReviewComments:          int32(pr.ReviewComments),
+			PushesAfterFirstReview:  int32(pr.PushesAfterFirstReview),
+			Reopened:                int32(pr.Reopened),
+			IsRevert:                pr.IsRevert,
+			IsHotfix:                pr.IsHotfix,
+		})
+	}
+	_, err := w.writer.Write(rows)
+	return err
+}
+
+// Flush writes the footer of the file.
+func (w *parquetWriter) Flush() error {
+	return w.writer.Close()
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// The data of a PR that can be missing when fetching it failed, recorded in
+// PRInfo.Unknown.
+const (
+	unknownDetails        = "details"
+	unknownTimeline       = "timeline"
+	unknownCheckRuns      = "check runs"
+	unknownFiles          = "files"
+	unknownComments       = "comments"
+	unknownReviewComments = "review comments"
+	unknownCommits        = "commits"
+	unknownReviews        = "reviews"
+)
+
+// known reports whether all the data was fetched for the PR.
+func (pr PRInfo) known(data ...string) bool {
+	for _, d := range data {
+		for _, unknown := range pr.Unknown {
+			if unknown == d {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// knownPRs returns the PRs all the data was fetched for, so that the
+// averages over that data are not skewed by the PRs missing it.
+func knownPRs(prData []PRInfo, data ...string) []PRInfo {
+	known := make([]PRInfo, 0, len(prData))
+	for _, pr := range prData {
+		if pr.known(data...) {
+			known = append(known, pr)
+		}
+	}
+	return known
+}
+
+// printIncompletePRs lists the PRs some data could not be fetched for.
+func printIncompletePRs(w io.Writer, prData []PRInfo) {
+	var incomplete []PRInfo
+	for _, pr := range prData {
+		if len(pr.Unknown) > 0 {
+			incomplete = append(incomplete, pr)
+		}
+	}
+	if len(incomplete) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "PRs with incomplete data: %d of %d\n", len(incomplete), len(prData))
+	for _, pr := range incomplete {
+		fmt.Fprintf(w, "  PR #%d: %s, unknown %s\n", pr.Number, pr.Title, strings.Join(pr.Unknown, ", "))
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+// Commenters and Reviewers of a PR hold one name per comment and per review,
+// so they count the total participation. The unique participants are the
+// distinct names among them. The creator of the PR is left out of both, as
+// replying on your own PR is not a response to it; PRs collected before the
+// creator was left out at collection time still have them in the lists.
+
+// withoutCreator returns the names other than the creator of the PR.
+func withoutCreator(pr PRInfo, names []string) []string {
+	var others []string
+	for _, name := range names {
+		if name != pr.Creator {
+			others = append(others, name)
+		}
+	}
+	return others
+}
+
+// uniqueCommenters returns the people other than the creator who commented
+// on the PR, each once.
+func uniqueCommenters(pr PRInfo) []string {
+	return unique(withoutCreator(pr, pr.Commenters))
+}
+
+// uniqueReviewers returns the people other than the creator who reviewed the
+// PR, each once.
+func uniqueReviewers(pr PRInfo) []string {
+	return unique(withoutCreator(pr, pr.Reviewers))
+}
+
+// commentCount returns the number of comments on the PR by people other than
+// its creator.
+func commentCount(pr PRInfo) int {
+	return len(withoutCreator(pr, pr.Commenters))
+}
+
+// reviewCount returns the number of reviews of the PR by people other than its
+// creator.
+func reviewCount(pr PRInfo) int {
+	return len(withoutCreator(pr, pr.Reviewers))
+}
+
+func averageNumberOfCommenters(prData []PRInfo) float64 {
+	if len(prData) == 0 {
+		return 0
+	}
+
+	var total int
+	for _, pr := range prData {
+		total += len(uniqueCommenters(pr))
+	}
+
+	return float64(total) / float64(len(prData))
+}
+
+func averageNumberOfReviews(prData []PRInfo) float64 {
+	if len(prData) == 0 {
+		return 0
+	}
+
+	var total int
+	for _, pr := range prData {
+		total += reviewCount(pr)
+	}
+
+	return float64(total) / float64(len(prData))
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// commentMarker marks the comments of the comment command, so that running
+// it again updates the comment instead of adding another one.
+const commentMarker = "<!-- time2review -->"
+
+// rollingWindow is the number of PRs merged before a PR it is compared with.
+const rollingWindow = 50
+
+// commentCommand is the comment command, which comments on a merged PR how
+// long its review took compared with the PRs merged before it.
+type commentCommand struct {
+	number int
+}
+
+// reviewIterations is the number of times the PR went back and forth between
+// its reviewers and its creator: one for the first review and one more for
+// every push after it. PRs nobody reviewed had no iterations.
+func reviewIterations(pr PRInfo) int {
+	if pr.ApprovedReviews+pr.ChangesRequestedReviews+pr.CommentedReviews == 0 {
+		return 0
+	}
+	return 1 + pr.PushesAfterFirstReview
+}
+
+// RollingMedians are the medians of the PRs merged before a PR.
+type RollingMedians struct {
+	PRs                      int
+	MergeTime                time.Duration
+	TimeToFirstHumanResponse time.Duration
+	ReviewIterations         float64
+}
+
+// rollingMedians returns the medians of the last window PRs merged before
+// the PR. The time to the first human response only counts the PRs that had
+// one.
+func rollingMedians(pr PRInfo, history []PRInfo, window int) RollingMedians {
+	var before []PRInfo
+	for _, other := range history {
+		if other.Number != pr.Number && other.MergedAt.Before(pr.MergedAt) {
+			before = append(before, other)
+		}
+	}
+	sort.Slice(before, func(i, j int) bool { return before[i].MergedAt.After(before[j].MergedAt) })
+	if len(before) > window {
+		before = before[:window]
+	}
+
+	var mergeTimes, responseTimes, iterations []float64
+	for _, other := range before {
+		mergeTimes = append(mergeTimes, float64(other.Duration))
+		if other.FirstHumanResponder != "" {
+			responseTimes = append(responseTimes, float64(other.TimeToFirstHumanResponse))
+		}
+		iterations = append(iterations, float64(reviewIterations(other)))
+	}
+	median := func(values []float64) float64 {
+		if len(values) == 0 {
+			return 0
+		}
+		sort.Float64s(values)
+		return quantile(values, 0.5)
+	}
+	return RollingMedians{
+		PRs:                      len(before),
+		MergeTime:                time.Duration(median(mergeTimes)),
+		TimeToFirstHumanResponse: time.Duration(median(responseTimes)),
+		ReviewIterations:         median(iterations),
+	}
+}
+
+// reviewCommentBody is the comment on the PR, comparing it with the medians.
+func reviewCommentBody(pr PRInfo, medians RollingMedians) string {
+	var body strings.Builder
+	body.WriteString(commentMarker + "\n")
+	body.WriteString("### Review stats\n\n")
+	fmt.Fprintf(&body, "| | This PR | Median of the last %d merged PRs |\n|---|---|---|\n", medians.PRs)
+
+	response := "no human response"
+	if pr.FirstHumanResponder != "" {
+		response = fmt.Sprintf("%s by @%s", humanDuration(pr.TimeToFirstHumanResponse.Round(time.Minute)), pr.FirstHumanResponder)
+	}
+	fmt.Fprintf(&body, "| Time to first review | %s | %s |\n", response, humanDuration(medians.TimeToFirstHumanResponse.Round(time.Minute)))
+	fmt.Fprintf(&body, "| Review iterations | %d | %g |\n", reviewIterations(pr), medians.ReviewIterations)
+	fmt.Fprintf(&body, "| Merge time | %s | %s |\n", humanDuration(pr.Duration.Round(time.Minute)), humanDuration(medians.MergeTime.Round(time.Minute)))
+
+	if medians.PRs > 0 && medians.MergeTime > 0 {
+		change := float64(pr.Duration-medians.MergeTime) / float64(medians.MergeTime)
+		switch {
+		case change < 0:
+			fmt.Fprintf(&body, "\nMerged %.0f%% faster than the median.\n", -change*100)
+		case change > 0:
+			fmt.Fprintf(&body, "\nMerged %.0f%% slower than the median.\n", change*100)
+		}
+	}
+	return body.String()
+}
+
+// postReviewComment comments the body on the PR, replacing the earlier
+// comment of the comment command if there is one. Only the comments of the
+// user of the token are replaced, anyone can write the marker.
+func postReviewComment(ctx context.Context, client GitHubClient, owner string, repo string, number int, body string) (*github.IssueComment, error) {
+	self, _, err := client.GetAuthenticatedUser(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("fetching the user of the token: %w", err)
+	}
+	comments, err := listAll(func(listOpts github.ListOptions) ([]*github.IssueComment, *github.Response, error) {
+		return client.ListComments(ctx, owner, repo, number, &github.IssueListCommentsOptions{ListOptions: listOpts})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching the comments of PR #%d: %w", number, err)
+	}
+	for _, comment := range comments {
+		if comment.GetUser().GetLogin() == self.GetLogin() && strings.HasPrefix(comment.GetBody(), commentMarker) {
+			edited, _, err := client.EditComment(ctx, owner, repo, comment.GetID(), body)
+			if err != nil {
+				return nil, fmt.Errorf("updating the comment on PR #%d: %w", number, err)
+			}
+			return edited, nil
+		}
+	}
+	created, _, err := client.CreateComment(ctx, owner, repo, number, body)
+	if err != nil {
+		return nil, fmt.Errorf("commenting on PR #%d: %w", number, err)
+	}
+	return created, nil
+}
+
+// commentOnPR collects the data of the merged PR and comments how it compares
+// with the PRs merged before it, either the ones of the --store or the ones
+// fetched as usual.
+func commentOnPR(ctx context.Context, client GitHubClient, owner string, repo string, number int, opts options, collectOpts collectOptions) error {
+	pr, _, err := client.GetPR(ctx, owner, repo, number)
+	if err != nil {
+		return fmt.Errorf("fetching PR #%d: %w", number, err)
+	}
+	if pr.MergedAt == nil {
+		return fmt.Errorf("PR #%d is not merged", number)
+	}
+	prInfos := getMergeTimes(ctx, client, owner, repo, []*github.PullRequest{pr}, newIssueCache(client, owner, repo, collectOpts.Calls), collectOpts, newProgress(true))
+	if len(prInfos) == 0 {
+		return fmt.Errorf("collecting PR #%d was interrupted", number)
+	}
+
+	var history []PRInfo
+	if opts.Store != "" {
+		store, err := openStore(opts.Store)
+		if err != nil {
+			return err
+		}
+		defer store.Close()
+		if history, err = store.LoadPRs(owner, repo); err != nil {
+			return err
+		}
+	} else if history, _, err = fetchPRs(ctx, client, owner, repo, collectOpts, false, newProgress(opts.Quiet)); err != nil {
+		return err
+	}
+
+	comment, err := postReviewComment(ctx, client, owner, repo, number, reviewCommentBody(prInfos[0], rollingMedians(prInfos[0], history, rollingWindow)))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Commented on PR #%d: %s\n", number, comment.GetHTMLURL())
+	return nil
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"runtime"
+	runtimepprof "runtime/pprof"
+	"time"
+)
+
+// startProfiling writes a CPU profile to cpuPath until the returned function
+// is called, which then writes a heap profile to memPath. Either path may be
+// empty.
+func startProfiling(cpuPath string, memPath string) (func() error, error) {
+	var cpu *os.File
+	if cpuPath != "" {
+		var err error
+		if cpu, err = os.Create(cpuPath); err != nil {
+			return nil, err
+		}
+		if err := runtimepprof.StartCPUProfile(cpu); err != nil {
+			cpu.Close()
+			return nil, err
+		}
+	}
+
+	return func() error {
+		if cpu != nil {
+			runtimepprof.StopCPUProfile()
+			if err := cpu.Close(); err != nil {
+				return err
+			}
+		}
+		if memPath == "" {
+			return nil
+		}
+		mem, err := os.Create(memPath)
+		if err != nil {
+			return err
+		}
+		// Only count the memory still in use
+		runtime.GC()
+		err = runtimepprof.WriteHeapProfile(mem)
+		if closeErr := mem.Close(); err == nil {
+			err = closeErr
+		}
+		return err
+	}, nil
+}
+
+// pprofAddr returns the address the profiles are served on, on the loopback
+// interface when addr has no host, such as ":6060", since the profiles
+// expose the memory of the process.
+func pprofAddr(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
+
+// servePprof serves the runtime profiles under /debug/pprof/ on addr, on its
+// own listener apart from the reports, until ctx is done.
+func servePprof(ctx context.Context, addr string) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	server := &http.Server{Addr: pprofAddr(addr), Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		server.Close()
+	}()
+
+	slog.Info("Serving the profiles", "addr", server.Addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
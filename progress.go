@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// apiCallsPerPR is the number of API calls needed to collect the data of a
+// single merged PR, used to estimate the remaining calls.
+const apiCallsPerPR = 8
+
+// progress reports how far the fetching of PRs got. On a terminal the status
+// line is redrawn in place, otherwise a line is printed every now and then so
+// that CI logs stay readable.
+type progress struct {
+	w           io.Writer
+	quiet       bool
+	interactive bool
+	pages       int
+	listed      int
+	processed   int
+	total       int
+}
+
+func newProgress(quiet bool) *progress {
+	interactive := false
+	if info, err := os.Stderr.Stat(); err == nil {
+		interactive = info.Mode()&os.ModeCharDevice != 0
+	}
+	return &progress{w: os.Stderr, quiet: quiet, interactive: interactive}
+}
+
+// pageFetched records a page of the PR listing with n PRs on it.
+func (p *progress) pageFetched(n int) {
+	p.pages++
+	p.listed += n
+	p.print(!p.interactive)
+}
+
+// queue records that n more PRs are about to be processed, those of the
+// page just fetched.
+func (p *progress) queue(n int) {
+	p.total += n
+	p.print(!p.interactive)
+}
+
+// prProcessed records that the data of one more PR has been collected.
+func (p *progress) prProcessed() {
+	p.processed++
+	p.print(!p.interactive && (p.processed%25 == 0 || p.processed == p.total))
+}
+
+// done terminates the status line.
+func (p *progress) done() {
+	if !p.quiet && p.interactive {
+		fmt.Fprintln(p.w)
+	}
+}
+
+func (p *progress) print(force bool) {
+	if p.quiet || (!p.interactive && !force) {
+		return
+	}
+
+	status := fmt.Sprintf("Fetched %d pages (%d PRs)", p.pages, p.listed)
+	if p.total > 0 {
+		status += fmt.Sprintf(", processed %d/%d PRs, ~%d API calls remaining", p.processed, p.total, (p.total-p.processed)*apiCallsPerPR)
+	}
+
+	if p.interactive {
+		fmt.Fprintf(p.w, "\r\033[K%s", status)
+	} else {
+		fmt.Fprintln(p.w, status)
+	}
+}
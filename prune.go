@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// retentionOf returns how long the store keeps the PRs of the repository:
+// the retention of the configuration, or else olderThan, keeping them all
+// when it is zero too.
+func retentionOf(retention map[string]configDuration, owner string, repo string, olderThan time.Duration) time.Duration {
+	if d, ok := retention[owner+"/"+repo]; ok {
+		return time.Duration(d)
+	}
+	return olderThan
+}
+
+// keepRetention deletes the stored PRs of the repository merged longer ago
+// than its retention in the configuration, keeping them all without one. The
+// runs and updates storing PRs call it, so that the store stays within the
+// retention without the prune command.
+func keepRetention(store *Store, retention map[string]configDuration, owner string, repo string, now time.Time) error {
+	keep := retentionOf(retention, owner, repo, 0)
+	if keep <= 0 {
+		return nil
+	}
+	_, err := store.PrunePRs(owner, repo, now.Add(-keep))
+	return err
+}
+
+// pruneStore deletes the stored PRs merged longer ago than the retention of
+// their repository, and then shrinks the store. It returns how many PRs it
+// deleted.
+func pruneStore(store *Store, retention map[string]configDuration, olderThan time.Duration, now time.Time) (int, error) {
+	repos, err := store.Repositories()
+	if err != nil {
+		return 0, fmt.Errorf("listing the stored repositories: %w", err)
+	}
+
+	pruned := 0
+	for _, r := range repos {
+		keep := retentionOf(retention, r.Owner, r.Repo, olderThan)
+		if keep <= 0 {
+			continue
+		}
+		n, err := store.PrunePRs(r.Owner, r.Repo, now.Add(-keep))
+		if err != nil {
+			return pruned, fmt.Errorf("pruning the PRs of %s/%s: %w", r.Owner, r.Repo, err)
+		}
+		slog.Info("Pruned the PRs", "owner", r.Owner, "repo", r.Repo, "merged_before", now.Add(-keep).Format(time.DateOnly), "prs", n)
+		pruned += n
+	}
+	if pruned > 0 {
+		if err := store.Vacuum(); err != nil {
+			return pruned, fmt.Errorf("shrinking the store: %w", err)
+		}
+	}
+	return pruned, nil
+}
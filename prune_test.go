@@ -0,0 +1,41 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeepRetention(t *testing.T) {
+	now := parseTime(t, "2024-06-01T00:00:00Z")
+	for _, tc := range []struct {
+		name      string
+		retention map[string]configDuration
+		want      int
+	}{
+		{name: "no retention", want: 2},
+		{name: "retention of another repository", retention: map[string]configDuration{"owner/other": configDuration(24 * time.Hour)}, want: 2},
+		{name: "retention", retention: map[string]configDuration{"owner/repo": configDuration(30 * 24 * time.Hour)}, want: 1},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			store, err := openStore(filepath.Join(t.TempDir(), "store.db"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer store.Close()
+			if err := store.SavePRs("owner", "repo", []PRInfo{
+				{Number: 1, MergedAt: parseTime(t, "2024-01-10T00:00:00Z")},
+				{Number: 2, MergedAt: parseTime(t, "2024-05-20T00:00:00Z")},
+			}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := keepRetention(store, tc.retention, "owner", "repo", now); err != nil {
+				t.Fatal(err)
+			}
+			if prs, err := store.LoadPRs("owner", "repo"); err != nil || len(prs) != tc.want {
+				t.Errorf("got %d stored PRs (%v), want %d", len(prs), err, tc.want)
+			}
+		})
+	}
+}
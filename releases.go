@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// ReleaseInfo describes a release and the PRs that shipped with it.
+type ReleaseInfo struct {
+	Name          string
+	PublishedAt   time.Time
+	SincePrevious time.Duration
+	MergedPRs     int
+}
+
+// RepoReleases are the releases of one repository of the run.
+type RepoReleases struct {
+	Repository string
+	Releases   []ReleaseInfo
+}
+
+// getReleases fetches the published releases of the repository, oldest
+// first, and counts the PRs merged between each release and the previous one.
+func getReleases(ctx context.Context, client GitHubClient, owner string, repo string, prInfos []PRInfo) ([]ReleaseInfo, error) {
+	releases, err := listAll(func(listOpts github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+		return client.ListReleases(ctx, owner, repo, &listOpts)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []ReleaseInfo
+	for _, release := range releases {
+		if release.GetDraft() || release.PublishedAt == nil {
+			continue
+		}
+		name := release.GetName()
+		if name == "" {
+			name = release.GetTagName()
+		}
+		infos = append(infos, ReleaseInfo{Name: name, PublishedAt: release.GetPublishedAt().UTC()})
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].PublishedAt.Before(infos[j].PublishedAt)
+	})
+
+	for i := range infos {
+		var previous time.Time
+		if i > 0 {
+			previous = infos[i-1].PublishedAt
+			infos[i].SincePrevious = infos[i].PublishedAt.Sub(previous)
+		}
+		for _, pr := range prInfos {
+			if pr.MergedAt.After(previous) && !pr.MergedAt.After(infos[i].PublishedAt) {
+				infos[i].MergedPRs++
+			}
+		}
+	}
+	return infos, nil
+}
+
+func printReleases(w io.Writer, releases []ReleaseInfo) {
+	fmt.Fprintln(w, "Release cadence:")
+	if len(releases) == 0 {
+		fmt.Fprintln(w, "  No published releases")
+		return
+	}
+
+	var total time.Duration
+	for i, release := range releases {
+		if i == 0 {
+			fmt.Fprintf(w, "  %s published %s with %d merged PRs\n", release.Name, release.PublishedAt.Format("2006-01-02"), release.MergedPRs)
+			continue
+		}
+		total += release.SincePrevious
+		fmt.Fprintf(w, "  %s published %s, %s after the previous release, with %d merged PRs\n", release.Name, release.PublishedAt.Format("2006-01-02"), formatDuration(release.SincePrevious), release.MergedPRs)
+	}
+	if len(releases) > 1 {
+		fmt.Fprintf(w, "  Average time between releases: %s\n", formatDuration(total/time.Duration(len(releases)-1)))
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// reopenCount returns how many times the PR was reopened after being closed.
+func reopenCount(timeline []*github.Timeline) int {
+	reopened := 0
+	for _, event := range timeline {
+		if event.GetEvent() == "reopened" {
+			reopened++
+		}
+	}
+	return reopened
+}
+
+// reopenedMergeTimes counts the PRs that were reopened at least once and
+// returns the average merge time of those PRs and of the others, to tell
+// whether reopening delays the merge.
+func reopenedMergeTimes(prData []PRInfo) (reopened int, averageReopened time.Duration, averageOthers time.Duration) {
+	var reopenedPRs, others []PRInfo
+	for _, pr := range prData {
+		if pr.Reopened > 0 {
+			reopenedPRs = append(reopenedPRs, pr)
+		} else {
+			others = append(others, pr)
+		}
+	}
+	return len(reopenedPRs), averageMergeTime(reopenedPRs), averageMergeTime(others)
+}
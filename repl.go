@@ -0,0 +1,175 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const replHelp = `Commands:
+  p90(duration)                     compute a --metric expression over the PRs
+  avg(commits) by creator           compute it for every creator, type, label...
+  where duration > 72h && is_bot    only keep the PRs meeting a --where condition
+  where                             keep all the PRs again
+  summary                           print the summary of the report of the PRs
+  list [n]                          print the PRs, or the first n of them
+  help                              print this help
+  quit                              leave
+`
+
+// repl answers the commands read from in about the PRs, so the collected PRs
+// can be explored without fetching them again for every question.
+type repl struct {
+	owner string
+	repo  string
+	all   []PRInfo
+	prs   []PRInfo
+	where whereFilter
+	opts  reportOptions
+	text  textOptions
+	out   io.Writer
+}
+
+func newRepl(owner string, repo string, prs []PRInfo, where whereFilter, opts reportOptions, text textOptions, out io.Writer) *repl {
+	r := &repl{owner: owner, repo: repo, all: prs, prs: prs, opts: opts, text: text, out: out}
+	if where.match != nil {
+		r.where, r.prs = where, where.filter(prs)
+	}
+	return r
+}
+
+// run reads the commands until quit or the end of in.
+func (r *repl) run(in io.Reader) error {
+	fmt.Fprintf(r.out, "%d PRs of %s/%s loaded, type help for the commands\n", len(r.all), r.owner, r.repo)
+	scanner := bufio.NewScanner(in)
+	for {
+		fmt.Fprint(r.out, r.prompt())
+		if !scanner.Scan() {
+			fmt.Fprintln(r.out)
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "quit" || line == "exit" {
+			return nil
+		}
+		if err := r.execute(line); err != nil {
+			fmt.Fprintln(r.out, "Error:", err)
+		}
+	}
+}
+
+func (r *repl) prompt() string {
+	if r.where.match == nil {
+		return "> "
+	}
+	return fmt.Sprintf("[%d PRs where %s] > ", len(r.prs), r.where.expr)
+}
+
+func (r *repl) execute(line string) error {
+	command, rest, _ := strings.Cut(line, " ")
+	rest = strings.TrimSpace(rest)
+	switch command {
+	case "":
+		return nil
+	case "help":
+		fmt.Fprint(r.out, replHelp)
+	case "where":
+		if rest == "" {
+			r.where, r.prs = whereFilter{}, r.all
+			fmt.Fprintf(r.out, "%d PRs\n", len(r.prs))
+			return nil
+		}
+		where, err := parseWhere(rest)
+		if err != nil {
+			return err
+		}
+		r.where, r.prs = where, where.filter(r.all)
+		fmt.Fprintf(r.out, "%d PRs\n", len(r.prs))
+	case "summary":
+		printSummary(r.out, newReport(r.owner, r.repo, 0, "", r.prs, r.opts))
+	case "list":
+		prs := r.text.Sort.sorted(r.prs)
+		if rest != "" {
+			n, err := strconv.Atoi(rest)
+			if err != nil || n <= 0 {
+				return fmt.Errorf("invalid number of PRs %q", rest)
+			}
+			prs = prs[:min(n, len(prs))]
+		}
+		printDetails(r.out, prs, nil, averageMergeTime(r.prs), false)
+	default:
+		return r.metric(line)
+	}
+	return nil
+}
+
+var replGroupBy = regexp.MustCompile(`^(.*\))\s+by\s+(\w+)$`)
+
+// metric prints the metric of the line, either over all the PRs or, when
+// followed by "by" and a text or list field, as a table with a row for every
+// value of the field.
+func (r *repl) metric(line string) error {
+	expr, by := line, ""
+	if match := replGroupBy.FindStringSubmatch(line); match != nil {
+		expr, by = match[1], match[2]
+	}
+	m, err := parseMetricExpr(expr)
+	if err != nil {
+		return err
+	}
+	if by == "" {
+		fmt.Fprintln(r.out, m.evaluate(r.prs).Value)
+		return nil
+	}
+
+	var keys func(pr PRInfo) []string
+	if text, ok := whereTextFields[by]; ok {
+		keys = func(pr PRInfo) []string { return []string{text(pr)} }
+	} else if list, ok := whereListFields[by]; ok {
+		keys = list
+	} else {
+		return fmt.Errorf("cannot group by %s, expected one of %s", by, strings.Join(append(sortedKeys(whereTextFields), sortedKeys(whereListFields)...), ", "))
+	}
+
+	groups := make(map[string][]PRInfo)
+	for _, pr := range r.prs {
+		for _, key := range keys(pr) {
+			groups[key] = append(groups[key], pr)
+		}
+	}
+	names := sortedKeys(groups)
+	sort.SliceStable(names, func(i, j int) bool { return len(groups[names[i]]) > len(groups[names[j]]) })
+
+	rows := [][]string{{by, "PRs", m.expr}}
+	for _, name := range names {
+		label := name
+		if label == "" {
+			label = "-"
+		}
+		rows = append(rows, []string{label, strconv.Itoa(len(groups[name])), m.evaluate(groups[name]).Value})
+	}
+	for _, line := range alignColumns(rows) {
+		fmt.Fprintln(r.out, line)
+	}
+	return nil
+}
+
+// replPRs loads the PRs of the --store, or fetches them once when there is no
+// store.
+func replPRs(ctx context.Context, client GitHubClient, owner string, repo string, opts options, collectOpts collectOptions) ([]PRInfo, error) {
+	if opts.Store == "" {
+		prs, _, err := fetchPRs(ctx, client, owner, repo, collectOpts, false, newProgress(opts.Quiet))
+		return prs, err
+	}
+	store, err := openStore(opts.Store)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+	return store.QueryPRs(owner, repo, opts.Query)
+}
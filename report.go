@@ -0,0 +1,336 @@
+package main
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// reportOptions configure how the aggregates of a report are computed.
+type reportOptions struct {
+	// HistogramBuckets are the upper bounds of the merge time histogram.
+	HistogramBuckets []time.Duration
+	// OutlierMethod is either "stddev" or "iqr", OutlierThreshold the number
+	// of standard deviations or interquartile ranges beyond which a PR is
+	// an outlier.
+	OutlierMethod    string
+	OutlierThreshold float64
+	// ExcludeOutliers leaves the outliers out of the averages.
+	ExcludeOutliers bool
+	// PathPrefixes are the path prefixes the metrics are broken down by.
+	PathPrefixes []string
+	// BusFactorDepth is the number of leading directories the bus factor is
+	// computed for.
+	BusFactorDepth int
+	// IdleGap is the time without activity after which a PR is idle, no PR
+	// is when zero.
+	IdleGap time.Duration
+	// LeaderboardSize is the number of people on each leaderboard, all when
+	// zero.
+	LeaderboardSize int
+	// SkipWeekends records that the durations of the PRs leave out weekends.
+	SkipWeekends bool
+	// LocalTimes records that the days and times of day are in the local
+	// time of the contributors with a timezone.
+	LocalTimes bool
+	// SLA are the targets the compliance is reported against.
+	SLA SLA
+	// Teams maps the users to their team for the cross-team reviews.
+	Teams map[string]string
+	// Mentees are the contributors the mentorship is reported for.
+	Mentees []string
+	// Karma are the weights the review karma is scored with.
+	Karma KarmaWeights
+	// Metrics are the --metric expressions computed over the PRs.
+	Metrics []metricExpr
+}
+
+// Report holds the PRs of one quarter of a repository together with all the
+// aggregates computed over them.
+type Report struct {
+	Owner   string
+	Repo    string
+	Year    int
+	Quarter string
+	PRs     []PRInfo
+
+	// Incomplete is set when fetching was interrupted or timed out, so the
+	// report only covers part of the PRs.
+	Incomplete bool
+
+	AverageMergeTime                        time.Duration
+	AverageTimeToFirstHumanResponse         time.Duration
+	AverageTimeToFirstBotResponse           time.Duration
+	SizeWeightedMergeTime                   time.Duration
+	SizeWeightedTimeToFirstHumanResponse    time.Duration
+	AverageLeadTime                         time.Duration
+	PRsWithLinkedIssues                     int
+	JiraProjects                            []ProjectCycleTime
+	LinearTeams                             []ProjectCycleTime
+	AverageTimeInDraft                      time.Duration
+	DraftPRs                                int
+	ReopenedPRs                             int
+	AverageMergeTimeReopened                time.Duration
+	AverageMergeTimeNotReopened             time.Duration
+	AveragePushesAfterFirstReview           float64
+	AverageReviewRounds                     float64
+	PingPongPRs                             []PingPongPR
+	TotalWaitingOnAuthors                   time.Duration
+	TotalWaitingOnReviewers                 time.Duration
+	AverageCITime                           time.Duration
+	CIShareOfMergeTime                      float64
+	AverageHumanWaitTime                    time.Duration
+	AverageGreenToMerge                     time.Duration
+	GreenPRs                                int
+	AverageNumberOfComments                 float64
+	AverageNumberOfCommenters               float64
+	AverageReviewCommentWords               float64
+	AverageReviewCommentCharacters          float64
+	PRsWithoutReviewComments                []int
+	AverageNumberOfReviewers                float64
+	AverageNumberOfReviews                  float64
+	AverageNumberOfCommits                  float64
+	DayWithMostPRsCreated                   string
+	TimeOfTheDayWithMostPRsCreated          string
+	DayWithMostPRsMerged                    string
+	TimeOfTheDayWithMostPRsMerged           string
+	DayOfTheWeekWithMostFirstHumanResponses string
+	TimeOfTheDayWithMostFirstHumanResponses string
+	DayOfTheWeekWithMostPRReviews           string
+	TimeOfTheDayWithMostPRReviews           string
+	Developers                              []string
+	ReviewerLeaderboard                     []LeaderboardEntry
+	CommenterLeaderboard                    []LeaderboardEntry
+	CreatorLeaderboard                      []LeaderboardEntry
+	FirstHumanResponderLeaderboard          []LeaderboardEntry
+	FirstResponderLeaderboard               []LeaderboardEntry
+	MergerLeaderboard                       []LeaderboardEntry
+	ApproverLeaderboard                     []LeaderboardEntry
+	ChangesRequesterLeaderboard             []LeaderboardEntry
+	// The Top fields hold the first name of the leaderboards above.
+	TopReviewer             string
+	TopCommenter            string
+	TopCreator              string
+	TopFirstHumanResponder  string
+	TopFirstResponder       string
+	TopMerger               string
+	TopApprover             string
+	TopChangesRequester     string
+	SelfMergeRate           float64
+	CrossTeamReviewShare    float64
+	CrossTeamReviews        []TeamReviews
+	Mentorships             []Mentorship
+	ReviewKarma             []Karma
+	ApprovedReviews         int
+	ChangesRequestedReviews int
+	PRsWithChangesRequested int
+	ChangesRequestedRate    float64
+	AverageChangesRequested float64
+	CommentedReviews        int
+	MergeTimeHistogram      []HistogramBucket
+	ReviewerLoad            []ReviewerShare
+	ReviewerLoadGini        float64
+	TypeSegments            []Segment
+	LabelSegments           []Segment
+	MilestoneSegments       []Segment
+	PathSegments            []Segment
+	SizeBuckets             []SizeBucket
+	RevertRate              float64
+	HotfixRate              float64
+	ChangeKindSegments      []Segment
+	BusFactors              []PathBusFactor
+	SLA                     SLA
+	SLABreaches             []SLABreach
+	SLACompliance           float64
+	SLAComplianceByLabel    []LabelCompliance
+	IdleGapThreshold        time.Duration
+	IdleGaps                []IdleGap
+	Outliers                []Outlier
+	OutliersExcluded        bool
+	WeekendsSkipped         bool
+	LocalTimes              bool
+	CustomMetrics           []CustomMetric
+	CustomSection           string
+	// The sections asked for besides the report, nil when they were not.
+	// Closed covers the PRs of the quarter, the others the whole history,
+	// so they only come with the last report of the run.
+	Closed                *ClosedSummary
+	Retention             []CohortRetention
+	QuarterlyLeaderboards []RoleLeaderboards
+	Forecast              *Forecast
+	Releases              []RepoReleases
+}
+
+func newReport(owner string, repo string, year int, quarter string, prInfos []PRInfo, opts reportOptions) Report {
+	approved, changesRequestedReviews, commented := countReviewsByState(prInfos)
+
+	outliers, err := findOutliers(prInfos, opts.OutlierMethod, opts.OutlierThreshold)
+	if err != nil {
+		slog.Error("Detecting outliers failed", "err", err)
+	}
+	averaged := prInfos
+	if opts.ExcludeOutliers {
+		averaged = withoutOutliers(prInfos, outliers)
+	}
+	averageDraftTime, drafts := averageTimeInDraft(averaged)
+	averageIssueLeadTime, linked := averageLeadTime(averaged)
+	reopened, averageReopened, averageNotReopened := reopenedMergeTimes(averaged)
+	averageCI, ciShare, averageHumans := waitTimes(knownPRs(averaged, unknownCheckRuns))
+	averageGreen, green := averageGreenToMerge(knownPRs(averaged, unknownCheckRuns))
+	breaches, compliance := slaCompliance(prInfos, opts.SLA)
+	revertRate, hotfixRate := revertAndHotfixRates(prInfos)
+	// The averages over the responses leave out the PRs missing any of them
+	responded := knownPRs(averaged, unknownComments, unknownReviewComments, unknownReviews)
+	depthWords, depthCharacters := averageReviewDepth(responded)
+	// The sizes of the PRs come with their details
+	sized := knownPRs(averaged, unknownDetails)
+	load, loadGini := reviewerLoad(prInfos)
+	averageRounds, pingPong := reviewRoundStats(knownPRs(averaged, unknownTimeline, unknownReviews))
+	onAuthors, onReviewers := totalWaitingTimes(knownPRs(prInfos, unknownTimeline, unknownReviews))
+	changesRequestedPRs, changesRequestedRate, averageChangesRequested := changesRequested(knownPRs(prInfos, unknownReviews))
+	crossTeamShare, crossTeam := crossTeamReviews(prInfos, opts.Teams)
+	reviewerBoard := leaderboard(prInfos, prReviewers, opts.LeaderboardSize)
+	commenterBoard := leaderboard(prInfos, prCommenters, opts.LeaderboardSize)
+	creatorBoard := leaderboard(prInfos, prCreator, opts.LeaderboardSize)
+	firstHumanResponderBoard := leaderboard(prInfos, prFirstHumanResponder, opts.LeaderboardSize)
+	firstResponderBoard := leaderboard(prInfos, prFirstResponder, opts.LeaderboardSize)
+	mergerBoard := leaderboard(prInfos, prMerger, opts.LeaderboardSize)
+	approverBoard := leaderboard(prInfos, prApprovers, opts.LeaderboardSize)
+	changesRequesterBoard := leaderboard(prInfos, prChangesRequesters, opts.LeaderboardSize)
+
+	var pathSegments []Segment
+	if len(opts.PathPrefixes) > 0 {
+		pathSegments = segmentBy(averaged, func(pr PRInfo) []string {
+			return matchingPrefixes(pr.Files, opts.PathPrefixes)
+		})
+	}
+
+	return Report{
+		Owner:   owner,
+		Repo:    repo,
+		Year:    year,
+		Quarter: quarter,
+		PRs:     prInfos,
+
+		AverageMergeTime:                        averageMergeTime(averaged),
+		AverageTimeToFirstHumanResponse:         averageFirstReponseHumanTime(responded),
+		AverageTimeToFirstBotResponse:           averageTimeToFirstBotResponse(responded),
+		SizeWeightedMergeTime:                   sizeWeightedAverage(sized, func(pr PRInfo) time.Duration { return pr.Duration }),
+		SizeWeightedTimeToFirstHumanResponse:    sizeWeightedAverage(knownPRs(responded, unknownDetails), func(pr PRInfo) time.Duration { return pr.TimeToFirstHumanResponse }),
+		AverageLeadTime:                         averageIssueLeadTime,
+		PRsWithLinkedIssues:                     linked,
+		JiraProjects:                            projectCycleTimes(averaged, jiraCycle),
+		LinearTeams:                             projectCycleTimes(averaged, linearCycle),
+		AverageTimeInDraft:                      averageDraftTime,
+		DraftPRs:                                drafts,
+		ReopenedPRs:                             reopened,
+		AverageMergeTimeReopened:                averageReopened,
+		AverageMergeTimeNotReopened:             averageNotReopened,
+		AveragePushesAfterFirstReview:           averagePushesAfterFirstReview(knownPRs(averaged, unknownTimeline, unknownReviews)),
+		AverageReviewRounds:                     averageRounds,
+		PingPongPRs:                             pingPong,
+		TotalWaitingOnAuthors:                   onAuthors,
+		TotalWaitingOnReviewers:                 onReviewers,
+		AverageCITime:                           averageCI,
+		CIShareOfMergeTime:                      ciShare,
+		AverageHumanWaitTime:                    averageHumans,
+		AverageGreenToMerge:                     averageGreen,
+		GreenPRs:                                green,
+		AverageNumberOfComments:                 averageNumberOfComments(responded),
+		AverageNumberOfCommenters:               averageNumberOfCommenters(responded),
+		AverageReviewCommentWords:               depthWords,
+		AverageReviewCommentCharacters:          depthCharacters,
+		PRsWithoutReviewComments:                prsWithoutReviewComments(prInfos),
+		AverageNumberOfReviewers:                averageNumberOfReviewers(responded),
+		AverageNumberOfReviews:                  averageNumberOfReviews(responded),
+		AverageNumberOfCommits:                  averageNumberOfCommits(knownPRs(averaged, unknownCommits)),
+		DayWithMostPRsCreated:                   dayWithMostPRsCreated(prInfos),
+		TimeOfTheDayWithMostPRsCreated:          timeOfTheDayWithMostPRsCreated(prInfos),
+		DayWithMostPRsMerged:                    dayMostPRsMerged(prInfos),
+		TimeOfTheDayWithMostPRsMerged:           timeOfTheDayWithMostPRsMerged(prInfos),
+		DayOfTheWeekWithMostFirstHumanResponses: dayOfTheWeekWithMostFirstHumanResponses(prInfos),
+		TimeOfTheDayWithMostFirstHumanResponses: timeOfTheDayWithMostFirstHumanResponses(prInfos),
+		DayOfTheWeekWithMostPRReviews:           dayOfTheWeekWithMostPRReviews(prInfos),
+		TimeOfTheDayWithMostPRReviews:           timeOfTheDayWithMostPRReviews(prInfos),
+		Developers:                              getTheNamesOfAllDevelopersWhoCreatedMergedReviewedCommentedOnOrApprovedPRs(prInfos),
+		ReviewerLeaderboard:                     reviewerBoard,
+		CommenterLeaderboard:                    commenterBoard,
+		CreatorLeaderboard:                      creatorBoard,
+		FirstHumanResponderLeaderboard:          firstHumanResponderBoard,
+		FirstResponderLeaderboard:               firstResponderBoard,
+		MergerLeaderboard:                       mergerBoard,
+		ApproverLeaderboard:                     approverBoard,
+		ChangesRequesterLeaderboard:             changesRequesterBoard,
+		TopReviewer:                             leader(reviewerBoard),
+		TopCommenter:                            leader(commenterBoard),
+		TopCreator:                              leader(creatorBoard),
+		TopFirstHumanResponder:                  leader(firstHumanResponderBoard),
+		TopFirstResponder:                       leader(firstResponderBoard),
+		TopMerger:                               leader(mergerBoard),
+		TopApprover:                             leader(approverBoard),
+		TopChangesRequester:                     leader(changesRequesterBoard),
+		SelfMergeRate:                           selfMergeRate(prInfos),
+		CrossTeamReviewShare:                    crossTeamShare,
+		CrossTeamReviews:                        crossTeam,
+		Mentorships:                             mentorships(prInfos, opts.Mentees),
+		ReviewKarma:                             reviewKarma(prInfos, opts.Karma, opts.LeaderboardSize),
+		ApprovedReviews:                         approved,
+		ChangesRequestedReviews:                 changesRequestedReviews,
+		PRsWithChangesRequested:                 changesRequestedPRs,
+		ChangesRequestedRate:                    changesRequestedRate,
+		AverageChangesRequested:                 averageChangesRequested,
+		CommentedReviews:                        commented,
+		MergeTimeHistogram:                      mergeTimeHistogram(prInfos, opts.HistogramBuckets),
+		TypeSegments:                            segmentBy(averaged, func(pr PRInfo) []string { return []string{pr.Type} }),
+		LabelSegments:                           segmentBy(averaged, func(pr PRInfo) []string { return pr.Labels }),
+		MilestoneSegments:                       segmentBy(averaged, milestoneKey),
+		PathSegments:                            pathSegments,
+		SizeBuckets:                             sizeBucketMedians(sized),
+		RevertRate:                              revertRate,
+		HotfixRate:                              hotfixRate,
+		ChangeKindSegments:                      segmentBy(averaged, changeKindKey),
+		ReviewerLoad:                            load,
+		ReviewerLoadGini:                        loadGini,
+		BusFactors:                              busFactorPerPath(prInfos, opts.BusFactorDepth),
+		SLA:                                     opts.SLA,
+		SLABreaches:                             breaches,
+		SLACompliance:                           compliance,
+		SLAComplianceByLabel:                    slaComplianceByLabel(prInfos, opts.SLA),
+		IdleGapThreshold:                        opts.IdleGap,
+		IdleGaps:                                idleGaps(knownPRs(prInfos, unknownTimeline, unknownReviews, unknownComments, unknownReviewComments), opts.IdleGap),
+		Outliers:                                outliers,
+		OutliersExcluded:                        opts.ExcludeOutliers,
+		WeekendsSkipped:                         opts.SkipWeekends,
+		LocalTimes:                              opts.LocalTimes,
+		CustomMetrics:                           customMetrics(prInfos, opts.Metrics),
+	}
+}
+
+func customMetrics(prInfos []PRInfo, metrics []metricExpr) []CustomMetric {
+	var results []CustomMetric
+	for _, m := range metrics {
+		results = append(results, m.evaluate(prInfos))
+	}
+	return results
+}
+
+func milestoneKey(pr PRInfo) []string {
+	if pr.Milestone == "" {
+		return nil
+	}
+	return []string{pr.Milestone}
+}
+
+// matchingPrefixes returns the prefixes at least one of the files starts with.
+func matchingPrefixes(files []string, prefixes []string) []string {
+	var matching []string
+	for _, prefix := range prefixes {
+		for _, file := range files {
+			if strings.HasPrefix(file, prefix) {
+				matching = append(matching, prefix)
+				break
+			}
+		}
+	}
+	return matching
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+// reportFixture has a PR of alice reviewed and merged by bob in a day, and a
+// PR of carol going back and forth with alice before carol merges it after
+// 12 hours. Both are of the first quarter of 2024.
+func reportFixture(t *testing.T) *Fixture {
+	t.Helper()
+	return newFixture(t,
+		testPR{
+			number:   1,
+			merger:   "bob",
+			created:  "2024-01-08T09:00:00Z",
+			merged:   "2024-01-09T09:00:00Z",
+			comments: []testEvent{{login: "bob", at: "2024-01-08T10:00:00Z"}},
+			reviews:  []testEvent{{login: "bob", state: "APPROVED", at: "2024-01-08T12:00:00Z"}},
+			lines:    10,
+		},
+		testPR{
+			number:  2,
+			creator: "carol",
+			merger:  "carol",
+			created: "2024-01-10T09:00:00Z",
+			merged:  "2024-01-10T21:00:00Z",
+			comments: []testEvent{
+				{login: "dependabot[bot]", at: "2024-01-10T09:30:00Z"},
+				{login: "alice", at: "2024-01-10T13:00:00Z"},
+			},
+			reviews: []testEvent{
+				{login: "alice", state: "CHANGES_REQUESTED", at: "2024-01-10T13:00:00Z"},
+				{login: "alice", state: "APPROVED", at: "2024-01-10T17:00:00Z"},
+			},
+			pushes: []string{"2024-01-10T15:00:00Z"},
+			lines:  200,
+		},
+	)
+}
+
+// defaultReportOptions are the report options of the default flags.
+var defaultReportOptions = reportOptions{HistogramBuckets: defaultHistogramBuckets, OutlierMethod: "stddev"}
+
+func TestNewReport(t *testing.T) {
+	prInfos := collect(t, reportFixture(t), collectOptions{})
+	r := newReport("owner", "repo", 2024, "Q1", prInfos, defaultReportOptions)
+
+	for _, tc := range []struct {
+		name string
+		got  any
+		want any
+	}{
+		{"PRs", len(r.PRs), 2},
+		{"AverageMergeTime", r.AverageMergeTime, 18 * time.Hour},
+		{"AverageTimeToFirstHumanResponse", r.AverageTimeToFirstHumanResponse, 150 * time.Minute},
+		{"SelfMergeRate", r.SelfMergeRate, 0.5},
+		{"AverageNumberOfComments", r.AverageNumberOfComments, 1.0},
+		{"AverageNumberOfReviewers", r.AverageNumberOfReviewers, 1.0},
+		{"AverageNumberOfReviews", r.AverageNumberOfReviews, 1.5},
+		{"ApprovedReviews", r.ApprovedReviews, 2},
+		{"ChangesRequestedReviews", r.ChangesRequestedReviews, 1},
+		{"PRsWithChangesRequested", r.PRsWithChangesRequested, 1},
+		{"AveragePushesAfterFirstReview", r.AveragePushesAfterFirstReview, 0.5},
+		{"AverageReviewRounds", r.AverageReviewRounds, 1.5},
+		{"PingPongPRs", len(r.PingPongPRs), 1},
+		{"TotalWaitingOnAuthors", r.TotalWaitingOnAuthors, 2 * time.Hour},
+		{"TotalWaitingOnReviewers", r.TotalWaitingOnReviewers, 9 * time.Hour},
+		{"TopReviewer", r.TopReviewer, "alice"},
+		{"TopMerger", r.TopMerger, "bob"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.got != tc.want {
+				t.Errorf("got %v, want %v", tc.got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewReportWithoutPRs(t *testing.T) {
+	r := newReport("owner", "repo", 2024, "Q1", nil, defaultReportOptions)
+	if r.AverageMergeTime != 0 || r.AverageTimeToFirstHumanResponse != 0 || r.SelfMergeRate != 0 || r.AverageReviewRounds != 0 {
+		t.Errorf("got the averages %v, %v, %v and %v over no PRs, want zeros", r.AverageMergeTime, r.AverageTimeToFirstHumanResponse, r.SelfMergeRate, r.AverageReviewRounds)
+	}
+	for _, bucket := range r.MergeTimeHistogram {
+		if bucket.Count != 0 {
+			t.Errorf("got %d PRs in the bucket %s, want none", bucket.Count, bucket.Label)
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// retentionHorizon is how many quarters after its cohort quarter the
+// retention of contributors is followed.
+const retentionHorizon = 2
+
+// CohortRetention tells how many of the contributors active in a quarter
+// were still active in the following quarters.
+type CohortRetention struct {
+	Year         int
+	Quarter      string
+	Contributors int
+	// Retained[i] is the share of the contributors still active i+1
+	// quarters later, nil when that quarter is not covered by the data.
+	Retained []*float64
+}
+
+// quarterIndex numbers the quarters consecutively.
+func quarterIndex(year int, quarter string) int {
+	return year*4 + int(quarter[1]-'1')
+}
+
+// contributors returns the humans who created, merged, reviewed or commented
+// on the PR.
+func contributors(pr PRInfo) []string {
+	people := append([]string{pr.Creator, pr.Merger}, pr.Reviewers...)
+	people = append(people, pr.Commenters...)
+
+	var humans []string
+	for _, person := range people {
+		if person != "" && !strings.HasSuffix(person, "[bot]") {
+			humans = append(humans, person)
+		}
+	}
+	return humans
+}
+
+// contributorRetention builds one cohort per quarter of the PRs, made of the
+// contributors active in that quarter.
+func contributorRetention(prData []PRInfo) []CohortRetention {
+	active := make(map[int]map[string]bool)
+	for _, pr := range prData {
+		q := quarterIndex(pr.Year, pr.Quarter)
+		if active[q] == nil {
+			active[q] = make(map[string]bool)
+		}
+		for _, person := range contributors(pr) {
+			active[q][person] = true
+		}
+	}
+
+	var quarters []int
+	last := 0
+	for q := range active {
+		quarters = append(quarters, q)
+		if q > last {
+			last = q
+		}
+	}
+	sort.Ints(quarters)
+
+	var cohorts []CohortRetention
+	for _, q := range quarters {
+		cohort := CohortRetention{Year: q / 4, Quarter: fmt.Sprintf("Q%d", q%4+1), Contributors: len(active[q])}
+		for i := 1; i <= retentionHorizon; i++ {
+			if q+i > last {
+				cohort.Retained = append(cohort.Retained, nil)
+				continue
+			}
+			retained := 0
+			for person := range active[q] {
+				if active[q+i][person] {
+					retained++
+				}
+			}
+			share := float64(retained) / float64(len(active[q]))
+			cohort.Retained = append(cohort.Retained, &share)
+		}
+		cohorts = append(cohorts, cohort)
+	}
+	return cohorts
+}
+
+func printRetention(w io.Writer, cohorts []CohortRetention) {
+	fmt.Fprintln(w, "Contributor retention:")
+	header := fmt.Sprintf("  %-8s %12s", "Cohort", "Contributors")
+	for i := 1; i <= retentionHorizon; i++ {
+		header += fmt.Sprintf(" %7s", fmt.Sprintf("N+%d", i))
+	}
+	fmt.Fprintln(w, header)
+
+	for _, cohort := range cohorts {
+		line := fmt.Sprintf("  %-8s %12d", fmt.Sprintf("%d-%s", cohort.Year, cohort.Quarter), cohort.Contributors)
+		for _, retained := range cohort.Retained {
+			if retained == nil {
+				line += fmt.Sprintf(" %7s", "-")
+				continue
+			}
+			line += fmt.Sprintf(" %6.1f%%", *retained*100)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
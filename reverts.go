@@ -0,0 +1,64 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// isRevert reports whether the PR reverts earlier work, judging by the title
+// GitHub gives revert PRs or by one of its commits being a revert commit.
+func isRevert(title string, commits []*github.RepositoryCommit) bool {
+	if strings.HasPrefix(strings.ToLower(title), "revert") {
+		return true
+	}
+	for _, commit := range commits {
+		message := commit.GetCommit().GetMessage()
+		if strings.HasPrefix(message, "Revert \"") || strings.Contains(message, "This reverts commit ") {
+			return true
+		}
+	}
+	return false
+}
+
+// isHotfix reports whether the PR carries the hotfix label.
+func isHotfix(labels []string, hotfixLabel string) bool {
+	for _, label := range labels {
+		if strings.EqualFold(label, hotfixLabel) {
+			return true
+		}
+	}
+	return false
+}
+
+// changeKindKey puts each PR into the revert, hotfix or regular segment.
+func changeKindKey(pr PRInfo) []string {
+	var kinds []string
+	if pr.IsRevert {
+		kinds = append(kinds, "revert")
+	}
+	if pr.IsHotfix {
+		kinds = append(kinds, "hotfix")
+	}
+	if len(kinds) == 0 {
+		kinds = append(kinds, "regular")
+	}
+	return kinds
+}
+
+func revertAndHotfixRates(prData []PRInfo) (revertRate float64, hotfixRate float64) {
+	if len(prData) == 0 {
+		return 0, 0
+	}
+
+	var reverts, hotfixes int
+	for _, pr := range prData {
+		if pr.IsRevert {
+			reverts++
+		}
+		if pr.IsHotfix {
+			hotfixes++
+		}
+	}
+	return float64(reverts) / float64(len(prData)), float64(hotfixes) / float64(len(prData))
+}
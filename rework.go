@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// firstReviewTime returns when the first human review of the PR was
+// submitted, false when nobody reviewed it. The creator reviewing their own
+// PR does not count.
+func firstReviewTime(creator string, reviews []*github.PullRequestReview) (time.Time, bool) {
+	var first time.Time
+	for _, review := range reviews {
+		if login := review.GetUser().GetLogin(); strings.HasSuffix(login, "[bot]") || login == creator || review.SubmittedAt == nil {
+			continue
+		}
+		if first.IsZero() || review.SubmittedAt.Before(first) {
+			first = *review.SubmittedAt
+		}
+	}
+	return first, !first.IsZero()
+}
+
+// pushTimes returns when the PR was pushed to: the committer dates of its
+// commits, which rebasing and amending refresh, and the force pushes of its
+// timeline. The timeline has no time for the commits pushed normally.
+func pushTimes(commits []*github.RepositoryCommit, timeline []*github.Timeline) []time.Time {
+	var pushes []time.Time
+	for _, commit := range commits {
+		if date := commit.GetCommit().GetCommitter().GetDate(); !date.IsZero() {
+			pushes = append(pushes, date)
+		}
+	}
+	for _, event := range timeline {
+		if event.GetEvent() == "head_ref_force_pushed" && event.CreatedAt != nil {
+			pushes = append(pushes, event.GetCreatedAt())
+		}
+	}
+	return pushes
+}
+
+// pushesAfter counts the pushes to the PR after the given time, as a proxy
+// for the rework asked for by the reviewers.
+func pushesAfter(since time.Time, pushes []time.Time) int {
+	count := 0
+	for _, push := range pushes {
+		if push.After(since) {
+			count++
+		}
+	}
+	return count
+}
+
+// averagePushesAfterFirstReview returns the average number of pushes after
+// the first review among the reviewed PRs.
+func averagePushesAfterFirstReview(prData []PRInfo) float64 {
+	var pushes, reviewed int
+	for _, pr := range prData {
+		if len(pr.Reviewers) == 0 {
+			continue
+		}
+		pushes += pr.PushesAfterFirstReview
+		reviewed++
+	}
+	if reviewed == 0 {
+		return 0
+	}
+	return float64(pushes) / float64(reviewed)
+}
+
+// reviewRounds counts the rounds of review of the PR: the human reviews of
+// anyone but the creator and the pushes to the PR are interleaved in the
+// order they happened, and every review following a push, or the first one,
+// starts a new round.
+func reviewRounds(creator string, reviews []*github.PullRequestReview, pushes []time.Time) int {
+	type event struct {
+		at     time.Time
+		review bool
+	}
+	var events []event
+	for _, review := range reviews {
+		login := review.GetUser().GetLogin()
+		if strings.HasSuffix(login, "[bot]") || login == creator || review.SubmittedAt == nil {
+			continue
+		}
+		events = append(events, event{at: review.GetSubmittedAt(), review: true})
+	}
+	for _, push := range pushes {
+		events = append(events, event{at: push})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+
+	rounds := 0
+	pushed := true
+	for _, e := range events {
+		switch {
+		case !e.review:
+			pushed = true
+		case pushed:
+			rounds++
+			pushed = false
+		}
+	}
+	return rounds
+}
+
+// maxPingPongPRs is the number of PRs with the most review rounds reported.
+const maxPingPongPRs = 5
+
+// PingPongPR is a PR that went back and forth between its author and the
+// reviewers.
+type PingPongPR struct {
+	Number   int
+	Title    string
+	Rounds   int
+	Duration time.Duration
+}
+
+// reviewRoundStats returns the average number of review rounds among the
+// reviewed PRs and the PRs with the most rounds, the longest running first
+// among the PRs with as many.
+func reviewRoundStats(prData []PRInfo) (average float64, pingPong []PingPongPR) {
+	var rounds, reviewed int
+	for _, pr := range prData {
+		if pr.ReviewRounds == 0 {
+			continue
+		}
+		rounds += pr.ReviewRounds
+		reviewed++
+		if pr.ReviewRounds > 1 {
+			pingPong = append(pingPong, PingPongPR{Number: pr.Number, Title: pr.Title, Rounds: pr.ReviewRounds, Duration: pr.Duration})
+		}
+	}
+	if reviewed == 0 {
+		return 0, nil
+	}
+	sort.Slice(pingPong, func(i, j int) bool {
+		if pingPong[i].Rounds != pingPong[j].Rounds {
+			return pingPong[i].Rounds > pingPong[j].Rounds
+		}
+		return pingPong[i].Duration > pingPong[j].Duration
+	})
+	if len(pingPong) > maxPingPongPRs {
+		pingPong = pingPong[:maxPingPongPRs]
+	}
+	return float64(rounds) / float64(reviewed), pingPong
+}
+
+func printReviewRounds(w io.Writer, average float64, pingPong []PingPongPR) {
+	fmt.Fprintf(w, "Average review rounds per reviewed PR: %.2f\n", average)
+	if len(pingPong) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Most review rounds:")
+	for _, pr := range pingPong {
+		fmt.Fprintf(w, "  #%d %s: %d rounds, merged after %s\n", pr.Number, pr.Title, pr.Rounds, formatDuration(pr.Duration))
+	}
+}
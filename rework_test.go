@@ -0,0 +1,92 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestRework(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		pr              testPR
+		wantFirstReview string
+		wantPushes      int
+		wantRounds      int
+	}{
+		{
+			name: "not reviewed",
+			pr:   testPR{pushes: []string{"2024-01-08T10:00:00Z"}},
+		},
+		{
+			name: "approved right away",
+			pr: testPR{
+				reviews: []testEvent{{login: "bob", state: "APPROVED", at: "2024-01-08T11:00:00Z"}},
+				pushes:  []string{"2024-01-08T09:00:00Z"},
+			},
+			wantFirstReview: "2024-01-08T11:00:00Z",
+			wantRounds:      1,
+		},
+		{
+			name: "reworked twice",
+			pr: testPR{
+				reviews: []testEvent{
+					{login: "bob", state: "CHANGES_REQUESTED", at: "2024-01-08T10:00:00Z"},
+					{login: "carol", state: "COMMENTED", at: "2024-01-08T10:30:00Z"},
+					{login: "bob", state: "CHANGES_REQUESTED", at: "2024-01-08T12:00:00Z"},
+					{login: "bob", state: "APPROVED", at: "2024-01-08T14:00:00Z"},
+				},
+				pushes: []string{"2024-01-08T11:00:00Z", "2024-01-08T13:00:00Z"},
+			},
+			wantFirstReview: "2024-01-08T10:00:00Z",
+			wantPushes:      2,
+			wantRounds:      3,
+		},
+		{
+			name: "reviews of the creator and bots do not count",
+			pr: testPR{
+				reviews: []testEvent{
+					{login: "alice", state: "COMMENTED", at: "2024-01-08T10:00:00Z"},
+					{login: "linter[bot]", state: "COMMENTED", at: "2024-01-08T10:30:00Z"},
+					{login: "bob", state: "APPROVED", at: "2024-01-08T12:00:00Z"},
+				},
+				pushes: []string{"2024-01-08T11:00:00Z"},
+			},
+			wantFirstReview: "2024-01-08T12:00:00Z",
+			wantRounds:      1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.pr.number, tc.pr.created, tc.pr.merged = 1, "2024-01-08T09:00:00Z", "2024-01-09T09:00:00Z"
+			pr := collect(t, newFixture(t, tc.pr), collectOptions{})[0]
+
+			if tc.wantFirstReview == "" {
+				if !pr.FirstReviewAt.IsZero() {
+					t.Errorf("got the first review at %v, want none", pr.FirstReviewAt)
+				}
+			} else if want := parseTime(t, tc.wantFirstReview); !pr.FirstReviewAt.Equal(want) {
+				t.Errorf("got the first review at %v, want %v", pr.FirstReviewAt, want)
+			}
+			if pr.PushesAfterFirstReview != tc.wantPushes {
+				t.Errorf("got %d pushes after the first review, want %d", pr.PushesAfterFirstReview, tc.wantPushes)
+			}
+			if pr.ReviewRounds != tc.wantRounds {
+				t.Errorf("got %d review rounds, want %d", pr.ReviewRounds, tc.wantRounds)
+			}
+		})
+	}
+}
+
+func TestReviewRoundStats(t *testing.T) {
+	prData := []PRInfo{
+		{Number: 1, ReviewRounds: 1},
+		{Number: 2, ReviewRounds: 3},
+		{Number: 3},
+		{Number: 4, ReviewRounds: 2},
+	}
+	average, pingPong := reviewRoundStats(prData)
+	if average != 2 {
+		t.Errorf("got an average of %v review rounds, want 2", average)
+	}
+	if len(pingPong) != 2 || pingPong[0].Number != 2 || pingPong[1].Number != 4 {
+		t.Errorf("got the ping-pong PRs %v, want #2 and #4", pingPong)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+	"github.com/yuin/gopher-lua/parse"
+)
+
+// CustomMetric is a metric computed by the --script.
+type CustomMetric struct {
+	Name  string
+	Value string
+}
+
+// metricsScript is a Lua script computing metrics of its own for every
+// report. The script may define two functions:
+//
+//	function on_pr(pr)
+//	  -- called with every PR of the report
+//	end
+//
+//	function on_report(report)
+//	  -- called with the aggregates of the report once all the PRs were
+//	  -- passed to on_pr, returning a table of metric names to values and
+//	  -- optionally the text of a section of its own
+//	  return {["Docs PRs"] = docs}, "text of the section"
+//	end
+//
+// The PRs and the aggregates are tables with the fields of PRInfo and
+// Report. Durations are in seconds and times in seconds since the epoch.
+// The script runs from scratch for every report, so its globals only hold
+// the PRs of that report.
+type metricsScript struct {
+	path  string
+	proto *lua.FunctionProto
+}
+
+func loadMetricsScript(path string) (*metricsScript, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	chunk, err := parse.Parse(file, path)
+	if err != nil {
+		return nil, err
+	}
+	proto, err := lua.Compile(chunk, path)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsScript{path: path, proto: proto}, nil
+}
+
+// run computes the custom metrics and section of the report. Only the base,
+// table, string and math libraries are available, so scripts can neither
+// touch files nor run commands.
+func (s *metricsScript) run(r Report) ([]CustomMetric, string, error) {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	defer L.Close()
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+
+	L.Push(L.NewFunctionFromProto(s.proto))
+	if err := L.PCall(0, 0, nil); err != nil {
+		return nil, "", err
+	}
+
+	if onPR, ok := L.GetGlobal("on_pr").(*lua.LFunction); ok {
+		for _, pr := range r.PRs {
+			if err := L.CallByParam(lua.P{Fn: onPR, Protect: true}, luaValue(L, reflect.ValueOf(pr))); err != nil {
+				return nil, "", fmt.Errorf("on_pr of PR #%d: %w", pr.Number, err)
+			}
+		}
+	}
+
+	onReport, ok := L.GetGlobal("on_report").(*lua.LFunction)
+	if !ok {
+		return nil, "", nil
+	}
+	aggregates := r
+	aggregates.PRs = nil
+	if err := L.CallByParam(lua.P{Fn: onReport, NRet: 2, Protect: true}, luaValue(L, reflect.ValueOf(aggregates))); err != nil {
+		return nil, "", fmt.Errorf("on_report: %w", err)
+	}
+	metricsValue, sectionValue := L.Get(-2), L.Get(-1)
+	L.Pop(2)
+
+	var metrics []CustomMetric
+	if table, ok := metricsValue.(*lua.LTable); ok {
+		table.ForEach(func(name lua.LValue, value lua.LValue) {
+			metrics = append(metrics, CustomMetric{Name: name.String(), Value: luaString(value)})
+		})
+	} else if metricsValue != lua.LNil {
+		return nil, "", fmt.Errorf("on_report returned a %s instead of a table of metrics", metricsValue.Type())
+	}
+	sort.Slice(metrics, func(i, j int) bool { return metrics[i].Name < metrics[j].Name })
+
+	section := ""
+	if sectionValue != lua.LNil {
+		section = sectionValue.String()
+	}
+	return metrics, section, nil
+}
+
+// luaString writes the numbers returned by the scripts without a trailing
+// .0 or an exponent.
+func luaString(value lua.LValue) string {
+	if number, ok := value.(lua.LNumber); ok {
+		return strconv.FormatFloat(float64(number), 'f', -1, 64)
+	}
+	return value.String()
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// luaValue converts a value of the reports to Lua: structs and maps become
+// tables keyed by the field names and the keys, slices become arrays.
+func luaValue(L *lua.LState, v reflect.Value) lua.LValue {
+	switch v.Type() {
+	case durationType:
+		return lua.LNumber(time.Duration(v.Int()).Seconds())
+	case timeType:
+		t := v.Interface().(time.Time)
+		if t.IsZero() {
+			return lua.LNil
+		}
+		return lua.LNumber(t.Unix())
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return lua.LBool(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return lua.LNumber(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return lua.LNumber(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return lua.LNumber(v.Float())
+	case reflect.String:
+		return lua.LString(v.String())
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return lua.LNil
+		}
+		return luaValue(L, v.Elem())
+	case reflect.Slice, reflect.Array:
+		table := L.CreateTable(v.Len(), 0)
+		for i := 0; i < v.Len(); i++ {
+			table.Append(luaValue(L, v.Index(i)))
+		}
+		return table
+	case reflect.Map:
+		table := L.CreateTable(0, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			table.RawSet(luaValue(L, iter.Key()), luaValue(L, iter.Value()))
+		}
+		return table
+	case reflect.Struct:
+		table := L.CreateTable(0, v.NumField())
+		for i := 0; i < v.NumField(); i++ {
+			if field := v.Type().Field(i); field.IsExported() {
+				table.RawSetString(field.Name, luaValue(L, v.Field(i)))
+			}
+		}
+		return table
+	default:
+		return lua.LNil
+	}
+}
+
+func printCustomMetrics(w io.Writer, metrics []CustomMetric, section string) {
+	if len(metrics) > 0 {
+		fmt.Fprintln(w, "Custom metrics:")
+		for _, metric := range metrics {
+			fmt.Fprintf(w, "  %s: %s\n", metric.Name, metric.Value)
+		}
+	}
+	if section = strings.TrimRight(section, "\n"); section != "" {
+		fmt.Fprintln(w, section)
+	}
+}
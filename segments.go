@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// noSegment is the segment of the PRs without a key, e.g. without labels.
+const noSegment = "(none)"
+
+// Segment holds the aggregates of the PRs that share a key such as a label.
+type Segment struct {
+	Name                            string
+	PRs                             int
+	AverageMergeTime                time.Duration
+	AverageTimeToFirstResponse      time.Duration
+	AverageTimeToFirstHumanResponse time.Duration
+	AverageNumberOfComments         float64
+	AverageNumberOfReviewers        float64
+	AverageNumberOfCommits          float64
+}
+
+// segmentBy groups the PRs by the keys returned for each of them and
+// aggregates every group. A PR with several keys counts towards each of them,
+// a PR without keys towards the noSegment group.
+func segmentBy(prData []PRInfo, keys func(PRInfo) []string) []Segment {
+	groups := make(map[string][]PRInfo)
+	for _, pr := range prData {
+		prKeys := keys(pr)
+		if len(prKeys) == 0 {
+			prKeys = []string{noSegment}
+		}
+		for _, key := range prKeys {
+			groups[key] = append(groups[key], pr)
+		}
+	}
+
+	segments := make([]Segment, 0, len(groups))
+	for name, prs := range groups {
+		segments = append(segments, Segment{
+			Name:                            name,
+			PRs:                             len(prs),
+			AverageMergeTime:                averageMergeTime(prs),
+			AverageTimeToFirstResponse:      averageTimeToFirstBotResponse(prs),
+			AverageTimeToFirstHumanResponse: averageFirstReponseHumanTime(prs),
+			AverageNumberOfComments:         averageNumberOfComments(prs),
+			AverageNumberOfReviewers:        averageNumberOfReviewers(prs),
+			AverageNumberOfCommits:          averageNumberOfCommits(prs),
+		})
+	}
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].Name < segments[j].Name
+	})
+	return segments
+}
+
+func printSegments(w io.Writer, title string, segments []Segment) {
+	fmt.Fprintf(w, "%s:\n", title)
+	for _, segment := range segments {
+		fmt.Fprintf(w, "  %s: %d PRs, average merge time %s, average time to first response %s, average time to first human response %s, %.1f comments, %.1f reviewers and %.1f commits per PR\n",
+			segment.Name, segment.PRs, formatDuration(segment.AverageMergeTime), formatDuration(segment.AverageTimeToFirstResponse), formatDuration(segment.AverageTimeToFirstHumanResponse),
+			segment.AverageNumberOfComments, segment.AverageNumberOfReviewers, segment.AverageNumberOfCommits)
+	}
+}
@@ -0,0 +1,278 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// The repository time2review itself is released from.
+const (
+	releaseOwner = "drpaneas"
+	releaseRepo  = "time2review"
+)
+
+// version is the release the binary was built from, and releaseKey the
+// base64 ed25519 public key its releases are signed with: checksums.txt.sig
+// is the signature of the tag of the release, a newline and checksums.txt.
+// Both are set when building a release with
+// -ldflags "-X main.version=v1.2.3 -X main.releaseKey=...".
+var (
+	version    = "dev"
+	releaseKey = ""
+)
+
+// releaseAsset is the name of the binary of the platform in the releases,
+// such as time2review_linux_amd64.
+func releaseAsset(goos string, goarch string) string {
+	name := fmt.Sprintf("time2review_%s_%s", goos, goarch)
+	if goos == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// parseVersion parses a version such as v1.2.3 into its numbers.
+// Pre-releases such as v1.2.3-rc.1 count as their release.
+func parseVersion(v string) ([3]int, bool) {
+	var numbers [3]int
+	release, _, _ := strings.Cut(strings.TrimPrefix(v, "v"), "-")
+	parts := strings.Split(release, ".")
+	if len(parts) != 3 {
+		return numbers, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return numbers, false
+		}
+		numbers[i] = n
+	}
+	return numbers, true
+}
+
+// newerVersion reports whether the latest version is newer than the current
+// one. Either not being a version such as v1.2.3 is an error, a build of
+// unknown version is not assumed to be older than any release.
+func newerVersion(latest string, current string) (bool, error) {
+	l, ok := parseVersion(latest)
+	if !ok {
+		return false, fmt.Errorf("the latest release %q is not a version such as v1.2.3", latest)
+	}
+	c, ok := parseVersion(current)
+	if !ok {
+		return false, fmt.Errorf("this build of version %q is not a release, build it again from the latest sources instead", current)
+	}
+	for i := range l {
+		if l[i] != c[i] {
+			return l[i] > c[i], nil
+		}
+	}
+	return false, nil
+}
+
+// latestRelease returns the most recent published release that is not a
+// pre-release.
+func latestRelease(ctx context.Context, client GitHubClient) (*github.RepositoryRelease, error) {
+	releases, err := listAll(func(listOpts github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+		return client.ListReleases(ctx, releaseOwner, releaseRepo, &listOpts)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("fetching the releases of %s/%s: %w", releaseOwner, releaseRepo, err)
+	}
+	var latest *github.RepositoryRelease
+	for _, release := range releases {
+		if release.GetDraft() || release.GetPrerelease() || release.PublishedAt == nil {
+			continue
+		}
+		if latest == nil || release.GetPublishedAt().After(latest.GetPublishedAt().Time) {
+			latest = release
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("%s/%s has no published release", releaseOwner, releaseRepo)
+	}
+	return latest, nil
+}
+
+// signedChecksums returns what the signature of a release covers: its tag on
+// a line of its own followed by its checksums file. Signing the tag too keeps
+// an older release from being passed off as a newer one.
+func signedChecksums(tag string, checksums []byte) []byte {
+	return append([]byte(tag+"\n"), checksums...)
+}
+
+// verifyChecksums verifies the signature of the checksums file of the release
+// of the tag with the base64 ed25519 public key.
+func verifyChecksums(tag string, checksums []byte, signature []byte, key string) error {
+	publicKey, err := base64.StdEncoding.DecodeString(key)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return errors.New("invalid release signing key")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return fmt.Errorf("decoding the signature of checksums.txt: %w", err)
+	}
+	if !ed25519.Verify(publicKey, signedChecksums(tag, checksums), sig) {
+		return fmt.Errorf("the signature of checksums.txt does not match the release signing key and %s", tag)
+	}
+	return nil
+}
+
+// checksumOf returns the SHA-256 checksum of the file in the checksums file,
+// which lists a "<sha256>  <file>" line for every asset of the release.
+func checksumOf(checksums []byte, file string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(checksums))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == file {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("checksums.txt has no checksum of %s", file)
+}
+
+// download returns the content of the release asset.
+func download(ctx context.Context, client *http.Client, asset *github.ReleaseAsset) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, asset.GetBrowserDownloadURL(), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading %s returned %s", asset.GetName(), resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// replaceExecutable replaces the running executable with the binary. The new
+// binary is written next to it first, so that the executable is replaced in
+// one rename and never left half written.
+func replaceExecutable(binary []byte) error {
+	path, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	if path, err = filepath.EvalSymlinks(path); err != nil {
+		return err
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".time2review-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(binary); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), info.Mode().Perm()|0o111); err != nil {
+		return err
+	}
+
+	// Windows does not replace a running executable, but renames it, and
+	// renames it back when the new binary cannot take its place
+	if runtime.GOOS == "windows" {
+		old := path + ".old"
+		os.Remove(old)
+		if err := os.Rename(path, old); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			if restoreErr := os.Rename(old, path); restoreErr != nil {
+				return fmt.Errorf("%w, and restoring %s from %s failed: %w", err, path, old, restoreErr)
+			}
+			return err
+		}
+		return nil
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// selfUpdate replaces the running executable with the binary of the latest
+// release when it is newer than the current version, once the binary matches
+// the checksum of the release and the checksums match their signature. It
+// returns the version it updated to, empty when already up to date.
+func selfUpdate(ctx context.Context, client GitHubClient, current string) (string, error) {
+	if releaseKey == "" {
+		return "", errors.New("this build has no release signing key to verify the releases with, build it again from the latest sources instead")
+	}
+	release, err := latestRelease(ctx, client)
+	if err != nil {
+		return "", err
+	}
+	newer, err := newerVersion(release.GetTagName(), current)
+	if err != nil {
+		return "", err
+	}
+	if !newer {
+		return "", nil
+	}
+
+	name := releaseAsset(runtime.GOOS, runtime.GOARCH)
+	assets := make(map[string]*github.ReleaseAsset)
+	for _, asset := range release.Assets {
+		assets[asset.GetName()] = asset
+	}
+	for _, file := range []string{name, "checksums.txt", "checksums.txt.sig"} {
+		if assets[file] == nil {
+			return "", fmt.Errorf("release %s has no %s", release.GetTagName(), file)
+		}
+	}
+
+	httpClient := &http.Client{Timeout: 5 * time.Minute}
+	checksums, err := download(ctx, httpClient, assets["checksums.txt"])
+	if err != nil {
+		return "", err
+	}
+	signature, err := download(ctx, httpClient, assets["checksums.txt.sig"])
+	if err != nil {
+		return "", err
+	}
+	if err := verifyChecksums(release.GetTagName(), checksums, signature, releaseKey); err != nil {
+		return "", err
+	}
+	want, err := checksumOf(checksums, name)
+	if err != nil {
+		return "", err
+	}
+	binary, err := download(ctx, httpClient, assets[name])
+	if err != nil {
+		return "", err
+	}
+	if sum := sha256.Sum256(binary); hex.EncodeToString(sum[:]) != strings.ToLower(want) {
+		return "", fmt.Errorf("the checksum of %s does not match checksums.txt", name)
+	}
+
+	if err := replaceExecutable(binary); err != nil {
+		return "", fmt.Errorf("replacing the executable: %w", err)
+	}
+	return release.GetTagName(), nil
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestParseVersion(t *testing.T) {
+	for _, tc := range []struct {
+		version string
+		want    [3]int
+		wantOK  bool
+	}{
+		{version: "v1.2.3", want: [3]int{1, 2, 3}, wantOK: true},
+		{version: "1.2.3", want: [3]int{1, 2, 3}, wantOK: true},
+		{version: "v1.2.3-rc.1", want: [3]int{1, 2, 3}, wantOK: true},
+		{version: "v10.0.12-beta", want: [3]int{10, 0, 12}, wantOK: true},
+		{version: "dev"},
+		{version: "v1.2"},
+		{version: "v1.2.3.4"},
+		{version: "v1.x.3"},
+		{version: "v1.-2.3"},
+		{version: ""},
+	} {
+		t.Run(tc.version, func(t *testing.T) {
+			got, ok := parseVersion(tc.version)
+			if ok != tc.wantOK || (ok && got != tc.want) {
+				t.Errorf("got %v (ok: %t), want %v (ok: %t)", got, ok, tc.want, tc.wantOK)
+			}
+		})
+	}
+}
+
+func TestNewerVersion(t *testing.T) {
+	for _, tc := range []struct {
+		latest  string
+		current string
+		want    bool
+		wantErr bool
+	}{
+		{latest: "v1.3.0", current: "v1.2.3", want: true},
+		{latest: "v1.2.3", current: "v1.2.3"},
+		{latest: "v1.2.3", current: "v1.3.0"},
+		{latest: "v1.2.3", current: "v1.2.3-rc.1"},
+		{latest: "v1.2.2", current: "v1.2.3-rc.1"},
+		{latest: "v1.2.4", current: "v1.2.3-rc.1", want: true},
+		{latest: "v1.2.3", current: "1.2.3"},
+		{latest: "v1.2.3", current: "dev", wantErr: true},
+		{latest: "v1.2.3", current: "garbage-1.2", wantErr: true},
+		{latest: "nightly", current: "v1.2.3", wantErr: true},
+	} {
+		t.Run(tc.latest+" over "+tc.current, func(t *testing.T) {
+			got, err := newerVersion(tc.latest, tc.current)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got the error %v, want an error: %t", err, tc.wantErr)
+			}
+			if got != tc.want {
+				t.Errorf("got %t, want %t", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reportServer serves the metrics of the latest reports over HTTP while the
+// program runs in server mode (--listen), as badges, feeds, a JSON API, the
+// dashboard drawn from it, a GraphQL API and the pages of the teams, along
+// with the pprof profiles of the program.
+type reportServer struct {
+	sla SLA
+
+	mu sync.RWMutex
+	// latest is the report of the most recent quarter of each repository,
+	// by "owner/repo".
+	latest map[string]Report
+	// history are the PRs of the latest run of each repository, by
+	// "owner/repo".
+	history map[string][]PRInfo
+
+	// groups are the repositories of the groups reported, by name.
+	groups map[string][]repoName
+
+	// reportOpts and teamViews are what the team pages are reported with.
+	reportOpts reportOptions
+	teamViews  map[string]TeamView
+
+	// login is only set when the users log in with GitHub.
+	login *githubLogin
+	// cache is only set when the responses are cached.
+	cache *responseCache
+
+	// subscribers are sent the reports update records, for the streams of
+	// the gRPC service.
+	subscribers map[chan Report]bool
+}
+
+func newReportServer(sla SLA) *reportServer {
+	return &reportServer{sla: sla, latest: make(map[string]Report), history: make(map[string][]PRInfo), subscribers: make(map[chan Report]bool)}
+}
+
+// updatePRs records the PRs of the latest run of a repository, with the
+// stored ones when there is a store.
+func (s *reportServer) updatePRs(owner string, repo string, prs []PRInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.history[owner+"/"+repo] = prs
+	s.cache.invalidate()
+}
+
+func (s *reportServer) prs(owner string, repo string) ([]PRInfo, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	prs, ok := s.history[owner+"/"+repo]
+	return prs, ok
+}
+
+// update records a report, replacing the one of the same repository unless
+// that one covers a later quarter.
+func (s *reportServer) update(r Report) {
+	key := r.Owner + "/" + r.Repo
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if latest, ok := s.latest[key]; ok && quarterIndex(latest.Year, latest.Quarter) > quarterIndex(r.Year, r.Quarter) {
+		return
+	}
+	s.latest[key] = r
+	s.cache.invalidate()
+	for subscriber := range s.subscribers {
+		// Slow subscribers miss the updates rather than holding the runs
+		select {
+		case subscriber <- r:
+		default:
+		}
+	}
+}
+
+// subscribe returns the reports update records from now on, until
+// unsubscribe is called.
+func (s *reportServer) subscribe() (updates <-chan Report, unsubscribe func()) {
+	subscriber := make(chan Report, 16)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.subscribers[subscriber] = true
+	return subscriber, func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		delete(s.subscribers, subscriber)
+	}
+}
+
+func (s *reportServer) report(owner string, repo string) (Report, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	r, ok := s.latest[owner+"/"+repo]
+	return r, ok
+}
+
+func (s *reportServer) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/badge/", s.serveBadge)
+	mux.HandleFunc("/feed/", s.serveFeed)
+	mux.HandleFunc("/api/repos", s.serveRepos)
+	mux.HandleFunc("/api/repos/", s.serveRepo)
+	mux.HandleFunc("/api/me", s.serveMe)
+	mux.HandleFunc("/team/", s.serveTeam)
+	mux.HandleFunc("/graphql", s.graphHandler())
+	mux.Handle("/", dashboardHandler())
+
+	var handler http.Handler = mux
+	if s.cache != nil {
+		handler = s.cache.wrap(mux, func(req *http.Request) string {
+			if s.login == nil {
+				return ""
+			}
+			if user := s.login.session(req); user != nil {
+				return user.login
+			}
+			return ""
+		})
+	}
+	if s.login == nil {
+		return handler
+	}
+	mux.HandleFunc("/login", s.login.handleLogin)
+	mux.HandleFunc("/oauth/callback", s.login.handleCallback)
+	mux.HandleFunc("/logout", s.login.handleLogout)
+	return s.login.requireLogin(handler)
+}
+
+// serve listens on addr until ctx is done.
+func (s *reportServer) serve(ctx context.Context, addr string) error {
+	server := &http.Server{Addr: addr, Handler: s.handler(), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	slog.Info("Serving the reports", "addr", addr)
+	if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// badge is the JSON of a shields.io endpoint badge, see
+// https://shields.io/badges/endpoint-badge.
+type badge struct {
+	SchemaVersion int    `json:"schemaVersion"`
+	Label         string `json:"label"`
+	Message       string `json:"message"`
+	Color         string `json:"color"`
+}
+
+// serveBadge answers /badge/{owner}/{repo}/avg-review-time with the average
+// time to the first human response of the latest quarter, green when it
+// meets the first response target of the SLA and red when it does not.
+// Without a target the color goes from green under a day to red over a week.
+func (s *reportServer) serveBadge(w http.ResponseWriter, req *http.Request) {
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, "/badge/"), "/")
+	if len(parts) != 3 || parts[2] != "avg-review-time" {
+		http.NotFound(w, req)
+		return
+	}
+	r, ok := s.report(parts[0], parts[1])
+	if !ok || !s.canRead(req, parts[0], parts[1]) {
+		http.NotFound(w, req)
+		return
+	}
+
+	average := r.AverageTimeToFirstHumanResponse
+	b := badge{SchemaVersion: 1, Label: "avg review time", Message: humanDuration(average.Round(time.Minute))}
+	if target := time.Duration(s.sla.targetsFor(nil).FirstResponse); target > 0 {
+		b.Color = "red"
+		if average <= target {
+			b.Color = "green"
+		}
+	} else {
+		switch {
+		case average < 24*time.Hour:
+			b.Color = "green"
+		case average < 3*24*time.Hour:
+			b.Color = "yellow"
+		case average < 7*24*time.Hour:
+			b.Color = "orange"
+		default:
+			b.Color = "red"
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "max-age=300")
+	json.NewEncoder(w).Encode(b)
+}
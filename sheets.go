@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/oauth2/google"
+)
+
+const sheetsScope = "https://www.googleapis.com/auth/spreadsheets"
+
+// sheetsExporter appends report rows to a Google Sheet on behalf of a service
+// account.
+type sheetsExporter struct {
+	client        *http.Client
+	spreadsheetID string
+	summaryRange  string
+	prRange       string
+	runTime       time.Time
+}
+
+func newSheetsExporter(ctx context.Context, credentialsFile string, spreadsheetID string, summaryRange string, prRange string) (*sheetsExporter, error) {
+	credentials, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading service account credentials: %w", err)
+	}
+	config, err := google.JWTConfigFromJSON(credentials, sheetsScope)
+	if err != nil {
+		return nil, fmt.Errorf("parsing service account credentials: %w", err)
+	}
+
+	return &sheetsExporter{
+		client:        config.Client(ctx),
+		spreadsheetID: spreadsheetID,
+		summaryRange:  summaryRange,
+		prRange:       prRange,
+		runTime:       time.Now().UTC(),
+	}, nil
+}
+
+// export appends one row with the aggregates of the report to the summary
+// range and, when a PR range is configured, one row per PR to that range.
+func (e *sheetsExporter) export(ctx context.Context, r Report) error {
+	summary := [][]interface{}{{
+		e.runTime.Format(time.RFC3339),
+		r.Owner + "/" + r.Repo,
+		r.Year,
+		r.Quarter,
+		len(r.PRs),
+		hours(r.AverageMergeTime),
+		hours(r.AverageTimeToFirstHumanResponse),
+		hours(r.AverageTimeToFirstBotResponse),
+		r.AverageNumberOfComments,
+		r.AverageNumberOfReviewers,
+		r.AverageNumberOfCommits,
+		r.TopReviewer,
+		r.TopCommenter,
+		r.TopCreator,
+		r.TopMerger,
+		r.Incomplete,
+		r.SelfMergeRate,
+	}}
+	if err := e.appendRows(ctx, e.summaryRange, summary); err != nil {
+		return err
+	}
+
+	if e.prRange == "" || len(r.PRs) == 0 {
+		return nil
+	}
+
+	var rows [][]interface{}
+	for _, pr := range r.PRs {
+		rows = append(rows, []interface{}{
+			e.runTime.Format(time.RFC3339),
+			r.Owner + "/" + r.Repo,
+			pr.Number,
+			pr.Title,
+			pr.Creator,
+			pr.CreatedAt.Format(time.RFC3339),
+			pr.MergedAt.Format(time.RFC3339),
+			hours(pr.Duration),
+			hours(pr.TimeToFirstResponse),
+			hours(pr.TimeToFirstHumanResponse),
+			pr.Commits,
+			len(uniqueCommenters(pr)),
+			len(uniqueReviewers(pr)),
+		})
+	}
+	return e.appendRows(ctx, e.prRange, rows)
+}
+
+func (e *sheetsExporter) appendRows(ctx context.Context, sheetRange string, rows [][]interface{}) error {
+	body, err := json.Marshal(map[string]interface{}{"values": rows})
+	if err != nil {
+		return err
+	}
+
+	u := fmt.Sprintf("https://sheets.googleapis.com/v4/spreadsheets/%s/values/%s:append?valueInputOption=USER_ENTERED&insertDataOption=INSERT_ROWS",
+		url.PathEscape(e.spreadsheetID), url.PathEscape(sheetRange))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("appending rows to %q: %s: %s", sheetRange, resp.Status, message)
+	}
+	return nil
+}
+
+// hours returns d in hours rounded to two decimals, which spreadsheets can
+// compute with unlike Go duration strings.
+func hours(d time.Duration) float64 {
+	return math.Round(d.Hours()*100) / 100
+}
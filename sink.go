@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	_ "github.com/lib/pq"
+)
+
+// RunInfo describes a run of the tool, stored next to the PRs it collected.
+type RunInfo struct {
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Owner      string
+	Repo       string
+	PRs        int
+	Incomplete bool
+}
+
+// postgresSink upserts the collected PRs and the metadata of the run into
+// PostgreSQL, so the history of many repositories can be queried with SQL.
+type postgresSink struct {
+	db *sql.DB
+}
+
+const postgresSchema = `
+CREATE TABLE IF NOT EXISTS runs (
+	id          BIGSERIAL PRIMARY KEY,
+	owner       TEXT NOT NULL,
+	repo        TEXT NOT NULL,
+	started_at  TIMESTAMPTZ NOT NULL,
+	finished_at TIMESTAMPTZ NOT NULL,
+	prs         INTEGER NOT NULL,
+	incomplete  BOOLEAN NOT NULL
+);
+CREATE TABLE IF NOT EXISTS prs (
+	owner                        TEXT NOT NULL,
+	repo                         TEXT NOT NULL,
+	number                       INTEGER NOT NULL,
+	title                        TEXT NOT NULL,
+	type                         TEXT NOT NULL,
+	creator                      TEXT NOT NULL,
+	merger                       TEXT NOT NULL,
+	created_at                   TIMESTAMPTZ NOT NULL,
+	merged_at                    TIMESTAMPTZ NOT NULL,
+	year                         INTEGER NOT NULL,
+	quarter                      TEXT NOT NULL,
+	merge_seconds                DOUBLE PRECISION NOT NULL,
+	first_response_seconds       DOUBLE PRECISION NOT NULL,
+	first_human_response_seconds DOUBLE PRECISION NOT NULL,
+	commits                      INTEGER NOT NULL,
+	reviewers                    INTEGER NOT NULL,
+	data                         JSONB NOT NULL,
+	run_id                       BIGINT NOT NULL REFERENCES runs (id),
+	PRIMARY KEY (owner, repo, number)
+);
+`
+
+// openSink connects to the sink at the URL. Only postgres:// URLs are
+// supported.
+func openSink(ctx context.Context, sinkURL string) (*postgresSink, error) {
+	u, err := url.Parse(sinkURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "postgres" && u.Scheme != "postgresql" {
+		return nil, fmt.Errorf("unsupported sink %q, only postgres:// URLs are supported", u.Redacted())
+	}
+
+	db, err := sql.Open("postgres", sinkURL)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.ExecContext(ctx, postgresSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating the schema in %s: %w", u.Redacted(), err)
+	}
+	return &postgresSink{db: db}, nil
+}
+
+func (s *postgresSink) Close() error {
+	return s.db.Close()
+}
+
+// save records the run and upserts its PRs in a single transaction.
+func (s *postgresSink) save(ctx context.Context, run RunInfo, prs []PRInfo) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var runID int64
+	err = tx.QueryRowContext(ctx, `INSERT INTO runs (owner, repo, started_at, finished_at, prs, incomplete)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		run.Owner, run.Repo, run.StartedAt, run.FinishedAt, run.PRs, run.Incomplete).Scan(&runID)
+	if err != nil {
+		return fmt.Errorf("recording the run: %w", err)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, `INSERT INTO prs
+		(owner, repo, number, title, type, creator, merger, created_at, merged_at, year, quarter,
+		 merge_seconds, first_response_seconds, first_human_response_seconds, commits, reviewers, data, run_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		ON CONFLICT (owner, repo, number) DO UPDATE SET
+		title = EXCLUDED.title, type = EXCLUDED.type, creator = EXCLUDED.creator, merger = EXCLUDED.merger,
+		created_at = EXCLUDED.created_at, merged_at = EXCLUDED.merged_at, year = EXCLUDED.year, quarter = EXCLUDED.quarter,
+		merge_seconds = EXCLUDED.merge_seconds, first_response_seconds = EXCLUDED.first_response_seconds,
+		first_human_response_seconds = EXCLUDED.first_human_response_seconds, commits = EXCLUDED.commits,
+		reviewers = EXCLUDED.reviewers, data = EXCLUDED.data, run_id = EXCLUDED.run_id`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, pr := range prs {
+		data, err := json.Marshal(pr)
+		if err != nil {
+			return err
+		}
+		// The PRs of a group of repositories are upserted in their own
+		owner, repo := run.Owner, run.Repo
+		if prOwner, prRepo, ok := strings.Cut(pr.Repository, "/"); ok {
+			owner, repo = prOwner, prRepo
+		}
+		_, err = stmt.ExecContext(ctx, owner, repo, pr.Number, pr.Title, pr.Type, pr.Creator, pr.Merger,
+			pr.CreatedAt, pr.MergedAt, pr.Year, pr.Quarter, pr.Duration.Seconds(), pr.TimeToFirstResponse.Seconds(),
+			pr.TimeToFirstHumanResponse.Seconds(), pr.Commits, len(uniqueReviewers(pr)), string(data), runID)
+		if err != nil {
+			return fmt.Errorf("upserting PR #%d: %w", pr.Number, err)
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// siteCommand is the site command, which renders the reports of every
+// quarter of every repository in the store as static HTML pages into dir.
+type siteCommand struct {
+	dir string
+}
+
+// siteRepository is a repository on the index page of the site.
+type siteRepository struct {
+	Owner   string
+	Repo    string
+	Reports []Report
+}
+
+var siteFuncs = template.FuncMap{
+	"duration": formatDuration,
+	"percent":  func(f float64) string { return fmt.Sprintf("%.1f%%", f*100) },
+	"page":     sitePage,
+	"slaSet":   func(sla SLA) bool { return sla.isSet() },
+}
+
+// sitePage is the path of the page of a report, relative to the index.
+func sitePage(r Report) string {
+	return fmt.Sprintf("%s/%s/%d-%s.html", r.Owner, r.Repo, r.Year, r.Quarter)
+}
+
+const siteStyle = `<style>
+body { font-family: sans-serif; margin: 2em auto; max-width: 60em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border-bottom: 1px solid #ddd; padding: .3em .8em; text-align: left; }
+</style>`
+
+var siteIndex = template.Must(template.New("index").Funcs(siteFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Review metrics</title>` + siteStyle + `</head>
+<body>
+<h1>Review metrics</h1>
+{{range .}}<h2>{{.Owner}}/{{.Repo}}</h2>
+<table>
+<tr><th>Quarter</th><th>PRs</th><th>Average merge time</th><th>Average time to first human response</th><th>SLA compliance</th></tr>
+{{range .Reports}}<tr><td><a href="{{page .}}">{{.Year}} {{.Quarter}}</a></td><td>{{len .PRs}}</td><td>{{duration .AverageMergeTime}}</td><td>{{duration .AverageTimeToFirstHumanResponse}}</td><td>{{if slaSet .SLA}}{{percent .SLACompliance}}{{else}}-{{end}}</td></tr>
+{{end}}</table>
+{{else}}<p>No PRs in the store.</p>
+{{end}}</body>
+</html>
+`))
+
+var siteReport = template.Must(template.New("report").Funcs(siteFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Owner}}/{{.Repo}} {{.Year}} {{.Quarter}}</title>` + siteStyle + `</head>
+<body>
+<p><a href="../../index.html">All repositories</a></p>
+<h1>{{.Owner}}/{{.Repo}} {{.Year}} {{.Quarter}}</h1>
+<table>
+<tr><th>PRs</th><td>{{len .PRs}}</td></tr>
+<tr><th>Average merge time</th><td>{{duration .AverageMergeTime}}</td></tr>
+<tr><th>Average time to first human response</th><td>{{duration .AverageTimeToFirstHumanResponse}}</td></tr>
+<tr><th>Average time to first bot response</th><td>{{duration .AverageTimeToFirstBotResponse}}</td></tr>
+<tr><th>Average time waiting on CI</th><td>{{duration .AverageCITime}}</td></tr>
+<tr><th>Average number of reviewers per PR</th><td>{{printf "%.1f" .AverageNumberOfReviewers}}</td></tr>
+<tr><th>Average number of comments per PR</th><td>{{printf "%.1f" .AverageNumberOfComments}}</td></tr>
+<tr><th>Self-merge rate</th><td>{{percent .SelfMergeRate}}</td></tr>
+{{if slaSet .SLA}}<tr><th>SLA compliance</th><td>{{percent .SLACompliance}}</td></tr>
+{{end}}</table>
+<h2>Top reviewers</h2>
+<table>
+{{range .ReviewerLeaderboard}}<tr><td>{{.Rank}}.</td><td>{{.Name}}</td><td>{{.Count}}</td><td>{{percent .Share}}</td></tr>
+{{end}}</table>
+<h2>Pull requests</h2>
+<table>
+<tr><th>PR</th><th>Title</th><th>Creator</th><th>Merger</th><th>First human response</th><th>Merge time</th></tr>
+{{range .PRs}}<tr><td>#{{.Number}}</td><td>{{.Title}}</td><td>{{.Creator}}</td><td>{{.Merger}}</td><td>{{duration .TimeToFirstHumanResponse}}</td><td>{{duration .Duration}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// buildSite renders an index page and one page per quarter of every
+// repository in the store at storePath into dir, newest quarter first, from
+// the PRs matching the query (all when empty).
+func buildSite(dir string, storePath string, query string, opts reportOptions) error {
+	store, err := openStore(storePath)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	repos, err := store.Repositories()
+	if err != nil {
+		return err
+	}
+
+	var index []siteRepository
+	for _, repo := range repos {
+		prs, err := store.QueryPRs(repo.Owner, repo.Repo, query)
+		if err != nil {
+			return err
+		}
+		byQuarter := make(map[int][]PRInfo)
+		for _, pr := range prs {
+			q := quarterIndex(pr.Year, pr.Quarter)
+			byQuarter[q] = append(byQuarter[q], pr)
+		}
+		quarters := make([]int, 0, len(byQuarter))
+		for q := range byQuarter {
+			quarters = append(quarters, q)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(quarters)))
+
+		entry := siteRepository{Owner: repo.Owner, Repo: repo.Repo}
+		for _, q := range quarters {
+			quarterPRs := byQuarter[q]
+			report := newReport(repo.Owner, repo.Repo, quarterPRs[0].Year, quarterPRs[0].Quarter, quarterPRs, opts)
+			if err := writeSitePage(filepath.Join(dir, sitePage(report)), siteReport, report); err != nil {
+				return err
+			}
+			entry.Reports = append(entry.Reports, report)
+		}
+		index = append(index, entry)
+	}
+	return writeSitePage(filepath.Join(dir, "index.html"), siteIndex, index)
+}
+
+func writeSitePage(path string, tmpl *template.Template, data interface{}) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = tmpl.Execute(f, data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
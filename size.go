@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// changedLines is the size of the PR, the lines it adds and deletes.
+func changedLines(pr PRInfo) int {
+	return pr.Additions + pr.Deletions
+}
+
+// sizeWeightedAverage averages the durations of the PRs weighted by their
+// changed lines, so that the many one line dependency bumps do not outweigh
+// the feature PRs. The PRs that change no lines do not count.
+func sizeWeightedAverage(prData []PRInfo, value func(pr PRInfo) time.Duration) time.Duration {
+	var total float64
+	var lines int
+	for _, pr := range prData {
+		total += float64(value(pr)) * float64(changedLines(pr))
+		lines += changedLines(pr)
+	}
+	if lines == 0 {
+		return 0
+	}
+	return time.Duration(total / float64(lines))
+}
+
+// sizeBuckets are the sizes the PRs are put in, by the upper bound of their
+// changed lines, the last one taking all the larger PRs.
+var sizeBuckets = []struct {
+	name  string
+	lines int
+}{
+	{"XS", 10},
+	{"S", 30},
+	{"M", 100},
+	{"L", 500},
+	{"XL", 0},
+}
+
+// sizeOf returns the size bucket of the PR.
+func sizeOf(pr PRInfo) string {
+	lines := changedLines(pr)
+	for _, bucket := range sizeBuckets[:len(sizeBuckets)-1] {
+		if lines < bucket.lines {
+			return bucket.name
+		}
+	}
+	return sizeBuckets[len(sizeBuckets)-1].name
+}
+
+// SizeBucket holds the medians of the PRs of one size.
+type SizeBucket struct {
+	Size string
+	PRs  int
+	// MedianTimeToFirstReview is the median time from the PR being ready for
+	// review to its first review, over the PRs that were reviewed.
+	MedianTimeToFirstReview time.Duration
+	MedianMergeTime         time.Duration
+}
+
+// timeToFirstReview returns the time until the first review of the PR by
+// somebody else than its creator, false when nobody reviewed it.
+func timeToFirstReview(pr PRInfo) (time.Duration, bool) {
+	return pr.TimeToFirstReview, !pr.FirstReviewAt.IsZero()
+}
+
+// sizeBucketMedians breaks the medians down by size bucket, leaving out the
+// buckets without PRs. The PRs that change no lines are left out too, they
+// are mostly the ones stored before the sizes were collected.
+func sizeBucketMedians(prData []PRInfo) []SizeBucket {
+	mergeTimes := make(map[string][]float64)
+	reviewTimes := make(map[string][]float64)
+	for _, pr := range prData {
+		if changedLines(pr) == 0 {
+			continue
+		}
+		size := sizeOf(pr)
+		mergeTimes[size] = append(mergeTimes[size], float64(pr.Duration))
+		if d, ok := timeToFirstReview(pr); ok {
+			reviewTimes[size] = append(reviewTimes[size], float64(d))
+		}
+	}
+	median := func(values []float64) time.Duration {
+		if len(values) == 0 {
+			return 0
+		}
+		sort.Float64s(values)
+		return time.Duration(quantile(values, 0.5))
+	}
+
+	var buckets []SizeBucket
+	for _, bucket := range sizeBuckets {
+		if len(mergeTimes[bucket.name]) == 0 {
+			continue
+		}
+		buckets = append(buckets, SizeBucket{
+			Size:                    bucket.name,
+			PRs:                     len(mergeTimes[bucket.name]),
+			MedianTimeToFirstReview: median(reviewTimes[bucket.name]),
+			MedianMergeTime:         median(mergeTimes[bucket.name]),
+		})
+	}
+	return buckets
+}
+
+func printSizeBuckets(w io.Writer, buckets []SizeBucket) {
+	if len(buckets) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "Medians per size (XS <10, S <30, M <100, L <500, XL 500+ changed lines):")
+	rows := [][]string{{"Size", "PRs", "Time to first review", "Merge time"}}
+	for _, bucket := range buckets {
+		rows = append(rows, []string{bucket.Size, strconv.Itoa(bucket.PRs), formatDuration(bucket.MedianTimeToFirstReview), formatDuration(bucket.MedianMergeTime)})
+	}
+	for _, line := range alignColumns(rows) {
+		fmt.Fprintln(w, "  "+line)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// SLA are the targets the reviews of the PRs should meet. Targets that are
+// not set are not checked. The targets of the labels replace the default
+// ones for the PRs with those labels, the strictest one winning when a PR has
+// several:
+//
+//	"sla": {
+//	  "firstResponse": "1d",
+//	  "merge": "5d",
+//	  "labels": {
+//	    "priority/critical": {"firstResponse": "2h"},
+//	    "kind/docs": {"firstResponse": "3d"}
+//	  }
+//	}
+type SLA struct {
+	SLATargets
+	Labels map[string]SLATargets `json:"labels"`
+}
+
+// SLATargets are the targets of a set of PRs.
+type SLATargets struct {
+	// FirstResponse is the longest the PRs should wait for a first human
+	// response.
+	FirstResponse configDuration `json:"firstResponse"`
+	// Merge is the longest the PRs should take to merge.
+	Merge configDuration `json:"merge"`
+}
+
+// configDuration is a duration written as a string in the configuration
+// file, such as "4h" or "3d".
+type configDuration time.Duration
+
+func (d *configDuration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("durations must be strings such as \"4h\" or \"3d\": %w", err)
+	}
+	parsed, err := parseShortDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = configDuration(parsed)
+	return nil
+}
+
+func (d configDuration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(shortDuration(time.Duration(d)))
+}
+
+// String and Set make the durations flags too, such as --older-than.
+func (d *configDuration) String() string {
+	if *d == 0 {
+		return ""
+	}
+	return shortDuration(time.Duration(*d))
+}
+
+func (d *configDuration) Set(value string) error {
+	parsed, err := parseShortDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = configDuration(parsed)
+	return nil
+}
+
+// targetsFor returns the targets of a PR with the labels.
+func (sla SLA) targetsFor(labels []string) SLATargets {
+	var firstResponse, merge []configDuration
+	for _, label := range labels {
+		targets, ok := sla.Labels[label]
+		if !ok {
+			continue
+		}
+		if targets.FirstResponse > 0 {
+			firstResponse = append(firstResponse, targets.FirstResponse)
+		}
+		if targets.Merge > 0 {
+			merge = append(merge, targets.Merge)
+		}
+	}
+
+	targets := sla.SLATargets
+	if len(firstResponse) > 0 {
+		targets.FirstResponse = minDuration(firstResponse)
+	}
+	if len(merge) > 0 {
+		targets.Merge = minDuration(merge)
+	}
+	return targets
+}
+
+func minDuration(durations []configDuration) configDuration {
+	shortest := durations[0]
+	for _, d := range durations[1:] {
+		shortest = min(shortest, d)
+	}
+	return shortest
+}
+
+func (sla SLA) isSet() bool {
+	if sla.FirstResponse > 0 || sla.Merge > 0 {
+		return true
+	}
+	for _, targets := range sla.Labels {
+		if targets.FirstResponse > 0 || targets.Merge > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// SLABreach is a PR that missed one of the SLA targets.
+type SLABreach struct {
+	Repository string
+	Number     int
+	Title      string
+	Metric     string
+	Target     time.Duration
+	Actual     time.Duration
+}
+
+// slaBreaches returns the targets of the SLA the PR missed. PRs that never
+// had a human response are only checked against the merge target.
+func slaBreaches(pr PRInfo, sla SLA) []SLABreach {
+	targets := sla.targetsFor(pr.Labels)
+
+	var breaches []SLABreach
+	if target := time.Duration(targets.FirstResponse); target > 0 && pr.FirstHumanResponder != "" && pr.TimeToFirstHumanResponse > target {
+		breaches = append(breaches, SLABreach{Repository: pr.Repository, Number: pr.Number, Title: pr.Title, Metric: "first response", Target: target, Actual: pr.TimeToFirstHumanResponse})
+	}
+	if target := time.Duration(targets.Merge); target > 0 && pr.Duration > target {
+		breaches = append(breaches, SLABreach{Repository: pr.Repository, Number: pr.Number, Title: pr.Title, Metric: "merge", Target: target, Actual: pr.Duration})
+	}
+	return breaches
+}
+
+// slaCompliance returns the breaches of all the PRs and the share of the PRs
+// that met every target.
+func slaCompliance(prData []PRInfo, sla SLA) (breaches []SLABreach, compliance float64) {
+	if len(prData) == 0 {
+		return nil, 1
+	}
+	compliant := 0
+	for _, pr := range prData {
+		prBreaches := slaBreaches(pr, sla)
+		if len(prBreaches) == 0 {
+			compliant++
+		}
+		breaches = append(breaches, prBreaches...)
+	}
+	return breaches, float64(compliant) / float64(len(prData))
+}
+
+// LabelCompliance is the SLA compliance of the PRs with a label.
+type LabelCompliance struct {
+	Label      string
+	PRs        int
+	Compliance float64
+}
+
+// slaComplianceByLabel breaks the compliance down per label, the PRs without
+// labels being grouped under noSegment.
+func slaComplianceByLabel(prData []PRInfo, sla SLA) []LabelCompliance {
+	perLabel := make(map[string][]PRInfo)
+	for _, pr := range prData {
+		labels := pr.Labels
+		if len(labels) == 0 {
+			labels = []string{noSegment}
+		}
+		for _, label := range labels {
+			perLabel[label] = append(perLabel[label], pr)
+		}
+	}
+
+	var result []LabelCompliance
+	for label, prs := range perLabel {
+		_, compliance := slaCompliance(prs, sla)
+		result = append(result, LabelCompliance{Label: label, PRs: len(prs), Compliance: compliance})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Label < result[j].Label })
+	return result
+}
+
+func printSLACompliance(w io.Writer, sla SLA, breaches []SLABreach, compliance float64, perLabel []LabelCompliance) {
+	if !sla.isSet() {
+		return
+	}
+	fmt.Fprintf(w, "SLA compliance: %.1f%% of the PRs met every target\n", compliance*100)
+	for _, label := range perLabel {
+		fmt.Fprintf(w, "  %s: %.1f%% of %d PRs\n", label.Label, label.Compliance*100, label.PRs)
+	}
+	for _, breach := range breaches {
+		fmt.Fprintf(w, "  PR #%d: %s missed the %s target of %s, took %s\n", breach.Number, breach.Title, breach.Metric, formatDuration(breach.Target), formatDuration(breach.Actual))
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// Snapshot holds the aggregates of a run, saved as a baseline to measure the
+// effect of process changes against.
+type Snapshot struct {
+	TakenAt    time.Time
+	Repository string
+	PRs        int
+	Metrics    map[string]float64
+}
+
+// snapshotMetric is an aggregate kept in snapshots. Durations are kept in
+// hours.
+type snapshotMetric struct {
+	name          string
+	duration      bool
+	lowerIsBetter bool
+	value         func(r Report) float64
+}
+
+var snapshotMetrics = []snapshotMetric{
+	{"average merge time", true, true, func(r Report) float64 { return hours(r.AverageMergeTime) }},
+	{"average first human response time", true, true, func(r Report) float64 { return hours(r.AverageTimeToFirstHumanResponse) }},
+	{"average first bot response time", true, true, func(r Report) float64 { return hours(r.AverageTimeToFirstBotResponse) }},
+	{"average time in draft", true, true, func(r Report) float64 { return hours(r.AverageTimeInDraft) }},
+	{"average time waiting on CI", true, true, func(r Report) float64 { return hours(r.AverageCITime) }},
+	{"average time from CI green to merge", true, true, func(r Report) float64 { return hours(r.AverageGreenToMerge) }},
+	{"average comments per PR", false, false, func(r Report) float64 { return r.AverageNumberOfComments }},
+	{"average reviewers per PR", false, false, func(r Report) float64 { return r.AverageNumberOfReviewers }},
+	{"average pushes after the first review", false, true, func(r Report) float64 { return r.AveragePushesAfterFirstReview }},
+	{"self-merge rate", false, true, func(r Report) float64 { return r.SelfMergeRate }},
+}
+
+// snapshotCommand is the snapshot or compare command, which runs the report
+// as usual and then saves its aggregates to path (action "save") or compares
+// them with the ones saved there (action "diff").
+type snapshotCommand struct {
+	action string
+	path   string
+}
+
+// snapshotWriter collects the PRs of all the reports and, when closed, saves
+// or diffs the aggregates computed over all of them.
+type snapshotWriter struct {
+	command *snapshotCommand
+	opts    reportOptions
+	owner   string
+	repo    string
+	prs     []PRInfo
+}
+
+func (w *snapshotWriter) write(r Report) error {
+	w.owner, w.repo = r.Owner, r.Repo
+	w.prs = append(w.prs, r.PRs...)
+	return nil
+}
+
+func (w *snapshotWriter) close() error {
+	current := takeSnapshot(newReport(w.owner, w.repo, 0, "", w.prs, w.opts))
+	if w.command.action == "save" {
+		data, err := json.MarshalIndent(current, "", "  ")
+		if err != nil {
+			return err
+		}
+		return os.WriteFile(w.command.path, data, 0o644)
+	}
+
+	data, err := os.ReadFile(w.command.path)
+	if err != nil {
+		return err
+	}
+	var baseline Snapshot
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return fmt.Errorf("parsing snapshot %s: %w", w.command.path, err)
+	}
+	printSnapshotDiff(baseline, current)
+	return nil
+}
+
+func takeSnapshot(r Report) Snapshot {
+	snapshot := Snapshot{
+		TakenAt:    time.Now().UTC(),
+		Repository: r.Owner + "/" + r.Repo,
+		PRs:        len(r.PRs),
+		Metrics:    make(map[string]float64),
+	}
+	for _, metric := range snapshotMetrics {
+		snapshot.Metrics[metric.name] = metric.value(r)
+	}
+	return snapshot
+}
+
+func printSnapshotDiff(baseline Snapshot, current Snapshot) {
+	fmt.Printf("Compared to the baseline of %s (%d PRs of %s), with %d PRs now:\n", baseline.TakenAt.Format("2006-01-02"), baseline.PRs, baseline.Repository, current.PRs)
+	for _, metric := range snapshotMetrics {
+		before, ok := baseline.Metrics[metric.name]
+		if !ok {
+			continue
+		}
+		after := current.Metrics[metric.name]
+		fmt.Printf("  %s %s (%s -> %s)\n", metric.name, change(before, after, metric.lowerIsBetter), metric.format(before), metric.format(after))
+	}
+}
+
+// change describes how a metric moved, in percent of its baseline value.
+func change(before float64, after float64, lowerIsBetter bool) string {
+	if after == before {
+		return "did not change"
+	}
+	if before == 0 {
+		return "changed from zero"
+	}
+	percent := math.Abs(after-before) / math.Abs(before) * 100
+	if (after < before) == lowerIsBetter {
+		return fmt.Sprintf("improved %.1f%%", percent)
+	}
+	return fmt.Sprintf("regressed %.1f%%", percent)
+}
+
+func (m snapshotMetric) format(value float64) string {
+	if m.duration {
+		return formatDuration(time.Duration(value * float64(time.Hour)).Round(time.Minute))
+	}
+	return fmt.Sprintf("%.2f", value)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// prOrder is the --sort flag ordering the per-PR lines, written as
+// field[:asc|desc]. The durations are sorted slowest first and the numbers
+// and creation times oldest first unless the direction is given.
+type prOrder struct {
+	field      string
+	descending bool
+}
+
+var prSortKeys = map[string]func(pr PRInfo) int64{
+	"duration":       func(pr PRInfo) int64 { return int64(pr.Duration) },
+	"first-response": func(pr PRInfo) int64 { return int64(pr.TimeToFirstResponse) },
+	"number":         func(pr PRInfo) int64 { return int64(pr.Number) },
+	"created":        func(pr PRInfo) int64 { return pr.CreatedAt.UnixNano() },
+}
+
+func (o *prOrder) String() string {
+	if o.field == "" {
+		return ""
+	}
+	if o.descending {
+		return o.field + ":desc"
+	}
+	return o.field + ":asc"
+}
+
+func (o *prOrder) Set(value string) error {
+	field, direction, _ := strings.Cut(value, ":")
+	if _, ok := prSortKeys[field]; !ok {
+		return fmt.Errorf("unknown sort field %q, expected duration, first-response, number or created", field)
+	}
+	switch direction {
+	case "":
+		o.descending = field == "duration" || field == "first-response"
+	case "asc":
+		o.descending = false
+	case "desc":
+		o.descending = true
+	default:
+		return fmt.Errorf("unknown sort direction %q, expected asc or desc", direction)
+	}
+	o.field = field
+	return nil
+}
+
+// sorted returns a copy of the PRs in the order, or the PRs themselves when
+// no order is set.
+func (o prOrder) sorted(prs []PRInfo) []PRInfo {
+	key, ok := prSortKeys[o.field]
+	if !ok {
+		return prs
+	}
+	sorted := append([]PRInfo(nil), prs...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if o.descending {
+			return key(sorted[i]) > key(sorted[j])
+		}
+		return key(sorted[i]) < key(sorted[j])
+	})
+	return sorted
+}
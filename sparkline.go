@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+var sparklineTicks = []rune("▁▂▃▄▅▆▇█")
+
+// sparkline renders the values as a single line of unicode block characters,
+// scaled between the smallest and the largest value.
+func sparkline(values []float64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	min, max := values[0], values[0]
+	for _, v := range values {
+		min = math.Min(min, v)
+		max = math.Max(max, v)
+	}
+
+	var sb strings.Builder
+	for _, v := range values {
+		tick := 0
+		if max > min {
+			tick = int((v - min) / (max - min) * float64(len(sparklineTicks)-1))
+		}
+		sb.WriteRune(sparklineTicks[tick])
+	}
+	return sb.String()
+}
+
+// barChart renders one horizontal bar per label, the longest bar being width
+// characters wide.
+func barChart(labels []string, values []float64, width int) string {
+	max := 0.0
+	labelWidth := 0
+	for i, v := range values {
+		max = math.Max(max, v)
+		if len(labels[i]) > labelWidth {
+			labelWidth = len(labels[i])
+		}
+	}
+
+	var sb strings.Builder
+	for i, v := range values {
+		length := 0
+		if max > 0 {
+			length = int(math.Round(v / max * float64(width)))
+		}
+		fmt.Fprintf(&sb, "  %-*s %s %v\n", labelWidth, labels[i], strings.Repeat("█", length), v)
+	}
+	return sb.String()
+}
+
+// weekStart returns midnight of the Monday of the week t falls in.
+func weekStart(t time.Time) time.Time {
+	t = t.UTC().Truncate(24 * time.Hour)
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
+
+// mergeTimesPerWeek returns the average merge time of the PRs merged in each
+// week, from the week of the first merge to the week of the last one. Weeks
+// without merges have an average of zero.
+func mergeTimesPerWeek(prData []PRInfo) (weeks []time.Time, averages []time.Duration) {
+	if len(prData) == 0 {
+		return nil, nil
+	}
+
+	totals := make(map[time.Time]time.Duration)
+	counts := make(map[time.Time]int)
+	first, last := weekStart(prData[0].MergedAt), weekStart(prData[0].MergedAt)
+	for _, pr := range prData {
+		week := weekStart(pr.MergedAt)
+		totals[week] += pr.Duration
+		counts[week]++
+		if week.Before(first) {
+			first = week
+		}
+		if week.After(last) {
+			last = week
+		}
+	}
+
+	for week := first; !week.After(last); week = week.AddDate(0, 0, 7) {
+		weeks = append(weeks, week)
+		if counts[week] == 0 {
+			averages = append(averages, 0)
+			continue
+		}
+		averages = append(averages, totals[week]/time.Duration(counts[week]))
+	}
+	return weeks, averages
+}
+
+func mergeTimePerWeekSparkline(prData []PRInfo) string {
+	weeks, averages := mergeTimesPerWeek(prData)
+	if len(weeks) == 0 {
+		return ""
+	}
+
+	values := make([]float64, len(averages))
+	for i, average := range averages {
+		values[i] = average.Hours()
+	}
+
+	return fmt.Sprintf("%s (weeks of %s to %s)", sparkline(values), weeks[0].Format("2006-01-02"), weeks[len(weeks)-1].Format("2006-01-02"))
+}
+
+var weekdays = []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday, time.Saturday, time.Sunday}
+
+func prsPerWeekdayBarChart(prData []PRInfo) string {
+	days := make(map[string]int)
+	for _, pr := range prData {
+		days[pr.CreationDayOfWeek]++
+	}
+
+	labels := make([]string, len(weekdays))
+	values := make([]float64, len(weekdays))
+	for i, day := range weekdays {
+		labels[i] = day.String()
+		values[i] = float64(days[day.String()])
+	}
+
+	return barChart(labels, values, 40)
+}
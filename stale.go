@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// StalePR is an open PR nobody has touched for a while.
+type StalePR struct {
+	Repository string
+	Number     int
+	Title      string
+	Creator    string
+	UpdatedAt  time.Time
+	// Idle is how long the PR has not been updated for.
+	Idle time.Duration
+}
+
+// fetchStalePRs returns the open PRs that have not been updated for longer
+// than after, the longest idle first.
+func fetchStalePRs(ctx context.Context, client GitHubClient, owner string, repo string, after time.Duration, now time.Time) ([]StalePR, error) {
+	opt := &github.PullRequestListOptions{
+		State:       "open",
+		Sort:        "updated",
+		Direction:   "asc",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+
+	var stale []StalePR
+	for {
+		prs, resp, err := client.ListPRs(ctx, owner, repo, opt)
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", opt.Page, err)
+		}
+		for _, pr := range prs {
+			idle := now.Sub(pr.GetUpdatedAt())
+			if pr.GetState() != "open" || idle <= after {
+				continue
+			}
+			stale = append(stale, StalePR{
+				Repository: owner + "/" + repo,
+				Number:     pr.GetNumber(),
+				Title:      pr.GetTitle(),
+				Creator:    pr.GetUser().GetLogin(),
+				UpdatedAt:  pr.GetUpdatedAt(),
+				Idle:       idle,
+			})
+		}
+		// The PRs come least recently updated first, so the rest are fresh
+		if resp.NextPage == 0 || len(prs) == 0 || now.Sub(prs[len(prs)-1].GetUpdatedAt()) <= after {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	return stale, nil
+}
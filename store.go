@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Store keeps the PRs collected by every run in a SQLite database, so the
+// analyses can cover more history than a single run fetches.
+type Store struct {
+	db *sql.DB
+}
+
+const storeSchema = `
+CREATE TABLE IF NOT EXISTS prs (
+	owner      TEXT NOT NULL,
+	repo       TEXT NOT NULL,
+	number     INTEGER NOT NULL,
+	creator    TEXT NOT NULL,
+	created_at TIMESTAMP NOT NULL,
+	merged_at  TIMESTAMP NOT NULL,
+	year       INTEGER NOT NULL,
+	quarter    TEXT NOT NULL,
+	data       TEXT NOT NULL,
+	stored_at  TIMESTAMP NOT NULL,
+	PRIMARY KEY (owner, repo, number)
+);
+CREATE INDEX IF NOT EXISTS prs_period ON prs (owner, repo, year, quarter);
+CREATE TABLE IF NOT EXISTS cursors (
+	owner      TEXT NOT NULL,
+	repo       TEXT NOT NULL,
+	updated_at TIMESTAMP NOT NULL,
+	PRIMARY KEY (owner, repo)
+);
+CREATE TABLE IF NOT EXISTS digests (
+	owner TEXT NOT NULL,
+	repo  TEXT NOT NULL,
+	week  TIMESTAMP NOT NULL,
+	PRIMARY KEY (owner, repo)
+);
+CREATE TABLE IF NOT EXISTS pins (
+	login    TEXT NOT NULL,
+	position INTEGER NOT NULL,
+	owner    TEXT NOT NULL,
+	repo     TEXT NOT NULL,
+	PRIMARY KEY (login, owner, repo)
+);
+`
+
+func openStore(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.Exec(storeSchema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("creating the store schema in %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// SavePRs inserts the PRs of the repository, replacing the ones stored
+// before.
+func (s *Store) SavePRs(owner string, repo string, prs []PRInfo) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`INSERT OR REPLACE INTO prs
+		(owner, repo, number, creator, created_at, merged_at, year, quarter, data, stored_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC()
+	for _, pr := range prs {
+		data, err := json.Marshal(pr)
+		if err != nil {
+			return err
+		}
+		if _, err := stmt.Exec(owner, repo, pr.Number, pr.Creator, pr.CreatedAt, pr.MergedAt, pr.Year, pr.Quarter, string(data), now); err != nil {
+			return fmt.Errorf("storing PR #%d: %w", pr.Number, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// LoadPRs returns all the stored PRs of the repository in the order they
+// were saved, so an offline report lists the PRs of the last run the same
+// way that run did.
+func (s *Store) LoadPRs(owner string, repo string) ([]PRInfo, error) {
+	return s.QueryPRs(owner, repo, "")
+}
+
+// storeQueryColumns are the columns an SQL condition on the stored PRs can
+// use besides the ones of the prs table, extracted from the JSON of the PRs.
+// The durations are in hours.
+const storeQueryColumns = `
+	json_extract(data, '$.Title') AS title,
+	json_extract(data, '$.Merger') AS merger,
+	json_extract(data, '$.Type') AS type,
+	json_extract(data, '$.Milestone') AS milestone,
+	json_extract(data, '$.Labels') AS labels,
+	json_extract(data, '$.Duration') / 3.6e12 AS duration,
+	json_extract(data, '$.TimeToFirstResponse') / 3.6e12 AS first_response,
+	json_extract(data, '$.TimeToFirstHumanResponse') / 3.6e12 AS first_human_response,
+	json_extract(data, '$.CITime') / 3.6e12 AS ci_time`
+
+// QueryPRs returns the stored PRs of the repository that match the SQL
+// condition, such as "type = 'feat' AND duration > 72", in the order they
+// were saved. An empty condition matches all PRs. The condition is part of
+// the statement, so it is run on a connection that is read-only meanwhile
+// and cannot change the store.
+func (s *Store) QueryPRs(owner string, repo string, where string) ([]PRInfo, error) {
+	ctx := context.Background()
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	query := `SELECT data FROM prs WHERE owner = ? AND repo = ? ORDER BY rowid`
+	if where != "" {
+		if _, err := conn.ExecContext(ctx, `PRAGMA query_only = ON`); err != nil {
+			return nil, err
+		}
+		// The connection goes back to the pool writable
+		defer conn.ExecContext(ctx, `PRAGMA query_only = OFF`)
+		query = `SELECT data FROM (SELECT rowid AS id, *,` + storeQueryColumns + `
+			FROM prs WHERE owner = ? AND repo = ?)
+			WHERE (` + where + `) ORDER BY id`
+	}
+	rows, err := conn.QueryContext(ctx, query, owner, repo)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prs []PRInfo
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, err
+		}
+		var pr PRInfo
+		if err := json.Unmarshal([]byte(data), &pr); err != nil {
+			return nil, err
+		}
+		// The PRs stored before they had a repository are from this one
+		if pr.Repository == "" {
+			pr.Repository = owner + "/" + repo
+		}
+		prs = append(prs, pr)
+	}
+	return prs, rows.Err()
+}
+
+// Cursor returns when the most recently updated PR of the repository seen by
+// an update was updated, or, before the first update, when the most recently
+// merged stored PR was merged. It is zero when the store has no PRs of the
+// repository.
+func (s *Store) Cursor(owner string, repo string) (time.Time, error) {
+	var cursor time.Time
+	err := s.db.QueryRow(`SELECT updated_at FROM cursors WHERE owner = ? AND repo = ?`, owner, repo).Scan(&cursor)
+	if err == sql.ErrNoRows {
+		err = s.db.QueryRow(`SELECT merged_at FROM prs WHERE owner = ? AND repo = ? ORDER BY merged_at DESC LIMIT 1`, owner, repo).Scan(&cursor)
+	}
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return cursor, err
+}
+
+// SaveCursor records when the most recently updated PR of the repository seen
+// by an update was updated.
+func (s *Store) SaveCursor(owner string, repo string, updatedAt time.Time) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO cursors (owner, repo, updated_at) VALUES (?, ?, ?)`, owner, repo, updatedAt.UTC())
+	return err
+}
+
+// DigestWeek returns the start of the week the notifications of the
+// repository last sent the digest of, zero when they never did.
+func (s *Store) DigestWeek(owner string, repo string) (time.Time, error) {
+	var week time.Time
+	err := s.db.QueryRow(`SELECT week FROM digests WHERE owner = ? AND repo = ?`, owner, repo).Scan(&week)
+	if err == sql.ErrNoRows {
+		return time.Time{}, nil
+	}
+	return week, err
+}
+
+// SaveDigestWeek records the start of the week the notifications of the
+// repository sent the digest of.
+func (s *Store) SaveDigestWeek(owner string, repo string, week time.Time) error {
+	_, err := s.db.Exec(`INSERT OR REPLACE INTO digests (owner, repo, week) VALUES (?, ?, ?)`, owner, repo, week.UTC())
+	return err
+}
+
+// PrunePRs deletes the stored PRs of the repository merged before the time
+// and returns how many it deleted.
+func (s *Store) PrunePRs(owner string, repo string, before time.Time) (int, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	// The times are compared here rather than in SQL, where they are text
+	rows, err := tx.Query(`SELECT number, merged_at FROM prs WHERE owner = ? AND repo = ?`, owner, repo)
+	if err != nil {
+		return 0, err
+	}
+	var numbers []int
+	for rows.Next() {
+		var number int
+		var mergedAt time.Time
+		if err := rows.Scan(&number, &mergedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		if mergedAt.Before(before) {
+			numbers = append(numbers, number)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, number := range numbers {
+		if _, err := tx.Exec(`DELETE FROM prs WHERE owner = ? AND repo = ? AND number = ?`, owner, repo, number); err != nil {
+			return 0, fmt.Errorf("deleting PR #%d: %w", number, err)
+		}
+	}
+	return len(numbers), tx.Commit()
+}
+
+// Vacuum gives the space of the deleted PRs back to the file system.
+func (s *Store) Vacuum() error {
+	_, err := s.db.Exec(`VACUUM`)
+	return err
+}
+
+// Repository is a repository the store has PRs of.
+type Repository struct {
+	Owner string
+	Repo  string
+}
+
+// Repositories returns the repositories the store has PRs of, by name.
+func (s *Store) Repositories() ([]Repository, error) {
+	rows, err := s.db.Query(`SELECT DISTINCT owner, repo FROM prs ORDER BY owner, repo`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var repos []Repository
+	for rows.Next() {
+		var r Repository
+		if err := rows.Scan(&r.Owner, &r.Repo); err != nil {
+			return nil, err
+		}
+		repos = append(repos, r)
+	}
+	return repos, rows.Err()
+}
+
+// Pins returns the repositories the user pinned on the dashboard, in the
+// order they were pinned in.
+func (s *Store) Pins(login string) ([]Repository, error) {
+	rows, err := s.db.Query(`SELECT owner, repo FROM pins WHERE login = ? ORDER BY position`, login)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var repos []Repository
+	for rows.Next() {
+		var r Repository
+		if err := rows.Scan(&r.Owner, &r.Repo); err != nil {
+			return nil, err
+		}
+		repos = append(repos, r)
+	}
+	return repos, rows.Err()
+}
+
+// SavePins replaces the repositories the user pinned.
+func (s *Store) SavePins(login string, repos []Repository) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM pins WHERE login = ?`, login); err != nil {
+		return err
+	}
+	for i, r := range repos {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO pins (login, position, owner, repo) VALUES (?, ?, ?, ?)`, login, i, r.Owner, r.Repo); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
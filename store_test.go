@@ -0,0 +1,47 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestQueryPRs(t *testing.T) {
+	store, err := openStore(filepath.Join(t.TempDir(), "store.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	if err := store.SavePRs("owner", "repo", []PRInfo{{Number: 1, Title: "feat: a"}, {Number: 2, Title: "fix: b"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		where   string
+		want    int
+		wantErr bool
+	}{
+		{name: "all", want: 2},
+		{name: "condition", where: "title LIKE 'fix:%'", want: 1},
+		{name: "invalid condition", where: "title LIKE", wantErr: true},
+		{name: "write", where: "1); DELETE FROM prs; SELECT data FROM prs WHERE (1", wantErr: true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			prs, err := store.QueryPRs("owner", "repo", tc.where)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got the error %v, want an error: %t", err, tc.wantErr)
+			}
+			if len(prs) != tc.want {
+				t.Errorf("got %d PRs, want %d", len(prs), tc.want)
+			}
+		})
+	}
+
+	// The store is left as it was and writable
+	if err := store.SavePRs("owner", "repo", []PRInfo{{Number: 3}}); err != nil {
+		t.Fatal(err)
+	}
+	if prs, err := store.QueryPRs("owner", "repo", ""); err != nil || len(prs) != 3 {
+		t.Errorf("got %d PRs (%v), want 3", len(prs), err)
+	}
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorReset = "\033[0m"
+)
+
+// maxTitleWidth is the width the PR titles are truncated to in the table.
+const maxTitleWidth = 40
+
+var detailsHeader = []string{"PR", "Title", "Creator", "Merger", "Created", "First response", "First human response", "Merge time", "Commits", "Comments", "Reviewers"}
+
+// printDetails prints one aligned table row per PR. When color is set, the
+// PRs that breached the SLA are red and the ones that met it and merged
+// faster than the average are green. The PRs are named with their repository
+// when they come from several.
+func printDetails(w io.Writer, prs []PRInfo, breached map[string]bool, averageMergeTime time.Duration, color bool) {
+	grouped := false
+	for _, pr := range prs {
+		grouped = grouped || pr.Repository != prs[0].Repository
+	}
+	rows := [][]string{detailsHeader}
+	for _, pr := range prs {
+		name := "#" + strconv.Itoa(pr.Number)
+		if grouped {
+			name = prReference(pr.Repository, pr.Number)
+		}
+		firstHumanResponse := "-"
+		if pr.FirstHumanResponder != "" {
+			firstHumanResponse = formatDuration(pr.TimeToFirstHumanResponse) + " by " + pr.FirstHumanResponder
+		}
+		rows = append(rows, []string{
+			name,
+			truncate(pr.Title, maxTitleWidth),
+			pr.Creator,
+			pr.Merger,
+			pr.CreatedAt.Format("2006-01-02 15:04"),
+			formatDuration(pr.TimeToFirstResponse),
+			firstHumanResponse,
+			formatDuration(pr.Duration),
+			strconv.Itoa(pr.Commits),
+			strconv.Itoa(commentCount(pr)),
+			strconv.Itoa(len(uniqueReviewers(pr))),
+		})
+	}
+
+	for i, text := range alignColumns(rows) {
+		if color && i > 0 {
+			pr := prs[i-1]
+			switch {
+			case breached[prReference(pr.Repository, pr.Number)]:
+				text = colorRed + text + colorReset
+			case pr.Duration < averageMergeTime:
+				text = colorGreen + text + colorReset
+			}
+		}
+		fmt.Fprintln(w, text)
+	}
+}
+
+// alignColumns pads the cells of the rows to the width of their column, two
+// spaces apart, and returns the lines.
+func alignColumns(rows [][]string) []string {
+	var widths []int
+	for _, row := range rows {
+		for i, cell := range row {
+			if i == len(widths) {
+				widths = append(widths, 0)
+			}
+			widths[i] = max(widths[i], utf8.RuneCountInString(cell))
+		}
+	}
+
+	lines := make([]string, len(rows))
+	for i, row := range rows {
+		var line strings.Builder
+		for j, cell := range row {
+			if j > 0 {
+				line.WriteString("  ")
+			}
+			line.WriteString(cell)
+			if j < len(row)-1 {
+				line.WriteString(strings.Repeat(" ", widths[j]-utf8.RuneCountInString(cell)))
+			}
+		}
+		lines[i] = line.String()
+	}
+	return lines
+}
+
+// truncate shortens s to width runes, marking the cut with an ellipsis.
+func truncate(s string, width int) string {
+	if utf8.RuneCountInString(s) <= width {
+		return s
+	}
+	return string([]rune(s)[:width-1]) + "…"
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// TeamReviews tells how many of the reviews of the PRs created by a team
+// came from people outside of it.
+type TeamReviews struct {
+	Team      string
+	Reviews   int
+	CrossTeam int
+	Share     float64
+}
+
+// crossTeamReviews returns the share of the reviews done by someone outside
+// the team of the PR creator, overall and per team of the creators, biggest
+// team first. teams maps the users to their team; reviews where the creator
+// or the reviewer has no team are left out.
+func crossTeamReviews(prData []PRInfo, teams map[string]string) (float64, []TeamReviews) {
+	if len(teams) == 0 {
+		return 0, nil
+	}
+
+	perTeam := make(map[string]*TeamReviews)
+	var reviews, crossTeam int
+	for _, pr := range prData {
+		team, ok := teams[pr.Creator]
+		if !ok {
+			continue
+		}
+		for _, reviewer := range pr.Reviewers {
+			reviewerTeam, ok := teams[reviewer]
+			if !ok || reviewer == pr.Creator {
+				continue
+			}
+			if perTeam[team] == nil {
+				perTeam[team] = &TeamReviews{Team: team}
+			}
+			perTeam[team].Reviews++
+			reviews++
+			if reviewerTeam != team {
+				perTeam[team].CrossTeam++
+				crossTeam++
+			}
+		}
+	}
+	if reviews == 0 {
+		return 0, nil
+	}
+
+	result := make([]TeamReviews, 0, len(perTeam))
+	for _, t := range perTeam {
+		t.Share = float64(t.CrossTeam) / float64(t.Reviews)
+		result = append(result, *t)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Reviews != result[j].Reviews {
+			return result[i].Reviews > result[j].Reviews
+		}
+		return result[i].Team < result[j].Team
+	})
+	return float64(crossTeam) / float64(reviews), result
+}
+
+func printCrossTeamReviews(w io.Writer, share float64, perTeam []TeamReviews) {
+	if len(perTeam) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "Cross-team reviews: %.1f%% of the reviews came from outside the team of the creator\n", share*100)
+	for _, team := range perTeam {
+		fmt.Fprintf(w, "  %s: %.1f%% of %d reviews\n", team.Team, team.Share*100, team.Reviews)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// TeamView configures the page of a team of the teams mapping in server
+// mode:
+//
+//	"teamViews": {
+//	  "platform": {"repos": ["codeready-toolchain/host-operator"], "sla": {"firstResponse": "4h"}}
+//	}
+type TeamView struct {
+	// Repos are the repositories of the team, as owner/repo, all the ones
+	// its members created PRs in when empty.
+	Repos []string `json:"repos"`
+	// SLA replaces the SLA of the configuration for the PRs of the team.
+	SLA *SLA `json:"sla"`
+}
+
+// teamRepository is a repository on the page of a team, reporting the PRs the
+// members of the team created in the quarter of its latest report.
+type teamRepository struct {
+	Report
+	// Reviewers are the members of the team on the reviewer leaderboard.
+	Reviewers []LeaderboardEntry
+}
+
+// teamPage is the page of a team.
+type teamPage struct {
+	Team    string
+	Members []string
+	SLA     SLA
+	Repos   []teamRepository
+}
+
+var teamFuncs = template.FuncMap{
+	"duration": formatDuration,
+	"percent":  siteFuncs["percent"],
+	"slaSet":   siteFuncs["slaSet"],
+	"target": func(d configDuration) string {
+		if d == 0 {
+			return "-"
+		}
+		return humanDuration(time.Duration(d))
+	},
+}
+
+var teamIndex = template.Must(template.New("teams").Funcs(teamFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Teams</title>` + siteStyle + `</head>
+<body>
+<p><a href="/">Dashboard</a></p>
+<h1>Teams</h1>
+<ul>
+{{range .}}<li><a href="/team/{{.}}">{{.}}</a></li>
+{{else}}<li>No teams in the configuration.</li>
+{{end}}</ul>
+</body>
+</html>
+`))
+
+var teamReport = template.Must(template.New("team").Funcs(teamFuncs).Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Team}}</title>` + siteStyle + `</head>
+<body>
+<p><a href="/team/">All teams</a></p>
+<h1>{{.Team}}</h1>
+<p>Members: {{range $i, $m := .Members}}{{if $i}}, {{end}}{{$m}}{{end}}</p>
+{{if slaSet .SLA}}<h2>SLA</h2>
+<table>
+<tr><th>Labels</th><th>First response</th><th>Merge</th></tr>
+<tr><td>All PRs</td><td>{{target .SLA.FirstResponse}}</td><td>{{target .SLA.Merge}}</td></tr>
+{{range $label, $targets := .SLA.Labels}}<tr><td>{{$label}}</td><td>{{target $targets.FirstResponse}}</td><td>{{target $targets.Merge}}</td></tr>
+{{end}}</table>
+{{end}}{{range .Repos}}<h2>{{.Owner}}/{{.Repo}} {{.Year}} {{.Quarter}}</h2>
+<table>
+<tr><th>PRs</th><td>{{len .PRs}}</td></tr>
+<tr><th>Average merge time</th><td>{{duration .AverageMergeTime}}</td></tr>
+<tr><th>Average time to first human response</th><td>{{duration .AverageTimeToFirstHumanResponse}}</td></tr>
+<tr><th>Average number of reviewers per PR</th><td>{{printf "%.1f" .AverageNumberOfReviewers}}</td></tr>
+{{if slaSet .SLA}}<tr><th>SLA compliance</th><td>{{percent .SLACompliance}}</td></tr>
+{{end}}</table>
+{{if .Reviewers}}<h3>Reviewers</h3>
+<table>
+{{range .Reviewers}}<tr><td>{{.Rank}}.</td><td>{{.Name}}</td><td>{{.Count}}</td><td>{{percent .Share}}</td></tr>
+{{end}}</table>
+{{end}}{{if .SLABreaches}}<h3>SLA breaches</h3>
+<table>
+<tr><th>PR</th><th>Title</th><th>Target</th><th>Took</th></tr>
+{{range .SLABreaches}}<tr><td>#{{.Number}}</td><td>{{.Title}}</td><td>{{.Metric}} in {{duration .Target}}</td><td>{{duration .Actual}}</td></tr>
+{{end}}</table>
+{{end}}{{else}}<p>The members of the team have no PRs in the latest reports.</p>
+{{end}}</body>
+</html>
+`))
+
+// teamNames returns the teams of the mapping and of the team views, by name.
+func teamNames(teams map[string]string, views map[string]TeamView) []string {
+	names := make(map[string]bool)
+	for _, team := range teams {
+		names[team] = true
+	}
+	for team := range views {
+		names[team] = true
+	}
+	return sortedKeys(names)
+}
+
+// newTeamPage reports the PRs the members of the team created in the
+// repositories of the team, in the quarter of the latest report of each.
+// latest are the latest reports and history the PRs of the latest runs, by
+// "owner/repo".
+func newTeamPage(team string, latest map[string]Report, history map[string][]PRInfo, opts reportOptions, view TeamView) teamPage {
+	page := teamPage{Team: team, SLA: opts.SLA}
+	if view.SLA != nil {
+		page.SLA = *view.SLA
+	}
+	members := make(map[string]bool)
+	for login, t := range opts.Teams {
+		if t == team {
+			members[login] = true
+		}
+	}
+	page.Members = sortedKeys(members)
+
+	repos := view.Repos
+	if len(repos) == 0 {
+		repos = sortedKeys(latest)
+	}
+	opts.SLA = page.SLA
+	for _, key := range repos {
+		r, ok := latest[key]
+		if !ok {
+			continue
+		}
+		var prs []PRInfo
+		for _, pr := range history[key] {
+			if members[pr.Creator] && pr.Year == r.Year && pr.Quarter == r.Quarter {
+				prs = append(prs, pr)
+			}
+		}
+		if len(prs) == 0 {
+			continue
+		}
+		repo := teamRepository{Report: newReport(r.Owner, r.Repo, r.Year, r.Quarter, prs, opts)}
+		sortBreaches(repo.SLABreaches)
+		for _, entry := range leaderboard(prs, prReviewers, 0) {
+			if members[entry.Name] {
+				repo.Reviewers = append(repo.Reviewers, entry)
+			}
+		}
+		page.Repos = append(page.Repos, repo)
+	}
+	return page
+}
+
+// serveTeam answers /team/ with the list of the teams and /team/{team} with
+// the page of the team, only reporting the repositories the user can read.
+func (s *reportServer) serveTeam(w http.ResponseWriter, req *http.Request) {
+	name := strings.TrimPrefix(req.URL.Path, "/team/")
+	teams := teamNames(s.reportOpts.Teams, s.teamViews)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if name == "" {
+		teamIndex.Execute(w, teams)
+		return
+	}
+	if i := sort.SearchStrings(teams, name); i == len(teams) || teams[i] != name {
+		http.NotFound(w, req)
+		return
+	}
+
+	s.mu.RLock()
+	latest := make(map[string]Report)
+	history := make(map[string][]PRInfo)
+	for key, r := range s.latest {
+		latest[key] = r
+		history[key] = s.history[key]
+	}
+	s.mu.RUnlock()
+	for key, r := range latest {
+		if !s.canRead(req, r.Owner, r.Repo) {
+			delete(latest, key)
+		}
+	}
+	if err := teamReport.Execute(w, newTeamPage(name, latest, history, s.reportOpts, s.teamViews[name])); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
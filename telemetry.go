@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpmetric/otlpmetrichttp"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/metric"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// instrumentation is the name the traces and metrics are recorded under.
+const instrumentation = "github.com/drpaneas/time2review"
+
+// setupTelemetry exports the traces and metrics over OTLP/HTTP to the
+// endpoint, such as http://localhost:4318. The returned function flushes and
+// stops the export.
+func setupTelemetry(ctx context.Context, endpoint string) (func(context.Context) error, error) {
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName("time2review")))
+	if err != nil {
+		return nil, err
+	}
+
+	traceExporter, err := otlptracehttp.New(ctx, otlptracehttp.WithEndpointURL(endpoint))
+	if err != nil {
+		return nil, err
+	}
+	tracerProvider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(traceExporter), sdktrace.WithResource(res))
+
+	metricExporter, err := otlpmetrichttp.New(ctx, otlpmetrichttp.WithEndpointURL(endpoint))
+	if err != nil {
+		tracerProvider.Shutdown(ctx)
+		return nil, err
+	}
+	meterProvider := sdkmetric.NewMeterProvider(sdkmetric.WithReader(sdkmetric.NewPeriodicReader(metricExporter)), sdkmetric.WithResource(res))
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetMeterProvider(meterProvider)
+	return func(ctx context.Context) error {
+		return errors.Join(tracerProvider.Shutdown(ctx), meterProvider.Shutdown(ctx))
+	}, nil
+}
+
+func tracer() trace.Tracer {
+	return otel.Tracer(instrumentation)
+}
+
+// prSpans traces the collection of the PRs one at a time, recording how long
+// each one took. A span ends when the next one starts, as the collection
+// skips to the next PR on errors.
+type prSpans struct {
+	span    trace.Span
+	started time.Time
+}
+
+// start ends the span of the previous PR and returns the context of the
+// span of the PR.
+func (s *prSpans) start(ctx context.Context, number int) context.Context {
+	s.end()
+	ctx, s.span = tracer().Start(ctx, "collect PR", trace.WithAttributes(attribute.Int("pr.number", number)))
+	s.started = time.Now()
+	return ctx
+}
+
+func (s *prSpans) end() {
+	if s.span == nil {
+		return
+	}
+	s.span.End()
+	s.span = nil
+	if histogram, err := otel.Meter(instrumentation).Float64Histogram("time2review.pr.duration", metric.WithUnit("s"), metric.WithDescription("Time it took to collect the data of a PR")); err == nil {
+		histogram.Record(context.Background(), time.Since(s.started).Seconds())
+	}
+}
+
+// tracingClient records a span and the duration of every call made through
+// it. It sits right above the API, so that every retry is a call of its own.
+type tracingClient struct {
+	next     GitHubClient
+	calls    metric.Int64Counter
+	duration metric.Float64Histogram
+}
+
+func newTracingClient(next GitHubClient) (*tracingClient, error) {
+	meter := otel.Meter(instrumentation)
+	calls, err := meter.Int64Counter("time2review.github.calls", metric.WithDescription("Number of GitHub API calls"))
+	if err != nil {
+		return nil, err
+	}
+	duration, err := meter.Float64Histogram("time2review.github.duration", metric.WithUnit("s"), metric.WithDescription("Duration of the GitHub API calls"))
+	if err != nil {
+		return nil, err
+	}
+	return &tracingClient{next: next, calls: calls, duration: duration}, nil
+}
+
+func (c *tracingClient) ListPRs(ctx context.Context, owner string, repo string, opts *github.PullRequestListOptions) ([]*github.PullRequest, *github.Response, error) {
+	return traceCall(ctx, c, "ListPRs", 0, func(ctx context.Context) ([]*github.PullRequest, *github.Response, error) {
+		return c.next.ListPRs(ctx, owner, repo, opts)
+	})
+}
+
+func (c *tracingClient) GetPR(ctx context.Context, owner string, repo string, number int) (*github.PullRequest, *github.Response, error) {
+	return traceCall(ctx, c, "GetPR", number, func(ctx context.Context) (*github.PullRequest, *github.Response, error) {
+		return c.next.GetPR(ctx, owner, repo, number)
+	})
+}
+
+func (c *tracingClient) ListComments(ctx context.Context, owner string, repo string, number int, opts *github.IssueListCommentsOptions) ([]*github.IssueComment, *github.Response, error) {
+	return traceCall(ctx, c, "ListComments", number, func(ctx context.Context) ([]*github.IssueComment, *github.Response, error) {
+		return c.next.ListComments(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *tracingClient) ListReviewComments(ctx context.Context, owner string, repo string, number int, opts *github.PullRequestListCommentsOptions) ([]*github.PullRequestComment, *github.Response, error) {
+	return traceCall(ctx, c, "ListReviewComments", number, func(ctx context.Context) ([]*github.PullRequestComment, *github.Response, error) {
+		return c.next.ListReviewComments(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *tracingClient) ListReviews(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.PullRequestReview, *github.Response, error) {
+	return traceCall(ctx, c, "ListReviews", number, func(ctx context.Context) ([]*github.PullRequestReview, *github.Response, error) {
+		return c.next.ListReviews(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *tracingClient) ListCommits(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.RepositoryCommit, *github.Response, error) {
+	return traceCall(ctx, c, "ListCommits", number, func(ctx context.Context) ([]*github.RepositoryCommit, *github.Response, error) {
+		return c.next.ListCommits(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *tracingClient) ListTimeline(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.Timeline, *github.Response, error) {
+	return traceCall(ctx, c, "ListTimeline", number, func(ctx context.Context) ([]*github.Timeline, *github.Response, error) {
+		return c.next.ListTimeline(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *tracingClient) ListFiles(ctx context.Context, owner string, repo string, number int, opts *github.ListOptions) ([]*github.CommitFile, *github.Response, error) {
+	return traceCall(ctx, c, "ListFiles", number, func(ctx context.Context) ([]*github.CommitFile, *github.Response, error) {
+		return c.next.ListFiles(ctx, owner, repo, number, opts)
+	})
+}
+
+func (c *tracingClient) GetIssue(ctx context.Context, owner string, repo string, number int) (*github.Issue, *github.Response, error) {
+	return traceCall(ctx, c, "GetIssue", number, func(ctx context.Context) (*github.Issue, *github.Response, error) {
+		return c.next.GetIssue(ctx, owner, repo, number)
+	})
+}
+
+func (c *tracingClient) ListReleases(ctx context.Context, owner string, repo string, opts *github.ListOptions) ([]*github.RepositoryRelease, *github.Response, error) {
+	return traceCall(ctx, c, "ListReleases", 0, func(ctx context.Context) ([]*github.RepositoryRelease, *github.Response, error) {
+		return c.next.ListReleases(ctx, owner, repo, opts)
+	})
+}
+
+func (c *tracingClient) ListCheckRuns(ctx context.Context, owner string, repo string, ref string, opts *github.ListOptions) ([]*github.CheckRun, *github.Response, error) {
+	return traceCall(ctx, c, "ListCheckRuns", 0, func(ctx context.Context) ([]*github.CheckRun, *github.Response, error) {
+		return c.next.ListCheckRuns(ctx, owner, repo, ref, opts)
+	})
+}
+
+func (c *tracingClient) CreateCheckRun(ctx context.Context, owner string, repo string, opts github.CreateCheckRunOptions) (*github.CheckRun, *github.Response, error) {
+	return traceCall(ctx, c, "CreateCheckRun", 0, func(ctx context.Context) (*github.CheckRun, *github.Response, error) {
+		return c.next.CreateCheckRun(ctx, owner, repo, opts)
+	})
+}
+
+func (c *tracingClient) CreateStatus(ctx context.Context, owner string, repo string, ref string, status *github.RepoStatus) (*github.RepoStatus, *github.Response, error) {
+	return traceCall(ctx, c, "CreateStatus", 0, func(ctx context.Context) (*github.RepoStatus, *github.Response, error) {
+		return c.next.CreateStatus(ctx, owner, repo, ref, status)
+	})
+}
+
+func (c *tracingClient) CreateComment(ctx context.Context, owner string, repo string, number int, body string) (*github.IssueComment, *github.Response, error) {
+	return traceCall(ctx, c, "CreateComment", number, func(ctx context.Context) (*github.IssueComment, *github.Response, error) {
+		return c.next.CreateComment(ctx, owner, repo, number, body)
+	})
+}
+
+func (c *tracingClient) GetAuthenticatedUser(ctx context.Context) (*github.User, *github.Response, error) {
+	return traceCall(ctx, c, "GetAuthenticatedUser", 0, func(ctx context.Context) (*github.User, *github.Response, error) {
+		return c.next.GetAuthenticatedUser(ctx)
+	})
+}
+
+func (c *tracingClient) EditComment(ctx context.Context, owner string, repo string, id int64, body string) (*github.IssueComment, *github.Response, error) {
+	return traceCall(ctx, c, "EditComment", 0, func(ctx context.Context) (*github.IssueComment, *github.Response, error) {
+		return c.next.EditComment(ctx, owner, repo, id, body)
+	})
+}
+
+func (c *tracingClient) ListIssues(ctx context.Context, owner string, repo string, opts *github.IssueListByRepoOptions) ([]*github.Issue, *github.Response, error) {
+	return traceCall(ctx, c, "ListIssues", 0, func(ctx context.Context) ([]*github.Issue, *github.Response, error) {
+		return c.next.ListIssues(ctx, owner, repo, opts)
+	})
+}
+
+func (c *tracingClient) CreateIssue(ctx context.Context, owner string, repo string, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return traceCall(ctx, c, "CreateIssue", 0, func(ctx context.Context) (*github.Issue, *github.Response, error) {
+		return c.next.CreateIssue(ctx, owner, repo, issue)
+	})
+}
+
+func (c *tracingClient) EditIssue(ctx context.Context, owner string, repo string, number int, issue *github.IssueRequest) (*github.Issue, *github.Response, error) {
+	return traceCall(ctx, c, "EditIssue", number, func(ctx context.Context) (*github.Issue, *github.Response, error) {
+		return c.next.EditIssue(ctx, owner, repo, number, issue)
+	})
+}
+
+// traceCall makes the call in a span named after the method. number is the
+// PR or issue the call is about, 0 when it is about none.
+func traceCall[T any](ctx context.Context, c *tracingClient, method string, number int, call func(context.Context) (T, *github.Response, error)) (T, *github.Response, error) {
+	attrs := []attribute.KeyValue{attribute.String("github.method", method)}
+	if number > 0 {
+		attrs = append(attrs, attribute.Int("github.number", number))
+	}
+	ctx, span := tracer().Start(ctx, "GitHub "+method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	defer span.End()
+
+	started := time.Now()
+	result, resp, err := call(ctx)
+	if resp != nil && resp.Response != nil {
+		span.SetAttributes(semconv.HTTPResponseStatusCode(resp.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	outcome := metric.WithAttributes(attribute.String("github.method", method), attribute.Bool("error", err != nil))
+	c.calls.Add(ctx, 1, outcome)
+	c.duration.Record(ctx, time.Since(started).Seconds(), outcome)
+	return result, resp, err
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are available in user-defined report templates next to the
+// builtin text/template functions.
+var templateFuncs = template.FuncMap{
+	"join":             strings.Join,
+	"hours":            hours,
+	"duration":         formatDuration,
+	"mergeTimePerWeek": mergeTimePerWeekSparkline,
+	"prsPerWeekday":    prsPerWeekdayBarChart,
+}
+
+// loadTemplate parses the report template at path. The template is executed
+// once per quarter with the Report of that quarter as its data.
+func loadTemplate(path string) (*template.Template, error) {
+	return template.New(filepath.Base(path)).Funcs(templateFuncs).ParseFiles(path)
+}
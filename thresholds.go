@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// thresholdMetric is a metric of the report a threshold can be set on.
+type thresholdMetric struct {
+	// duration is set for the metrics compared with durations such as 24h,
+	// the others are compared with plain numbers.
+	duration bool
+	value    func(r Report) float64
+}
+
+func durationMetric(value func(r Report) time.Duration) thresholdMetric {
+	return thresholdMetric{duration: true, value: func(r Report) float64 { return float64(value(r)) }}
+}
+
+func numberMetric(value func(r Report) float64) thresholdMetric {
+	return thresholdMetric{value: value}
+}
+
+var thresholdMetrics = map[string]thresholdMetric{
+	"prs":                           numberMetric(func(r Report) float64 { return float64(len(r.PRs)) }),
+	"avg_merge_time":                durationMetric(func(r Report) time.Duration { return r.AverageMergeTime }),
+	"avg_first_human_response":      durationMetric(func(r Report) time.Duration { return r.AverageTimeToFirstHumanResponse }),
+	"avg_first_bot_response":        durationMetric(func(r Report) time.Duration { return r.AverageTimeToFirstBotResponse }),
+	"weighted_merge_time":           durationMetric(func(r Report) time.Duration { return r.SizeWeightedMergeTime }),
+	"weighted_first_human_response": durationMetric(func(r Report) time.Duration { return r.SizeWeightedTimeToFirstHumanResponse }),
+	"avg_lead_time":                 durationMetric(func(r Report) time.Duration { return r.AverageLeadTime }),
+	"avg_time_in_draft":             durationMetric(func(r Report) time.Duration { return r.AverageTimeInDraft }),
+	"avg_ci_time":                   durationMetric(func(r Report) time.Duration { return r.AverageCITime }),
+	"avg_human_wait_time":           durationMetric(func(r Report) time.Duration { return r.AverageHumanWaitTime }),
+	"avg_green_to_merge":            durationMetric(func(r Report) time.Duration { return r.AverageGreenToMerge }),
+	"avg_comments":                  numberMetric(func(r Report) float64 { return r.AverageNumberOfComments }),
+	"avg_commenters":                numberMetric(func(r Report) float64 { return r.AverageNumberOfCommenters }),
+	"avg_reviewers":                 numberMetric(func(r Report) float64 { return r.AverageNumberOfReviewers }),
+	"avg_reviews":                   numberMetric(func(r Report) float64 { return r.AverageNumberOfReviews }),
+	"avg_commits":                   numberMetric(func(r Report) float64 { return r.AverageNumberOfCommits }),
+	"avg_review_rounds":             numberMetric(func(r Report) float64 { return r.AverageReviewRounds }),
+	"self_merge_rate":               numberMetric(func(r Report) float64 { return r.SelfMergeRate }),
+	"changes_requested_rate":        numberMetric(func(r Report) float64 { return r.ChangesRequestedRate }),
+	"cross_team_review_share":       numberMetric(func(r Report) float64 { return r.CrossTeamReviewShare }),
+	"sla_compliance":                numberMetric(func(r Report) float64 { return r.SLACompliance }),
+}
+
+// thresholdOperators are the comparisons of a threshold, the two character
+// ones first so that ">=" is not taken for ">".
+var thresholdOperators = []string{">=", "<=", "==", "!=", ">", "<"}
+
+// threshold is a condition such as "avg_first_human_response > 24h" that
+// fails the run when a report meets it.
+type threshold struct {
+	expr     string
+	metric   string
+	operator string
+	value    float64
+}
+
+func parseThreshold(expr string) (threshold, error) {
+	for _, operator := range thresholdOperators {
+		name, value, ok := strings.Cut(expr, operator)
+		if !ok {
+			continue
+		}
+		t := threshold{expr: strings.TrimSpace(expr), metric: strings.TrimSpace(name), operator: operator}
+		metric, ok := thresholdMetrics[t.metric]
+		if !ok {
+			names := make([]string, 0, len(thresholdMetrics))
+			for name := range thresholdMetrics {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return threshold{}, fmt.Errorf("unknown metric %q in %q, expected one of %s", t.metric, expr, strings.Join(names, ", "))
+		}
+
+		value = strings.TrimSpace(value)
+		if metric.duration {
+			d, err := parseShortDuration(value)
+			if err != nil {
+				return threshold{}, fmt.Errorf("invalid duration in %q: %w", expr, err)
+			}
+			t.value = float64(d)
+		} else if percent, ok := strings.CutSuffix(value, "%"); ok {
+			f, err := strconv.ParseFloat(percent, 64)
+			if err != nil {
+				return threshold{}, fmt.Errorf("invalid percentage in %q: %w", expr, err)
+			}
+			t.value = f / 100
+		} else {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return threshold{}, fmt.Errorf("invalid number in %q: %w", expr, err)
+			}
+			t.value = f
+		}
+		return t, nil
+	}
+	return threshold{}, fmt.Errorf("missing the comparison in %q, expected a metric, one of %s and a value", expr, strings.Join(thresholdOperators, " "))
+}
+
+// exceeded reports whether the report meets the condition of the threshold,
+// along with the value of the metric as written in the reports.
+func (t threshold) exceeded(r Report) (bool, string) {
+	metric := thresholdMetrics[t.metric]
+	value := metric.value(r)
+	actual := strconv.FormatFloat(value, 'f', -1, 64)
+	if metric.duration {
+		actual = formatDuration(time.Duration(value))
+	}
+
+	switch t.operator {
+	case ">=":
+		return value >= t.value, actual
+	case "<=":
+		return value <= t.value, actual
+	case "==":
+		return value == t.value, actual
+	case "!=":
+		return value != t.value, actual
+	case ">":
+		return value > t.value, actual
+	default:
+		return value < t.value, actual
+	}
+}
+
+// checkThresholds checks the thresholds on the report of the quarter of now,
+// logging an error for each one the report meets, and returns the ones it
+// meets. None are checked when the quarter has no PRs.
+func checkThresholds(owner string, repo string, prInfos []PRInfo, thresholds thresholdList, opts reportOptions, now time.Time) []threshold {
+	if len(thresholds) == 0 {
+		return nil
+	}
+	year, quarter := getYearAndQuarter(now)
+	current := filterPRInfosByQuarterAndYear(prInfos, year, quarter)
+	if len(current) == 0 {
+		slog.Warn("No PRs of the current quarter, the --fail-if thresholds are not checked", "year", year, "quarter", quarter)
+		return nil
+	}
+
+	var met []threshold
+	report := newReport(owner, repo, year, quarter, current, opts)
+	for _, t := range thresholds {
+		if exceeded, actual := t.exceeded(report); exceeded {
+			slog.Error("Threshold exceeded", "year", year, "quarter", quarter, "threshold", t.expr, "actual", actual)
+			met = append(met, t)
+		}
+	}
+	return met
+}
+
+// thresholdList is the --fail-if flag, which can be given several times.
+type thresholdList []threshold
+
+func (l *thresholdList) String() string {
+	exprs := make([]string, len(*l))
+	for i, t := range *l {
+		exprs[i] = t.expr
+	}
+	return strings.Join(exprs, ", ")
+}
+
+func (l *thresholdList) Set(value string) error {
+	t, err := parseThreshold(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, t)
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseThreshold(t *testing.T) {
+	for _, tc := range []struct {
+		expr     string
+		metric   string
+		operator string
+		value    float64
+		wantErr  bool
+	}{
+		{expr: "avg_first_human_response > 24h", metric: "avg_first_human_response", operator: ">", value: float64(24 * time.Hour)},
+		{expr: "avg_merge_time>=3d", metric: "avg_merge_time", operator: ">=", value: float64(3 * 24 * time.Hour)},
+		{expr: "sla_compliance < 90%", metric: "sla_compliance", operator: "<", value: 0.9},
+		{expr: "avg_reviewers <= 1.5", metric: "avg_reviewers", operator: "<=", value: 1.5},
+		{expr: "prs == 0", metric: "prs", operator: "==", value: 0},
+		{expr: "avg_merge_time > soon", wantErr: true},
+		{expr: "avg_reviewers > many", wantErr: true},
+		{expr: "happiness > 1", wantErr: true},
+		{expr: "avg_merge_time", wantErr: true},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			got, err := parseThreshold(tc.expr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("got the error %v, want an error: %t", err, tc.wantErr)
+			}
+			if got.metric != tc.metric || got.operator != tc.operator || got.value != tc.value {
+				t.Errorf("got %s %s %v, want %s %s %v", got.metric, got.operator, got.value, tc.metric, tc.operator, tc.value)
+			}
+		})
+	}
+}
+
+func TestThresholdExceeded(t *testing.T) {
+	r := Report{AverageMergeTime: 36 * time.Hour, SelfMergeRate: 0.25}
+	for _, tc := range []struct {
+		expr       string
+		want       bool
+		wantActual string
+	}{
+		{expr: "avg_merge_time > 1d", want: true, wantActual: "36h0m0s"},
+		{expr: "avg_merge_time > 36h", want: false, wantActual: "36h0m0s"},
+		{expr: "avg_merge_time >= 36h", want: true, wantActual: "36h0m0s"},
+		{expr: "self_merge_rate != 25%", want: false, wantActual: "0.25"},
+		{expr: "self_merge_rate < 0.5", want: true, wantActual: "0.25"},
+	} {
+		t.Run(tc.expr, func(t *testing.T) {
+			threshold, err := parseThreshold(tc.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, actual := threshold.exceeded(r)
+			if got != tc.want || actual != tc.wantActual {
+				t.Errorf("got %t with %s, want %t with %s", got, actual, tc.want, tc.wantActual)
+			}
+		})
+	}
+}
+
+func TestCheckThresholds(t *testing.T) {
+	prInfos := collect(t, reportFixture(t), collectOptions{})
+	var thresholds thresholdList
+	for _, expr := range []string{"avg_merge_time > 12h", "avg_merge_time > 1d", "self_merge_rate >= 50%"} {
+		if err := thresholds.Set(expr); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		name string
+		now  string
+		want []string
+	}{
+		{name: "current quarter", now: "2024-02-15T12:00:00Z", want: []string{"avg_merge_time > 12h", "self_merge_rate >= 50%"}},
+		{name: "quarter without PRs", now: "2024-05-15T12:00:00Z"},
+		{name: "same quarter of another year", now: "2025-02-15T12:00:00Z"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			met := checkThresholds("owner", "repo", prInfos, thresholds, defaultReportOptions, parseTime(t, tc.now))
+			var got []string
+			for _, threshold := range met {
+				got = append(got, threshold.expr)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("got the thresholds %q, want %q", got, tc.want)
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("got the thresholds %q, want %q", got, tc.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// configLocation is a timezone written as its IANA name in the
+// configuration file, such as "Europe/Berlin".
+type configLocation struct {
+	*time.Location
+}
+
+func (l *configLocation) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err != nil {
+		return fmt.Errorf("timezones must be strings such as \"Europe/Berlin\": %w", err)
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", name, err)
+	}
+	l.Location = location
+	return nil
+}
+
+func (l configLocation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.String())
+}
+
+// localTime returns the time in the timezone of the user, in UTC when the
+// configuration has none for them.
+func (o collectOptions) localTime(login string, t time.Time) time.Time {
+	if location, ok := o.Timezones[login]; ok && location.Location != nil {
+		return t.In(location.Location)
+	}
+	return t.UTC()
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// updateStoredPRs collects the closed PRs of the repository updated since the
+// cursor of the store, most recently updated first, into the store. Only the
+// PRs merged or changed since the previous update are listed, which takes a
+// page or two a day, instead of the --max-prs most recently closed ones. The
+// cursor moves on once all of them are stored, so that an interrupted update
+// lists them again the next time. It returns the number of PRs it stored.
+func updateStoredPRs(ctx context.Context, client GitHubClient, owner string, repo string, store *Store, opts collectOptions, progress *progress) (int, error) {
+	cursor, err := store.Cursor(owner, repo)
+	if err != nil {
+		return 0, fmt.Errorf("loading the cursor: %w", err)
+	}
+	if cursor.IsZero() {
+		return 0, errors.New("the store has no PRs to update yet, fetch or backfill them first")
+	}
+	slog.Info("Listing the PRs updated since the previous update", "since", cursor)
+
+	opt := &github.PullRequestListOptions{State: "closed", Sort: "updated", Direction: "desc", ListOptions: github.ListOptions{PerPage: 100}}
+	issues := newIssueCache(client, owner, repo, opts.Calls)
+	var newest time.Time
+	added := 0
+	for ctx.Err() == nil {
+		prs, resp, err := client.ListPRs(ctx, owner, repo, opt)
+		if err != nil && ctx.Err() != nil {
+			break
+		}
+		if err != nil {
+			return added, fmt.Errorf("page %d: %w", opt.Page, err)
+		}
+		progress.pageFetched(len(prs))
+
+		// The PRs come most recently updated first, the ones updated before
+		// the cursor end the update
+		var batch []*github.PullRequest
+		reachedCursor := false
+		for _, pr := range prs {
+			if !pr.GetUpdatedAt().After(cursor) {
+				reachedCursor = true
+				break
+			}
+			if pr.GetUpdatedAt().After(newest) {
+				newest = pr.GetUpdatedAt()
+			}
+			batch = append(batch, pr)
+		}
+
+		prInfos := getMergeTimes(ctx, client, owner, repo, batch, issues, opts, progress)
+		if err := store.SavePRs(owner, repo, prInfos); err != nil {
+			return added, fmt.Errorf("storing page %d: %w", opt.Page, err)
+		}
+		added += len(prInfos)
+
+		if reachedCursor || resp.NextPage == 0 {
+			break
+		}
+		opt.Page = resp.NextPage
+	}
+	progress.done()
+
+	if ctx.Err() == nil && !newest.IsZero() {
+		if err := store.SaveCursor(owner, repo, newest); err != nil {
+			return added, fmt.Errorf("saving the cursor: %w", err)
+		}
+	}
+	return added, nil
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v32/github"
+)
+
+// waitingTimes splits the time from the PR being ready for review to its
+// merge into the time it waited on its author and the time it waited on the
+// reviewers. The PR waits on the reviewers until a review requests changes,
+// then on its author until the next push, then on the reviewers again. Once
+// approved it waits on nobody until a push asks for another review, and the
+// comments of a review do not change who it waits on. Both are measured on
+// the same clock, leaving out the weekends when configured to.
+func waitingTimes(pr PRInfo, reviews []*github.PullRequestReview, pushes []time.Time, opts collectOptions) (onAuthor time.Duration, onReviewers time.Duration) {
+	type event struct {
+		at    time.Time
+		state string
+	}
+	var events []event
+	for _, review := range reviews {
+		login := review.GetUser().GetLogin()
+		if strings.HasSuffix(login, "[bot]") || login == pr.Creator || review.SubmittedAt == nil {
+			continue
+		}
+		events = append(events, event{at: review.GetSubmittedAt(), state: review.GetState()})
+	}
+	for _, push := range pushes {
+		events = append(events, event{at: push, state: "pushed"})
+	}
+	sort.SliceStable(events, func(i, j int) bool { return events[i].at.Before(events[j].at) })
+
+	waitingOn := "reviewers"
+	since := pr.ReadyForReviewAt
+	if since.IsZero() {
+		since = pr.CreatedAt
+	}
+	wait := func(until time.Time) {
+		if until.After(pr.MergedAt) {
+			until = pr.MergedAt
+		}
+		if !until.After(since) {
+			return
+		}
+		switch waitingOn {
+		case "author":
+			onAuthor += opts.elapsed(since, until)
+		case "reviewers":
+			onReviewers += opts.elapsed(since, until)
+		}
+		since = until
+	}
+	for _, e := range events {
+		wait(e.at)
+		switch e.state {
+		case "CHANGES_REQUESTED":
+			waitingOn = "author"
+		case "APPROVED":
+			waitingOn = ""
+		case "pushed":
+			waitingOn = "reviewers"
+		}
+	}
+	wait(pr.MergedAt)
+	return onAuthor, onReviewers
+}
+
+// totalWaitingTimes adds up the time the PRs waited on their authors and on
+// the reviewers.
+func totalWaitingTimes(prData []PRInfo) (onAuthors time.Duration, onReviewers time.Duration) {
+	for _, pr := range prData {
+		onAuthors += pr.WaitingOnAuthor
+		onReviewers += pr.WaitingOnReviewers
+	}
+	return onAuthors, onReviewers
+}
+
+func printWaitingTimes(w io.Writer, onAuthors time.Duration, onReviewers time.Duration) {
+	share := 0.0
+	if total := onAuthors + onReviewers; total > 0 {
+		share = float64(onReviewers) / float64(total)
+	}
+	fmt.Fprintf(w, "Total time waiting on the authors: %s, on the reviewers: %s (%.1f%% on the reviewers)\n", formatDuration(onAuthors), formatDuration(onReviewers), share*100)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWaitingTimes(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		pr              testPR
+		opts            collectOptions
+		wantOnAuthor    time.Duration
+		wantOnReviewers time.Duration
+	}{
+		{
+			name:            "not reviewed",
+			pr:              testPR{merged: "2024-01-08T15:00:00Z"},
+			wantOnReviewers: 6 * time.Hour,
+		},
+		{
+			name: "approved",
+			pr: testPR{
+				merged:  "2024-01-08T15:00:00Z",
+				reviews: []testEvent{{login: "bob", state: "APPROVED", at: "2024-01-08T11:00:00Z"}},
+			},
+			wantOnReviewers: 2 * time.Hour,
+		},
+		{
+			name: "changes requested",
+			pr: testPR{
+				merged: "2024-01-08T18:00:00Z",
+				reviews: []testEvent{
+					{login: "bob", state: "CHANGES_REQUESTED", at: "2024-01-08T10:00:00Z"},
+					{login: "bob", state: "COMMENTED", at: "2024-01-08T11:00:00Z"},
+					{login: "bob", state: "APPROVED", at: "2024-01-08T16:00:00Z"},
+				},
+				pushes: []string{"2024-01-08T13:00:00Z"},
+			},
+			wantOnAuthor:    3 * time.Hour,
+			wantOnReviewers: 4 * time.Hour,
+		},
+		{
+			name: "pushed after the approval",
+			pr: testPR{
+				merged:  "2024-01-08T15:00:00Z",
+				reviews: []testEvent{{login: "bob", state: "APPROVED", at: "2024-01-08T10:00:00Z"}},
+				pushes:  []string{"2024-01-08T12:00:00Z"},
+			},
+			wantOnReviewers: 4 * time.Hour,
+		},
+		{
+			name: "over a weekend",
+			pr: testPR{
+				created: "2024-01-12T18:00:00Z",
+				merged:  "2024-01-15T06:00:00Z",
+				reviews: []testEvent{{login: "bob", state: "CHANGES_REQUESTED", at: "2024-01-12T20:00:00Z"}},
+				pushes:  []string{"2024-01-14T12:00:00Z"},
+			},
+			opts:            collectOptions{SkipWeekends: true},
+			wantOnAuthor:    4 * time.Hour,
+			wantOnReviewers: 8 * time.Hour,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.pr.number = 1
+			if tc.pr.created == "" {
+				tc.pr.created = "2024-01-08T09:00:00Z"
+			}
+			pr := collect(t, newFixture(t, tc.pr), tc.opts)[0]
+			if pr.WaitingOnAuthor != tc.wantOnAuthor || pr.WaitingOnReviewers != tc.wantOnReviewers {
+				t.Errorf("got %v on the author and %v on the reviewers, want %v and %v", pr.WaitingOnAuthor, pr.WaitingOnReviewers, tc.wantOnAuthor, tc.wantOnReviewers)
+			}
+		})
+	}
+}
+
+func TestTotalWaitingTimes(t *testing.T) {
+	onAuthors, onReviewers := totalWaitingTimes([]PRInfo{
+		{WaitingOnAuthor: time.Hour, WaitingOnReviewers: 2 * time.Hour},
+		{WaitingOnReviewers: 3 * time.Hour},
+	})
+	if onAuthors != time.Hour || onReviewers != 5*time.Hour {
+		t.Errorf("got %v on the authors and %v on the reviewers, want 1h and 5h", onAuthors, onReviewers)
+	}
+}
@@ -0,0 +1,359 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// whereFields are the text, list, boolean and time fields of the PRs the
+// --where conditions compare, next to the numbers and durations of
+// metricFields.
+var (
+	whereTextFields = map[string]func(pr PRInfo) string{
+		"title":                 func(pr PRInfo) string { return pr.Title },
+		"creator":               func(pr PRInfo) string { return pr.Creator },
+		"merger":                func(pr PRInfo) string { return pr.Merger },
+		"type":                  func(pr PRInfo) string { return pr.Type },
+		"milestone":             func(pr PRInfo) string { return pr.Milestone },
+		"quarter":               func(pr PRInfo) string { return pr.Quarter },
+		"first_responder":       func(pr PRInfo) string { return pr.FirstResponder },
+		"first_human_responder": func(pr PRInfo) string { return pr.FirstHumanResponder },
+		"size":                  sizeOf,
+	}
+	whereListFields = map[string]func(pr PRInfo) []string{
+		"labels":             func(pr PRInfo) []string { return pr.Labels },
+		"files":              func(pr PRInfo) []string { return pr.Files },
+		"reviewers":          uniqueReviewers,
+		"commenters":         uniqueCommenters,
+		"approvers":          func(pr PRInfo) []string { return pr.Approvers },
+		"changes_requesters": func(pr PRInfo) []string { return pr.ChangesRequesters },
+	}
+	whereBoolFields = map[string]func(pr PRInfo) bool{
+		"is_revert": func(pr PRInfo) bool { return pr.IsRevert },
+		"is_hotfix": func(pr PRInfo) bool { return pr.IsHotfix },
+		"is_bot":    func(pr PRInfo) bool { return strings.HasSuffix(pr.Creator, "[bot]") },
+	}
+	whereTimeFields = map[string]func(pr PRInfo) time.Time{
+		"created_at": func(pr PRInfo) time.Time { return pr.CreatedAt },
+		"merged_at":  func(pr PRInfo) time.Time { return pr.MergedAt },
+	}
+)
+
+// whereFilter is a --where condition such as
+// `creator != "dependabot[bot]" && duration > 72h && quarter == "Q2"`. A
+// condition compares a field with a value, tests a boolean field, or tests
+// whether a list field holds a value with `"bug" in labels`. Text fields are
+// also matched against regular expressions with =~. Conditions combine with
+// &&, || and !, and group with parentheses. Comparing a value a PR does not
+// have, such as the time to the first human response of the PRs nobody
+// responded to, is false.
+type whereFilter struct {
+	expr  string
+	match func(pr PRInfo) bool
+}
+
+func parseWhere(expr string) (whereFilter, error) {
+	tokens, err := whereTokens(expr)
+	if err != nil {
+		return whereFilter{}, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+	p := &whereParser{tokens: tokens}
+	match, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected %s", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return whereFilter{}, fmt.Errorf("invalid condition %q: %w", expr, err)
+	}
+	return whereFilter{expr: expr, match: match}, nil
+}
+
+// filter returns the PRs matching the condition.
+func (f whereFilter) filter(prs []PRInfo) []PRInfo {
+	var matching []PRInfo
+	for _, pr := range prs {
+		if f.match(pr) {
+			matching = append(matching, pr)
+		}
+	}
+	return matching
+}
+
+func (f *whereFilter) String() string {
+	return f.expr
+}
+
+func (f *whereFilter) Set(value string) error {
+	parsed, err := parseWhere(value)
+	if err != nil {
+		return err
+	}
+	*f = parsed
+	return nil
+}
+
+type whereToken struct {
+	// kind is "string" for the quoted strings, "word" for the field names and
+	// the other values, and the operator itself otherwise.
+	kind string
+	text string
+}
+
+var whereOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "<", ">", "!", "(", ")"}
+
+func whereTokens(expr string) ([]whereToken, error) {
+	var tokens []whereToken
+	for i := 0; i < len(expr); {
+		c := rune(expr[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case c == '"':
+			end := i + 1
+			for end < len(expr) && expr[end] != '"' {
+				if expr[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(expr) {
+				return nil, fmt.Errorf("unterminated string %s", expr[i:])
+			}
+			text, err := strconv.Unquote(expr[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string %s", expr[i:end+1])
+			}
+			tokens = append(tokens, whereToken{kind: "string", text: text})
+			i = end + 1
+		default:
+			operator := ""
+			for _, op := range whereOperators {
+				if strings.HasPrefix(expr[i:], op) {
+					operator = op
+					break
+				}
+			}
+			if operator != "" {
+				tokens = append(tokens, whereToken{kind: operator, text: operator})
+				i += len(operator)
+				continue
+			}
+			end := i
+			for end < len(expr) && !unicode.IsSpace(rune(expr[end])) && !strings.ContainsRune(`"&|=!<>()~`, rune(expr[end])) {
+				end++
+			}
+			if end == i {
+				return nil, fmt.Errorf("unexpected %q", expr[i:i+1])
+			}
+			tokens = append(tokens, whereToken{kind: "word", text: expr[i:end]})
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+// whereParser parses the tokens of a condition by recursive descent, || binding
+// looser than &&, which binds looser than !.
+type whereParser struct {
+	tokens []whereToken
+	pos    int
+}
+
+func (p *whereParser) peek(kind string) bool {
+	return p.pos < len(p.tokens) && p.tokens[p.pos].kind == kind
+}
+
+func (p *whereParser) next() (whereToken, error) {
+	if p.pos >= len(p.tokens) {
+		return whereToken{}, fmt.Errorf("unexpected end of the condition")
+	}
+	p.pos++
+	return p.tokens[p.pos-1], nil
+}
+
+func (p *whereParser) or() (func(pr PRInfo) bool, error) {
+	left, err := p.and()
+	for err == nil && p.peek("||") {
+		p.pos++
+		var right func(pr PRInfo) bool
+		if right, err = p.and(); err == nil {
+			l := left
+			left = func(pr PRInfo) bool { return l(pr) || right(pr) }
+		}
+	}
+	return left, err
+}
+
+func (p *whereParser) and() (func(pr PRInfo) bool, error) {
+	left, err := p.unary()
+	for err == nil && p.peek("&&") {
+		p.pos++
+		var right func(pr PRInfo) bool
+		if right, err = p.unary(); err == nil {
+			l := left
+			left = func(pr PRInfo) bool { return l(pr) && right(pr) }
+		}
+	}
+	return left, err
+}
+
+func (p *whereParser) unary() (func(pr PRInfo) bool, error) {
+	switch {
+	case p.peek("!"):
+		p.pos++
+		operand, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return func(pr PRInfo) bool { return !operand(pr) }, nil
+	case p.peek("("):
+		p.pos++
+		inner, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.peek(")") {
+			return nil, fmt.Errorf("missing )")
+		}
+		p.pos++
+		return inner, nil
+	default:
+		return p.comparison()
+	}
+}
+
+func (p *whereParser) comparison() (func(pr PRInfo) bool, error) {
+	left, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	// "value" in list
+	if p.pos < len(p.tokens) && p.tokens[p.pos].kind == "word" && p.tokens[p.pos].text == "in" {
+		p.pos++
+		field, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+		list, ok := whereListFields[field.text]
+		if field.kind != "word" || !ok {
+			return nil, fmt.Errorf("%s is not a list field, expected one of %s", field.text, strings.Join(sortedKeys(whereListFields), ", "))
+		}
+		value := left.text
+		return func(pr PRInfo) bool {
+			for _, item := range list(pr) {
+				if item == value {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+
+	if left.kind != "word" {
+		return nil, fmt.Errorf("expected a field instead of %s", left.text)
+	}
+	if value, ok := whereBoolFields[left.text]; ok {
+		return value, nil
+	}
+
+	operator, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch operator.kind {
+	case "==", "!=", "<", "<=", ">", ">=", "=~":
+	default:
+		return nil, fmt.Errorf("expected a comparison after %s instead of %s", left.text, operator.text)
+	}
+	right, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if right.kind != "string" && right.kind != "word" {
+		return nil, fmt.Errorf("expected a value after %s instead of %s", operator.text, right.text)
+	}
+
+	if value, ok := whereTextFields[left.text]; ok {
+		if operator.kind == "=~" {
+			re, err := regexp.Compile(right.text)
+			if err != nil {
+				return nil, err
+			}
+			return func(pr PRInfo) bool { return re.MatchString(value(pr)) }, nil
+		}
+		return compareWith(operator.kind, func(pr PRInfo) (string, bool) { return value(pr), true }, right.text), nil
+	}
+	if operator.kind == "=~" {
+		return nil, fmt.Errorf("=~ only applies to the text fields")
+	}
+
+	if field, ok := metricFields[left.text]; ok {
+		var target float64
+		if field.duration {
+			d, err := parseShortDuration(right.text)
+			if err != nil {
+				return nil, err
+			}
+			target = float64(d)
+		} else if target, err = strconv.ParseFloat(right.text, 64); err != nil {
+			return nil, fmt.Errorf("invalid number %s", right.text)
+		}
+		return compareWith(operator.kind, field.value, target), nil
+	}
+	if left.text == "year" || left.text == "number" {
+		target, err := strconv.ParseFloat(right.text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s", right.text)
+		}
+		value := func(pr PRInfo) (float64, bool) { return float64(pr.Year), true }
+		if left.text == "number" {
+			value = func(pr PRInfo) (float64, bool) { return float64(pr.Number), true }
+		}
+		return compareWith(operator.kind, value, target), nil
+	}
+
+	if value, ok := whereTimeFields[left.text]; ok {
+		target, err := time.Parse(time.DateOnly, right.text)
+		if err != nil {
+			if target, err = time.Parse(time.RFC3339, right.text); err != nil {
+				return nil, fmt.Errorf("invalid time %s, expected a date such as 2024-01-31 or an RFC 3339 time", right.text)
+			}
+		}
+		return compareWith(operator.kind, func(pr PRInfo) (int64, bool) { return value(pr).UnixNano(), true }, target.UnixNano()), nil
+	}
+
+	var fields []string
+	for _, names := range [][]string{sortedKeys(whereTextFields), sortedKeys(metricFields), sortedKeys(whereTimeFields), sortedKeys(whereBoolFields), {"number", "year"}} {
+		fields = append(fields, names...)
+	}
+	return nil, fmt.Errorf("unknown field %s, expected one of %s or a list field after in", left.text, strings.Join(fields, ", "))
+}
+
+// compareWith compares the value of the field of the PRs with the target.
+func compareWith[T string | float64 | int64](operator string, value func(pr PRInfo) (T, bool), target T) func(pr PRInfo) bool {
+	return func(pr PRInfo) bool {
+		v, ok := value(pr)
+		if !ok {
+			return false
+		}
+		switch operator {
+		case "==":
+			return v == target
+		case "!=":
+			return v != target
+		case "<":
+			return v < target
+		case "<=":
+			return v <= target
+		case ">":
+			return v > target
+		default:
+			return v >= target
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkingHours is the schedule of a contributor in the configuration, such
+// as {"days": ["Mon", "Tue", "Wed", "Thu"], "start": "09:00", "end": "17:00"}
+// for a four day week. The hours are in the timezone of the contributor, the
+// days default to Monday to Friday and the hours to the whole day.
+type WorkingHours struct {
+	Days  []string `json:"days"`
+	Start string   `json:"start"`
+	End   string   `json:"end"`
+
+	days       map[time.Weekday]bool
+	start, end time.Duration
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+func (h *WorkingHours) UnmarshalJSON(data []byte) error {
+	type plain WorkingHours
+	if err := json.Unmarshal(data, (*plain)(h)); err != nil {
+		return err
+	}
+
+	h.days = make(map[time.Weekday]bool)
+	if len(h.Days) == 0 {
+		for day := time.Monday; day <= time.Friday; day++ {
+			h.days[day] = true
+		}
+	}
+	for _, name := range h.Days {
+		day, ok := weekdayNames[strings.ToLower(name[:min(len(name), 3)])]
+		if !ok {
+			return fmt.Errorf("invalid working day %q, expected Mon to Sun", name)
+		}
+		h.days[day] = true
+	}
+
+	var err error
+	if h.start, err = clockTime(h.Start, 0); err != nil {
+		return err
+	}
+	if h.end, err = clockTime(h.End, 24*time.Hour); err != nil {
+		return err
+	}
+	if h.end <= h.start {
+		return fmt.Errorf("working hours end at %s before they start at %s", h.End, h.Start)
+	}
+	return nil
+}
+
+// clockTime parses a time of day such as "09:30" as the time since midnight,
+// fallback when empty.
+func clockTime(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q, expected HH:MM", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// isWorkingDay reports whether the contributor works on the day, Monday to
+// Friday for the schedules not read from the configuration.
+func (h WorkingHours) isWorkingDay(day time.Weekday) bool {
+	if h.days == nil {
+		return day >= time.Monday && day <= time.Friday
+	}
+	return h.days[day]
+}
+
+// at returns the time of day on the day, by the clock of its location, so
+// that the days on which the clocks change keep the same working hours.
+func at(day time.Time, clock time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), int(clock/time.Hour), int(clock%time.Hour/time.Minute), 0, 0, day.Location())
+}
+
+// duration returns the part of the time between start and end that falls in
+// the working hours, in the location of the contributor.
+func (h WorkingHours) duration(start time.Time, end time.Time, location *time.Location) time.Duration {
+	// The schedules not read from the configuration work the whole day
+	endOfDay := h.end
+	if endOfDay == 0 {
+		endOfDay = 24 * time.Hour
+	}
+
+	var d time.Duration
+	start, end = start.In(location), end.In(location)
+	for day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, location); day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !h.isWorkingDay(day.Weekday()) {
+			continue
+		}
+		from, until := at(day, h.start), at(day, endOfDay)
+		if from.Before(start) {
+			from = start
+		}
+		if until.After(end) {
+			until = end
+		}
+		if until.After(from) {
+			d += until.Sub(from)
+		}
+	}
+	return d
+}
+
+// responseTime returns the time the user took to respond at end to something
+// from start on, counting only their working hours when the configuration
+// has a schedule for them.
+func (o collectOptions) responseTime(login string, start time.Time, end time.Time) time.Duration {
+	hours, ok := o.WorkingHours[login]
+	if !ok {
+		return o.latency(start, end)
+	}
+	if end.Before(start) {
+		return 0
+	}
+	location := time.UTC
+	if tz, ok := o.Timezones[login]; ok && tz.Location != nil {
+		location = tz.Location
+	}
+	return hours.duration(start, end, location)
+}